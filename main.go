@@ -73,9 +73,17 @@ func run() int {
 	}()
 
 	if _, err := driftctlCmd.ExecuteC(); err != nil {
-		if _, isNotInSync := err.(cmderrors.InfrastructureNotInSync); isNotInSync {
+		if notInSync, isNotInSync := err.(cmderrors.InfrastructureNotInSync); isNotInSync {
+			// Drifts take precedence over an incomplete scan, which is still reported
+			if len(notInSync.IncompleteKinds) > 0 {
+				_, _ = fmt.Fprintln(os.Stderr, color.RedString("%s", cmderrors.IncompleteScan{Kinds: notInSync.IncompleteKinds}))
+			}
 			return scan.EXIT_NOT_IN_SYNC
 		}
+		if _, isIncomplete := err.(cmderrors.IncompleteScan); isIncomplete {
+			_, _ = fmt.Fprintln(os.Stderr, color.RedString("%s", err))
+			return scan.EXIT_INCOMPLETE_SCAN
+		}
 		if cmd.IsReportingEnabled(&driftctlCmd.Command) {
 			sentry.CaptureException(err)
 		}
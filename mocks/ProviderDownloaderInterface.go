@@ -9,13 +9,13 @@ type ProviderDownloaderInterface struct {
 	mock.Mock
 }
 
-// Download provides a mock function with given fields: url, path
-func (_m *ProviderDownloaderInterface) Download(url string, path string) error {
-	ret := _m.Called(url, path)
+// Download provides a mock function with given fields: url, path, verify
+func (_m *ProviderDownloaderInterface) Download(url string, path string, verify func(string) error) error {
+	ret := _m.Called(url, path, verify)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(string, string) error); ok {
-		r0 = rf(url, path)
+	if rf, ok := ret.Get(0).(func(string, string, func(string) error) error); ok {
+		r0 = rf(url, path, verify)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -23,16 +23,25 @@ func (_m *ProviderDownloaderInterface) Download(url string, path string) error {
 	return r0
 }
 
-// GetProviderUrl provides a mock function with given fields: name, version
-func (_m *ProviderDownloaderInterface) GetProviderUrl(name string, version string) string {
-	ret := _m.Called(name, version)
+// Get provides a mock function with given fields: url
+func (_m *ProviderDownloaderInterface) Get(url string) ([]byte, error) {
+	ret := _m.Called(url)
 
-	var r0 string
-	if rf, ok := ret.Get(0).(func(string, string) string); ok {
-		r0 = rf(name, version)
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(string) []byte); ok {
+		r0 = rf(url)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
 	}
 
-	return r0
+	var r1 error
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(url)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
@@ -1,19 +1,105 @@
 package alerter
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
 
 type Alerts map[string][]Alert
 
 type Alert interface {
+	// Kind returns a stable name identifying the type of the alert,
+	// used to serialize alerts and to reference them in alert policies
+	Kind() string
 	Message() string
 	ShouldIgnoreResource() bool
 }
 
+// DetailedAlert is implemented by alerts carrying more than a message.
+// Details are serialized so the alert can be rebuilt from an analysis JSON.
+type DetailedAlert interface {
+	Alert
+	Details() map[string]string
+}
+
+// AlertConstructor rebuilds an alert from its serialized form
+type AlertConstructor func(alert *SerializedAlert) Alert
+
+var (
+	kindsMu      sync.RWMutex
+	constructors = map[string]AlertConstructor{}
+)
+
+// RegisterKind declares an alert kind along with the constructor used to restore alerts of this kind
+// when reading an analysis JSON. Registering the same kind twice panics.
+func RegisterKind(kind string, constructor AlertConstructor) {
+	kindsMu.Lock()
+	defer kindsMu.Unlock()
+	if _, exists := constructors[kind]; exists {
+		panic(fmt.Sprintf("alert kind %s is already registered", kind))
+	}
+	constructors[kind] = constructor
+}
+
+// Kinds returns the sorted list of registered alert kinds
+func Kinds() []string {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+	kinds := make([]string, 0, len(constructors))
+	for kind := range constructors {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Restore rebuilds the alert a serialized alert was made of,
+// alerts of unknown kinds, e.g. sent by another driftctl version, are kept as generic alerts
+func Restore(alert *SerializedAlert) Alert {
+	alert.AlertKind = legacyKind(alert.AlertKind)
+	kindsMu.RLock()
+	constructor, exists := constructors[alert.AlertKind]
+	kindsMu.RUnlock()
+	if !exists {
+		return alert
+	}
+	return constructor(alert)
+}
+
+// legacyKind converts kinds written by versions using alert type names (e.g. RemoteAccessDeniedAlert)
+// to the current kind (e.g. remote_access_denied), other kinds are returned as is
+func legacyKind(kind string) string {
+	if !strings.HasSuffix(kind, "Alert") || kind == "" || !unicode.IsUpper(rune(kind[0])) {
+		return kind
+	}
+	var b strings.Builder
+	for i, r := range strings.TrimSuffix(kind, "Alert") {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteRune('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+const FakeAlertKind = "fake"
+
 type FakeAlert struct {
 	Msg            string
 	IgnoreResource bool
 }
 
+func (f *FakeAlert) Kind() string {
+	return FakeAlertKind
+}
+
 func (f *FakeAlert) Message() string {
 	return f.Msg
 }
@@ -27,7 +113,14 @@ type SerializableAlert struct {
 }
 
 type SerializedAlert struct {
-	Msg string `json:"message"`
+	AlertKind      string            `json:"kind,omitempty"`
+	Msg            string            `json:"message"`
+	IgnoreResource bool              `json:"should_ignore_resource,omitempty"`
+	Detail         map[string]string `json:"details,omitempty"`
+}
+
+func (u *SerializedAlert) Kind() string {
+	return u.AlertKind
 }
 
 func (u *SerializedAlert) Message() string {
@@ -35,7 +128,11 @@ func (u *SerializedAlert) Message() string {
 }
 
 func (u *SerializedAlert) ShouldIgnoreResource() bool {
-	return false
+	return u.IgnoreResource
+}
+
+func (u *SerializedAlert) Details() map[string]string {
+	return u.Detail
 }
 
 func (s *SerializableAlert) UnmarshalJSON(bytes []byte) error {
@@ -49,5 +146,13 @@ func (s *SerializableAlert) UnmarshalJSON(bytes []byte) error {
 }
 
 func (s *SerializableAlert) MarshalJSON() ([]byte, error) {
-	return json.Marshal(SerializedAlert{Msg: s.Message()})
+	res := SerializedAlert{
+		AlertKind:      s.Kind(),
+		Msg:            s.Message(),
+		IgnoreResource: s.ShouldIgnoreResource(),
+	}
+	if detailed, ok := s.Alert.(DetailedAlert); ok {
+		res.Detail = detailed.Details()
+	}
+	return json.Marshal(res)
 }
@@ -0,0 +1,53 @@
+package alerter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// resetKinds empties the registered alert kinds for the duration of a test,
+// so that tests only depend on the kinds they register themselves
+func resetKinds(t *testing.T) {
+	kindsMu.Lock()
+	registered := constructors
+	constructors = map[string]AlertConstructor{}
+	kindsMu.Unlock()
+
+	t.Cleanup(func() {
+		kindsMu.Lock()
+		constructors = registered
+		kindsMu.Unlock()
+	})
+}
+
+type restoredAlert struct {
+	FakeAlert
+	detail string
+}
+
+func (r *restoredAlert) Kind() string {
+	return "restored"
+}
+
+func TestRestore(t *testing.T) {
+	resetKinds(t)
+	RegisterKind("restored", func(alert *SerializedAlert) Alert {
+		return &restoredAlert{FakeAlert{Msg: alert.Message()}, alert.Details()["detail"]}
+	})
+
+	assert.Panics(t, func() {
+		RegisterKind("restored", nil)
+	})
+	assert.Equal(t, []string{"restored"}, Kinds())
+
+	restored := Restore(&SerializedAlert{AlertKind: "restored", Msg: "message", Detail: map[string]string{"detail": "value"}})
+	assert.Equal(t, &restoredAlert{FakeAlert{Msg: "message"}, "value"}, restored)
+
+	// Analyses written before alerts had explicit kinds used the alert type name
+	restored = Restore(&SerializedAlert{AlertKind: "RestoredAlert", Msg: "message"})
+	assert.Equal(t, &restoredAlert{FakeAlert{Msg: "message"}, ""}, restored)
+
+	unknown := &SerializedAlert{AlertKind: "unknown", Msg: "message"}
+	assert.Same(t, unknown, Restore(unknown))
+}
@@ -0,0 +1,161 @@
+package alerter
+
+import (
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+)
+
+type PolicyAction string
+
+const (
+	// PolicyIgnore removes alerts from the scan results
+	PolicyIgnore PolicyAction = "ignore"
+	// PolicyWarn reports alerts without changing the scan result, this is the default
+	PolicyWarn PolicyAction = "warn"
+	// PolicyFail reports alerts and marks the scan as incomplete
+	PolicyFail PolicyAction = "fail"
+)
+
+// PolicyWildcard is used to set the action of every alert kind without a dedicated entry
+const PolicyWildcard = "*"
+
+// Policy maps alert kinds (e.g. remote_access_denied) to the action to take when they are raised
+type Policy map[string]PolicyAction
+
+// ParsePolicy parses a list of kind=action entries
+func ParsePolicy(entries []string) (Policy, error) {
+	policy := make(Policy, len(entries))
+	for _, entry := range entries {
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.Errorf("unable to parse alert policy '%s', expected KIND=ACTION", entry)
+		}
+		if err := validatePolicyKind(parts[0]); err != nil {
+			return nil, err
+		}
+		action, err := parsePolicyAction(parts[0], parts[1])
+		if err != nil {
+			return nil, err
+		}
+		policy[parts[0]] = action
+	}
+	return policy, nil
+}
+
+type policyFile struct {
+	AlertPolicy map[string]string `json:"alert_policy"`
+}
+
+// ReadPolicy reads the alert_policy key of a YAML file, mapping alert kinds to actions, e.g.
+//
+//	alert_policy:
+//	  remote_access_denied: fail
+//	  "*": warn
+func ReadPolicy(path string) (Policy, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read alert policy file %s", path)
+	}
+
+	var f policyFile
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse alert policy file %s", path)
+	}
+
+	policy := make(Policy, len(f.AlertPolicy))
+	for kind, value := range f.AlertPolicy {
+		if err := validatePolicyKind(kind); err != nil {
+			return nil, errors.Wrapf(err, "invalid alert policy file %s", path)
+		}
+		action, err := parsePolicyAction(kind, value)
+		if err != nil {
+			return nil, err
+		}
+		policy[kind] = action
+	}
+	return policy, nil
+}
+
+// validatePolicyKind checks that a policy references a registered alert kind or the wildcard,
+// so a typo does not silently leave alerts with their default action
+func validatePolicyKind(kind string) error {
+	if kind == PolicyWildcard {
+		return nil
+	}
+	kinds := Kinds()
+	for _, k := range kinds {
+		if k == kind {
+			return nil
+		}
+	}
+	return errors.Errorf(
+		"unknown alert kind '%s', valid kinds are: %s, %s",
+		kind, strings.Join(kinds, ", "), PolicyWildcard,
+	)
+}
+
+func parsePolicyAction(kind, value string) (PolicyAction, error) {
+	action := PolicyAction(strings.ToLower(value))
+	switch action {
+	case PolicyIgnore, PolicyWarn, PolicyFail:
+		return action, nil
+	default:
+		return "", errors.Errorf(
+			"invalid action '%s' for alert kind '%s', valid actions are: %s, %s, %s",
+			value, kind, PolicyIgnore, PolicyWarn, PolicyFail,
+		)
+	}
+}
+
+// Merge returns a policy with the entries of both policies, the ones of other take precedence
+func (p Policy) Merge(other Policy) Policy {
+	merged := make(Policy, len(p)+len(other))
+	for kind, action := range p {
+		merged[kind] = action
+	}
+	for kind, action := range other {
+		merged[kind] = action
+	}
+	return merged
+}
+
+// Action returns the action to take for the given alert
+func (p Policy) Action(alert Alert) PolicyAction {
+	if action, ok := p[alert.Kind()]; ok {
+		return action
+	}
+	if action, ok := p[PolicyWildcard]; ok {
+		return action
+	}
+	return PolicyWarn
+}
+
+// Apply removes ignored alerts and returns the remaining ones,
+// along with the sorted list of kinds of alerts that should fail the scan
+func (p Policy) Apply(alerts Alerts) (Alerts, []string) {
+	result := make(Alerts, len(alerts))
+	failed := make(map[string]struct{})
+	for key, list := range alerts {
+		for _, alert := range list {
+			switch p.Action(alert) {
+			case PolicyIgnore:
+				continue
+			case PolicyFail:
+				failed[alert.Kind()] = struct{}{}
+			}
+			result[key] = append(result[key], alert)
+		}
+	}
+
+	failedKinds := make([]string, 0, len(failed))
+	for kind := range failed {
+		failedKinds = append(failedKinds, kind)
+	}
+	sort.Strings(failedKinds)
+
+	return result, failedKinds
+}
@@ -0,0 +1,90 @@
+package alerter
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type remoteAccessDeniedAlert struct {
+	FakeAlert
+}
+
+func (r *remoteAccessDeniedAlert) Kind() string {
+	return "remote_access_denied"
+}
+
+func registerPolicyTestKinds(t *testing.T) {
+	resetKinds(t)
+	for _, kind := range []string{FakeAlertKind, "remote_access_denied", "computed_diff"} {
+		RegisterKind(kind, func(alert *SerializedAlert) Alert { return alert })
+	}
+}
+
+func TestParsePolicy_UnknownKind(t *testing.T) {
+	registerPolicyTestKinds(t)
+	_, err := ParsePolicy([]string{"remote_acess_denied=fail"})
+	assert.EqualError(t, err, "unknown alert kind 'remote_acess_denied', valid kinds are: computed_diff, fake, remote_access_denied, *")
+}
+
+func TestPolicy_Apply(t *testing.T) {
+	registerPolicyTestKinds(t)
+	policy, err := ParsePolicy([]string{"remote_access_denied=fail", "fake=IGNORE"})
+	assert.NoError(t, err)
+
+	denied := &remoteAccessDeniedAlert{FakeAlert{Msg: "denied", IgnoreResource: true}}
+	other := &SerializedAlert{AlertKind: "computed_diff", Msg: "computed"}
+	alerts, failed := policy.Apply(Alerts{
+		"aws_iam_role": {denied, &FakeAlert{Msg: "ignored"}},
+		"aws_vpc":      {&FakeAlert{Msg: "ignored"}},
+		"":             {other},
+	})
+
+	assert.Equal(t, Alerts{
+		"aws_iam_role": {denied},
+		"":             {other},
+	}, alerts)
+	assert.Equal(t, []string{"remote_access_denied"}, failed)
+
+	policy, err = ParsePolicy([]string{"*=fail", "computed_diff=warn"})
+	assert.NoError(t, err)
+	assert.Equal(t, PolicyFail, policy.Action(denied))
+	assert.Equal(t, PolicyWarn, policy.Action(other))
+	assert.Equal(t, PolicyWarn, Policy(nil).Action(denied))
+}
+
+func TestReadPolicy(t *testing.T) {
+	registerPolicyTestKinds(t)
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.yml")
+	if err := ioutil.WriteFile(path, []byte("alert_policy:\n  remote_access_denied: fail\n  \"*\": Ignore\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	policy, err := ReadPolicy(path)
+	assert.NoError(t, err)
+	assert.Equal(t, Policy{"remote_access_denied": PolicyFail, "*": PolicyIgnore}, policy)
+
+	flagPolicy, _ := ParsePolicy([]string{"*=warn"})
+	assert.Equal(t, Policy{"remote_access_denied": PolicyFail, "*": PolicyWarn}, policy.Merge(flagPolicy))
+
+	invalid := filepath.Join(dir, "invalid.yml")
+	if err := ioutil.WriteFile(invalid, []byte("alert_policy:\n  computed_diff: panic\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadPolicy(invalid)
+	assert.EqualError(t, err, "invalid action 'panic' for alert kind 'computed_diff', valid actions are: ignore, warn, fail")
+
+	typo := filepath.Join(dir, "typo.yml")
+	if err := ioutil.WriteFile(typo, []byte("alert_policy:\n  remote_acess_denied: fail\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = ReadPolicy(typo)
+	assert.EqualError(t, err, "invalid alert policy file "+typo+": unknown alert kind 'remote_acess_denied', valid kinds are: computed_diff, fake, remote_access_denied, *")
+
+	_, err = ReadPolicy(filepath.Join(dir, "missing.yml"))
+	assert.Error(t, err)
+}
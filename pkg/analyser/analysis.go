@@ -7,15 +7,21 @@ import (
 	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/r3labs/diff/v2"
 	"github.com/snyk/driftctl/pkg/alerter"
 	"github.com/snyk/driftctl/pkg/resource"
 )
 
+// FormatVersion is the version of the analysis JSON format.
+// It must be bumped when a breaking change is made to the serialized analysis,
+// analyses without version are considered as version 0.
+const FormatVersion = 1
+
 type Change struct {
 	diff.Change
 	Computed   bool `json:"computed"`
-	JsonString bool `json:"-"`
+	JsonString bool `json:"json_string,omitempty"`
 }
 
 type Changelog []Change
@@ -34,6 +40,24 @@ type Summary struct {
 	TotalIaCSourceCount uint `json:"total_iac_source_count"`
 }
 
+// Completeness tells whether every resource type could be scanned on the cloud provider
+type Completeness struct {
+	Complete       bool     `json:"complete"`
+	UnscannedTypes []string `json:"unscanned_types"`
+}
+
+// ScanMetadata records what a scan was about, so a report can be traced back to the scanned account and IaC sources
+type ScanMetadata struct {
+	DriftctlVersion string   `json:"driftctl_version,omitempty"`
+	From            []string `json:"from,omitempty"`
+	To              string   `json:"to,omitempty"`
+	Account         string   `json:"account,omitempty"`
+	Region          string   `json:"region,omitempty"`
+	CallerIdentity  string   `json:"caller_identity,omitempty"`
+	GitCommit       string   `json:"git_commit,omitempty"`
+	DriftignoreHash string   `json:"driftignore_hash,omitempty"`
+}
+
 type Analysis struct {
 	unmanaged       []*resource.Resource
 	managed         []*resource.Resource
@@ -42,10 +66,12 @@ type Analysis struct {
 	options         AnalyzerOptions
 	summary         Summary
 	alerts          alerter.Alerts
+	unscannedTypes  []string
 	Duration        time.Duration
 	Date            time.Time
 	ProviderName    string
 	ProviderVersion string
+	Scan            *ScanMetadata
 }
 
 type serializableDifference struct {
@@ -54,6 +80,7 @@ type serializableDifference struct {
 }
 
 type serializableAnalysis struct {
+	Version         int                                    `json:"version"`
 	Options         AnalyzerOptions                        `json:"options"`
 	Summary         Summary                                `json:"summary"`
 	Managed         []resource.SerializableResource        `json:"managed"`
@@ -62,10 +89,12 @@ type serializableAnalysis struct {
 	Differences     []serializableDifference               `json:"differences"`
 	Coverage        int                                    `json:"coverage"`
 	Alerts          map[string][]alerter.SerializableAlert `json:"alerts"`
+	Completeness    Completeness                           `json:"completeness"`
 	ProviderName    string                                 `json:"provider_name"`
 	ProviderVersion string                                 `json:"provider_version"`
 	ScanDuration    uint                                   `json:"scan_duration,omitempty"`
 	Date            time.Time                              `json:"date"`
+	Scan            *ScanMetadata                          `json:"scan,omitempty"`
 }
 
 type GenDriftIgnoreOptions struct {
@@ -105,6 +134,7 @@ func (a Analysis) MarshalJSON() ([]byte, error) {
 			}
 		}
 	}
+	bla.Completeness = a.Completeness()
 	bla.Summary = a.summary
 	bla.Coverage = a.Coverage()
 	bla.ProviderName = a.ProviderName
@@ -112,6 +142,8 @@ func (a Analysis) MarshalJSON() ([]byte, error) {
 	bla.ScanDuration = uint(a.Duration.Seconds())
 	bla.Options = a.Options()
 	bla.Date = a.Date
+	bla.Scan = a.Scan
+	bla.Version = FormatVersion
 
 	return json.Marshal(bla)
 }
@@ -121,42 +153,21 @@ func (a *Analysis) UnmarshalJSON(bytes []byte) error {
 	if err := json.Unmarshal(bytes, &bla); err != nil {
 		return err
 	}
+	if bla.Version > FormatVersion {
+		return errors.Errorf("unsupported analysis format version %d, this version of driftctl supports up to version %d", bla.Version, FormatVersion)
+	}
 	for _, u := range bla.Unmanaged {
-		a.AddUnmanaged(&resource.Resource{
-			Id:   u.Id,
-			Type: u.Type,
-		})
+		a.AddUnmanaged(u.ToResource())
 	}
 	for _, d := range bla.Deleted {
-		a.AddDeleted(&resource.Resource{
-			Id:   d.Id,
-			Type: d.Type,
-		})
+		a.AddDeleted(d.ToResource())
 	}
 	for _, m := range bla.Managed {
-		res := &resource.Resource{
-			Id:   m.Id,
-			Type: m.Type,
-		}
-		if m.Source != nil {
-			// We loose the source type in the serialization process, for now everything is serialized back to a
-			// TerraformStateSource.
-			// TODO: Add a discriminator field to be able to serialize back to the right type
-			// when we'll introduce a new source type
-			res.Source = &resource.TerraformStateSource{
-				State:  m.Source.S,
-				Module: m.Source.Ns,
-				Name:   m.Source.Name,
-			}
-		}
-		a.AddManaged(res)
+		a.AddManaged(m.ToResource())
 	}
 	for _, di := range bla.Differences {
 		a.AddDifference(Difference{
-			Res: &resource.Resource{
-				Id:   di.Res.Id,
-				Type: di.Res.Type,
-			},
+			Res:       di.Res.ToResource(),
 			Changelog: di.Changelog,
 		})
 	}
@@ -164,18 +175,18 @@ func (a *Analysis) UnmarshalJSON(bytes []byte) error {
 		a.alerts = make(alerter.Alerts)
 		for k, v := range bla.Alerts {
 			for _, al := range v {
-				a.alerts[k] = append(a.alerts[k], &alerter.SerializedAlert{
-					Msg: al.Message(),
-				})
+				a.alerts[k] = append(a.alerts[k], alerter.Restore(al.Alert.(*alerter.SerializedAlert)))
 			}
 		}
 	}
+	a.AddUnscannedTypes(bla.Completeness.UnscannedTypes...)
 	a.ProviderName = bla.ProviderName
 	a.ProviderVersion = bla.ProviderVersion
 	a.SetIaCSourceCount(bla.Summary.TotalIaCSourceCount)
 	a.Duration = time.Duration(bla.ScanDuration) * time.Second
 	a.options = bla.Options
 	a.Date = bla.Date
+	a.Scan = bla.Scan
 	return nil
 }
 
@@ -214,6 +225,51 @@ func (a *Analysis) SetAlerts(alerts alerter.Alerts) {
 	a.alerts = alerts
 }
 
+// ApplyAlertPolicy removes alerts ignored by the policy, then records resource types that could not be scanned
+// according to the remaining alerts. It returns the sorted kinds of alerts that should fail the scan.
+func (a *Analysis) ApplyAlertPolicy(policy alerter.Policy) []string {
+	alerts, failedKinds := policy.Apply(a.alerts)
+	a.SetAlerts(alerts)
+	a.AddUnscannedTypes(unscannedTypes(alerts)...)
+	return failedKinds
+}
+
+// unscannedTypes returns resource types for which alerts caused every resource of the type to be ignored.
+// Alerts keyed by resource type and id, joined with a dot, only ignore a single resource and are skipped.
+func unscannedTypes(alerts alerter.Alerts) []string {
+	types := make([]string, 0)
+	for key, list := range alerts {
+		if key == "" || strings.Contains(key, ".") {
+			continue
+		}
+		for _, alert := range list {
+			if alert.ShouldIgnoreResource() {
+				types = append(types, key)
+				break
+			}
+		}
+	}
+	return types
+}
+
+// AddUnscannedTypes records resource types that could not be scanned, keeping the list sorted and without duplicates
+func (a *Analysis) AddUnscannedTypes(types ...string) {
+	for _, ty := range types {
+		a.unscannedTypes = appendDistinct(a.unscannedTypes, ty)
+	}
+}
+
+func (a *Analysis) Completeness() Completeness {
+	unscanned := a.unscannedTypes
+	if unscanned == nil {
+		unscanned = []string{}
+	}
+	return Completeness{
+		Complete:       len(unscanned) == 0,
+		UnscannedTypes: unscanned,
+	}
+}
+
 func (a *Analysis) SetOptions(options AnalyzerOptions) {
 	a.options = options
 }
@@ -4,17 +4,36 @@ import (
 	"github.com/r3labs/diff/v2"
 	"github.com/snyk/driftctl/pkg/filter"
 	resourceaws "github.com/snyk/driftctl/pkg/resource/aws"
+	"github.com/snyk/driftctl/pkg/rules"
 
 	"github.com/snyk/driftctl/pkg/alerter"
 	"github.com/snyk/driftctl/pkg/resource"
 )
 
+const (
+	UnmanagedSecurityGroupRulesAlertKind = "unmanaged_security_group_rules"
+	ComputedDiffAlertKind                = "computed_diff"
+)
+
+func init() {
+	alerter.RegisterKind(UnmanagedSecurityGroupRulesAlertKind, func(*alerter.SerializedAlert) alerter.Alert {
+		return newUnmanagedSecurityGroupRulesAlert()
+	})
+	alerter.RegisterKind(ComputedDiffAlertKind, func(*alerter.SerializedAlert) alerter.Alert {
+		return NewComputedDiffAlert()
+	})
+}
+
 type UnmanagedSecurityGroupRulesAlert struct{}
 
 func newUnmanagedSecurityGroupRulesAlert() *UnmanagedSecurityGroupRulesAlert {
 	return &UnmanagedSecurityGroupRulesAlert{}
 }
 
+func (u *UnmanagedSecurityGroupRulesAlert) Kind() string {
+	return UnmanagedSecurityGroupRulesAlertKind
+}
+
 func (u *UnmanagedSecurityGroupRulesAlert) Message() string {
 	return "You have unmanaged security group rules that could be false positives, find out more at https://docs.driftctl.com/limitations"
 }
@@ -29,6 +48,10 @@ func NewComputedDiffAlert() *ComputedDiffAlert {
 	return &ComputedDiffAlert{}
 }
 
+func (c *ComputedDiffAlert) Kind() string {
+	return ComputedDiffAlertKind
+}
+
 func (c *ComputedDiffAlert) Message() string {
 	return "You have diffs on computed fields, check the documentation for potential false positive drifts: https://docs.driftctl.com/limitations"
 }
@@ -41,16 +64,20 @@ type AnalyzerOptions struct {
 	Deep          bool `json:"deep"`
 	OnlyManaged   bool `json:"only_managed"`
 	OnlyUnmanaged bool `json:"only_unmanaged"`
+	// IgnoreSensitiveChanges skips changes of sensitive fields, used when replaying a recorded scan
+	// as sensitive values were redacted when recording it
+	IgnoreSensitiveChanges bool `json:"-"`
 }
 
 type Analyzer struct {
 	alerter *alerter.Alerter
 	options AnalyzerOptions
 	filter  filter.Filter
+	rules   *rules.RuleSet
 }
 
-func NewAnalyzer(alerter *alerter.Alerter, options AnalyzerOptions, filter filter.Filter) *Analyzer {
-	return &Analyzer{alerter, options, filter}
+func NewAnalyzer(alerter *alerter.Alerter, options AnalyzerOptions, filter filter.Filter, rules *rules.RuleSet) *Analyzer {
+	return &Analyzer{alerter, options, filter, rules}
 }
 
 func (a Analyzer) Analyze(remoteResources, resourcesFromState []*resource.Resource) (Analysis, error) {
@@ -64,24 +91,26 @@ func (a Analyzer) Analyze(remoteResources, resourcesFromState []*resource.Resour
 		}
 		filteredRemoteResource = append(filteredRemoteResource, remoteRes)
 	}
+	remoteIndex := newResourceIndex(filteredRemoteResource)
 
 	haveComputedDiff := false
+	filteredStateResources := make([]*resource.Resource, 0, len(resourcesFromState))
+	deletedResources := make([]*resource.Resource, 0)
 	for _, stateRes := range resourcesFromState {
-		i, remoteRes, found := findCorrespondingRes(filteredRemoteResource, stateRes)
+		remoteRes, found := remoteIndex.find(stateRes)
 
 		if a.filter.IsResourceIgnored(stateRes) || a.alerter.IsResourceIgnored(stateRes) {
 			continue
 		}
+		filteredStateResources = append(filteredStateResources, stateRes)
 
 		if !found {
-			if !analysis.Options().OnlyUnmanaged {
-				analysis.AddDeleted(stateRes)
-			}
+			deletedResources = append(deletedResources, stateRes)
 			continue
 		}
 
 		// Remove managed resources, so it will remain only unmanaged ones
-		filteredRemoteResource = removeResourceByIndex(i, filteredRemoteResource)
+		remoteIndex.remove(remoteRes)
 		analysis.AddManaged(stateRes)
 
 		// Stop there if we are not in deep mode, we do not want to compute diffs
@@ -106,8 +135,14 @@ func (a Analyzer) Analyze(remoteResources, resourcesFromState []*resource.Resour
 			if a.filter.IsFieldIgnored(stateRes, change.Path) {
 				continue
 			}
-			c := Change{Change: change}
+			if a.rules.IsChangeIgnored(stateRes, remoteRes, change.Type, change.Path, change.From, change.To) {
+				continue
+			}
 			resSchema := stateRes.Schema()
+			if a.options.IgnoreSensitiveChanges && resSchema != nil && resSchema.IsSensitiveField(change.Path) {
+				continue
+			}
+			c := Change{Change: change}
 			if resSchema != nil {
 				c.Computed = resSchema.IsComputedField(c.Path)
 				c.JsonString = resSchema.IsJsonStringField(c.Path)
@@ -125,6 +160,16 @@ func (a Analyzer) Analyze(remoteResources, resourcesFromState []*resource.Resour
 		}
 	}
 
+	filteredRemoteResource = remoteIndex.remaining(filteredRemoteResource)
+
+	if a.rules.HasManagedRules() {
+		filteredRemoteResource, deletedResources = a.applyManagedRules(&analysis, filteredRemoteResource, filteredStateResources, deletedResources)
+	}
+
+	if !analysis.Options().OnlyUnmanaged {
+		analysis.AddDeleted(deletedResources...)
+	}
+
 	if a.hasUnmanagedSecurityGroupRules(filteredRemoteResource) {
 		a.alerter.SendAlert("", newUnmanagedSecurityGroupRulesAlert())
 	}
@@ -147,20 +192,139 @@ func (a Analyzer) Analyze(remoteResources, resourcesFromState []*resource.Resour
 	return analysis, nil
 }
 
-func findCorrespondingRes(resources []*resource.Resource, res *resource.Resource) (int, *resource.Resource, bool) {
-	for i, r := range resources {
+// resourceIndex partitions resources by type and id, so we do not have to walk through
+// every remote resource to find the one corresponding to a state resource.
+type resourceIndex struct {
+	resources map[resourceIndexKey][]*resource.Resource
+}
+
+type resourceIndexKey struct {
+	ty string
+	id string
+}
+
+func newResourceIndex(resources []*resource.Resource) *resourceIndex {
+	index := &resourceIndex{
+		resources: make(map[resourceIndexKey][]*resource.Resource, len(resources)),
+	}
+	for _, res := range resources {
+		key := newResourceIndexKey(res)
+		index.resources[key] = append(index.resources[key], res)
+	}
+	return index
+}
+
+func newResourceIndexKey(res *resource.Resource) resourceIndexKey {
+	return resourceIndexKey{res.ResourceType(), res.ResourceId()}
+}
+
+// find returns the first indexed resource equal to the given one
+func (i *resourceIndex) find(res *resource.Resource) (*resource.Resource, bool) {
+	for _, r := range i.resources[newResourceIndexKey(res)] {
 		if res.Equal(r) {
-			return i, r, true
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// remove drops the given resource from the index, it returns false if it was not indexed
+func (i *resourceIndex) remove(res *resource.Resource) bool {
+	key := newResourceIndexKey(res)
+	candidates := i.resources[key]
+	for j, r := range candidates {
+		if r == res {
+			if len(candidates) == 1 {
+				delete(i.resources, key)
+			} else {
+				i.resources[key] = append(candidates[:j], candidates[j+1:]...)
+			}
+			return true
 		}
 	}
-	return -1, nil, false
+	return false
 }
 
-func removeResourceByIndex(i int, resources []*resource.Resource) []*resource.Resource {
-	if i == len(resources)-1 {
-		return resources[:len(resources)-1]
+// remaining returns resources that were not removed from the index, keeping the given order.
+// The given slice is reused and the index is emptied, so we do not hold two copies of remote resources.
+func (i *resourceIndex) remaining(resources []*resource.Resource) []*resource.Resource {
+	result := resources[:0]
+	for _, res := range resources {
+		if i.remove(res) {
+			result = append(result, res)
+		}
+	}
+	for j := len(result); j < len(resources); j++ {
+		resources[j] = nil
 	}
-	return append(resources[:i], resources[i+1:]...)
+	return result
+}
+
+// applyManagedRules removes unmanaged resources matching a state resource according to user rules.
+// Missing resources matched by an unmanaged one are considered as managed.
+func (a Analyzer) applyManagedRules(analysis *Analysis, unmanaged, stateResources, deleted []*resource.Resource) ([]*resource.Resource, []*resource.Resource) {
+	// State resources are grouped by type, and converted once for the rules, so each unmanaged resource is only
+	// compared to state resources of types a rule can match
+	stateTypes := make([]string, 0)
+	stateByType := make(map[string][]*resource.Resource)
+	for _, stateRes := range stateResources {
+		if _, exist := stateByType[stateRes.ResourceType()]; !exist {
+			stateTypes = append(stateTypes, stateRes.ResourceType())
+		}
+		stateByType[stateRes.ResourceType()] = append(stateByType[stateRes.ResourceType()], stateRes)
+	}
+	stateMaps := make(map[*resource.Resource]map[string]interface{}, len(stateResources))
+	candidateTypes := make(map[string][]string)
+
+	matchedState := make(map[*resource.Resource]struct{})
+	remainingUnmanaged := make([]*resource.Resource, 0, len(unmanaged))
+	for _, remoteRes := range unmanaged {
+		candidates, exist := candidateTypes[remoteRes.ResourceType()]
+		if !exist {
+			candidates = make([]string, 0)
+			for _, ty := range stateTypes {
+				if a.rules.MayBeManaged(ty, remoteRes.ResourceType()) {
+					candidates = append(candidates, ty)
+				}
+			}
+			candidateTypes[remoteRes.ResourceType()] = candidates
+		}
+
+		matched := false
+		var remoteMap map[string]interface{}
+	candidatesLoop:
+		for _, ty := range candidates {
+			for _, stateRes := range stateByType[ty] {
+				if remoteMap == nil {
+					remoteMap = rules.ResourceToMap(remoteRes)
+				}
+				stateMap, exist := stateMaps[stateRes]
+				if !exist {
+					stateMap = rules.ResourceToMap(stateRes)
+					stateMaps[stateRes] = stateMap
+				}
+				if a.rules.IsManagedMap(stateMap, remoteMap) {
+					matchedState[stateRes] = struct{}{}
+					matched = true
+					break candidatesLoop
+				}
+			}
+		}
+		if !matched {
+			remainingUnmanaged = append(remainingUnmanaged, remoteRes)
+		}
+	}
+
+	remainingDeleted := make([]*resource.Resource, 0, len(deleted))
+	for _, stateRes := range deleted {
+		if _, ok := matchedState[stateRes]; ok {
+			analysis.AddManaged(stateRes)
+			continue
+		}
+		remainingDeleted = append(remainingDeleted, stateRes)
+	}
+
+	return remainingUnmanaged, remainingDeleted
 }
 
 // hasUnmanagedSecurityGroupRules returns true if we find at least one unmanaged
@@ -0,0 +1,166 @@
+package analyser
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/snyk/driftctl/pkg/alerter"
+	"github.com/snyk/driftctl/pkg/resource"
+)
+
+// SetOrigin tags every resource of the analysis that does not have an origin yet
+func (a *Analysis) SetOrigin(origin resource.Origin) {
+	tag := func(resources ...*resource.Resource) {
+		for _, res := range resources {
+			if res.Origin == nil {
+				o := origin
+				res.Origin = &o
+			}
+		}
+	}
+	tag(a.managed...)
+	tag(a.unmanaged...)
+	tag(a.deleted...)
+	for _, d := range a.differences {
+		tag(d.Res)
+	}
+}
+
+type mergeKey struct {
+	provider string
+	account  string
+	ty       string
+	id       string
+}
+
+func newMergeKey(res *resource.Resource) mergeKey {
+	key := mergeKey{ty: res.ResourceType(), id: res.ResourceId()}
+	if res.Origin != nil {
+		key.provider = res.Origin.Provider
+		key.account = res.Origin.Account
+	}
+	return key
+}
+
+type alertKey struct {
+	key     string
+	message string
+}
+
+// Merge combines several analyses into a single one and recomputes the summary.
+// Resources are identified by their type, id, and origin provider and account:
+// a resource reported as unmanaged by a scan but managed by another scan of the
+// same account is considered as managed, and so is a resource reported as deleted
+// by a scan but found by another scan of the same account. Alerts sharing the same
+// key and message are only kept once.
+func Merge(analyses ...*Analysis) *Analysis {
+	result := NewAnalysis(AnalyzerOptions{})
+	if len(analyses) == 0 {
+		return result
+	}
+
+	result.options = analyses[0].options
+	managedKeys := make(map[mergeKey]struct{})
+	providerNames := make([]string, 0, len(analyses))
+	providerVersions := make([]string, 0, len(analyses))
+	var iacSourceCount uint
+	for _, analysis := range analyses {
+		result.options.Deep = result.options.Deep || analysis.options.Deep
+		result.options.OnlyManaged = result.options.OnlyManaged && analysis.options.OnlyManaged
+		result.options.OnlyUnmanaged = result.options.OnlyUnmanaged && analysis.options.OnlyUnmanaged
+
+		for _, res := range analysis.managed {
+			key := newMergeKey(res)
+			if _, exist := managedKeys[key]; exist {
+				continue
+			}
+			managedKeys[key] = struct{}{}
+			result.AddManaged(res)
+		}
+
+		if analysis.Date.After(result.Date) {
+			result.Date = analysis.Date
+		}
+		result.Duration += analysis.Duration
+		iacSourceCount += analysis.summary.TotalIaCSourceCount
+		providerNames = appendDistinct(providerNames, analysis.ProviderName)
+		providerVersions = appendDistinct(providerVersions, analysis.ProviderVersion)
+	}
+
+	unmanagedKeys := make(map[mergeKey]struct{})
+	deletedKeys := make(map[mergeKey]struct{})
+	differenceKeys := make(map[mergeKey]struct{})
+	alertKeys := make(map[alertKey]struct{})
+	for _, analysis := range analyses {
+		for _, res := range analysis.unmanaged {
+			key := newMergeKey(res)
+			if _, managed := managedKeys[key]; managed {
+				continue
+			}
+			if _, exist := unmanagedKeys[key]; exist {
+				continue
+			}
+			unmanagedKeys[key] = struct{}{}
+			result.AddUnmanaged(res)
+		}
+		for _, res := range analysis.deleted {
+			key := newMergeKey(res)
+			if _, managed := managedKeys[key]; managed {
+				continue
+			}
+			if _, exist := deletedKeys[key]; exist {
+				continue
+			}
+			deletedKeys[key] = struct{}{}
+			result.AddDeleted(res)
+		}
+		for _, difference := range analysis.differences {
+			key := newMergeKey(difference.Res)
+			if _, exist := differenceKeys[key]; exist {
+				continue
+			}
+			differenceKeys[key] = struct{}{}
+			result.AddDifference(difference)
+		}
+		result.AddUnscannedTypes(analysis.unscannedTypes...)
+
+		for k, alerts := range analysis.alerts {
+			if result.alerts == nil {
+				result.alerts = make(alerter.Alerts)
+			}
+			for _, alert := range alerts {
+				key := alertKey{key: k, message: alert.Message()}
+				if _, exist := alertKeys[key]; exist {
+					continue
+				}
+				alertKeys[key] = struct{}{}
+				result.alerts[k] = append(result.alerts[k], alert)
+			}
+		}
+	}
+
+	result.SetIaCSourceCount(iacSourceCount)
+	result.ProviderName = strings.Join(providerNames, ", ")
+	result.ProviderVersion = strings.Join(providerVersions, ", ")
+	// Scan metadata describes a single scan, merged analyses rely on the origin of their resources instead
+	if len(analyses) == 1 {
+		result.Scan = analyses[0].Scan
+	}
+	result.SortResources()
+
+	return result
+}
+
+func appendDistinct(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	i := sort.SearchStrings(values, value)
+	if i < len(values) && values[i] == value {
+		return values
+	}
+	values = append(values, "")
+	copy(values[i+1:], values[i:])
+	values[i] = value
+	return values
+}
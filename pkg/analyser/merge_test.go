@@ -0,0 +1,105 @@
+package analyser
+
+import (
+	"testing"
+	"time"
+
+	"github.com/r3labs/diff/v2"
+	"github.com/snyk/driftctl/pkg/alerter"
+	"github.com/snyk/driftctl/pkg/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	prod := NewAnalysis(AnalyzerOptions{Deep: true})
+	prod.ProviderName = "AWS"
+	prod.ProviderVersion = "3.19.0"
+	prod.Date = time.Date(2022, 4, 8, 10, 35, 0, 0, time.UTC)
+	prod.Duration = 10 * time.Second
+	prod.SetIaCSourceCount(2)
+	prod.AddManaged(&resource.Resource{Id: "bucket-1", Type: "aws_s3_bucket"})
+	prod.AddUnmanaged(
+		&resource.Resource{Id: "bucket-2", Type: "aws_s3_bucket"},
+		&resource.Resource{Id: "bucket-3", Type: "aws_s3_bucket"},
+	)
+	prod.AddDeleted(
+		&resource.Resource{Id: "role-1", Type: "aws_iam_role"},
+		&resource.Resource{Id: "role-2", Type: "aws_iam_role"},
+	)
+	prod.AddDifference(Difference{
+		Res: &resource.Resource{Id: "bucket-1", Type: "aws_s3_bucket"},
+		Changelog: []Change{
+			{Change: diff.Change{Type: diff.UPDATE, Path: []string{"acl"}, From: "private", To: "public-read"}},
+		},
+	})
+	prod.SetAlerts(alerter.Alerts{"aws_s3_bucket": {&alerter.FakeAlert{Msg: "prod alert"}}})
+	prod.SetOrigin(resource.Origin{Provider: "AWS", Account: "prod", File: "prod.json"})
+
+	// Another scan of the prod account, using a different IaC source
+	prodNetwork := NewAnalysis(AnalyzerOptions{})
+	prodNetwork.ProviderName = "AWS"
+	prodNetwork.ProviderVersion = "3.19.0"
+	prodNetwork.Date = time.Date(2022, 4, 8, 11, 0, 0, 0, time.UTC)
+	prodNetwork.Duration = 5 * time.Second
+	prodNetwork.SetIaCSourceCount(1)
+	// role-2 is missing from the IaC of the first scan but found by this one
+	prodNetwork.AddManaged(
+		&resource.Resource{Id: "bucket-2", Type: "aws_s3_bucket"},
+		&resource.Resource{Id: "role-2", Type: "aws_iam_role"},
+	)
+	prodNetwork.AddUnmanaged(&resource.Resource{Id: "bucket-3", Type: "aws_s3_bucket"})
+	// Overlapping scans report the same drift, it must be counted once
+	prodNetwork.AddDifference(Difference{
+		Res: &resource.Resource{Id: "bucket-1", Type: "aws_s3_bucket"},
+		Changelog: []Change{
+			{Change: diff.Change{Type: diff.UPDATE, Path: []string{"acl"}, From: "private", To: "public-read"}},
+		},
+	})
+	// Overlapping scans raise the same alerts, they must be reported once
+	prodNetwork.SetAlerts(alerter.Alerts{"aws_s3_bucket": {&alerter.FakeAlert{Msg: "prod alert"}}})
+	prodNetwork.SetOrigin(resource.Origin{Provider: "AWS", Account: "prod", File: "prod-network.json"})
+
+	github := NewAnalysis(AnalyzerOptions{})
+	github.ProviderName = "Github"
+	github.ProviderVersion = "4.4.0"
+	github.SetIaCSourceCount(1)
+	github.AddUnmanaged(&resource.Resource{Id: "bucket-3", Type: "aws_s3_bucket"})
+	github.SetAlerts(alerter.Alerts{"aws_s3_bucket": {&alerter.FakeAlert{Msg: "github alert"}}})
+	github.SetOrigin(resource.Origin{Provider: "Github", File: "github.json"})
+
+	got := Merge(prod, prodNetwork, github)
+
+	assert.Equal(t, Summary{
+		TotalResources:      6,
+		TotalDrifted:        1,
+		TotalUnmanaged:      2,
+		TotalDeleted:        1,
+		TotalManaged:        3,
+		TotalIaCSourceCount: 4,
+	}, got.Summary())
+	assert.Equal(t, 50, got.Coverage())
+	assert.True(t, got.Options().Deep)
+	assert.Equal(t, "AWS, Github", got.ProviderName)
+	assert.Equal(t, "3.19.0, 4.4.0", got.ProviderVersion)
+	assert.Equal(t, time.Date(2022, 4, 8, 11, 0, 0, 0, time.UTC), got.Date)
+	assert.Equal(t, 15*time.Second, got.Duration)
+
+	assert.Equal(t, &resource.Origin{Provider: "AWS", Account: "prod", File: "prod.json"}, got.Unmanaged()[0].Origin)
+	assert.Equal(t, &resource.Origin{Provider: "Github", File: "github.json"}, got.Unmanaged()[1].Origin)
+	assert.Equal(t, &resource.Origin{Provider: "AWS", Account: "prod", File: "prod-network.json"}, got.Managed()[1].Origin)
+	assert.Equal(t, "role-1", got.Deleted()[0].ResourceId())
+	assert.Len(t, got.Alerts()["aws_s3_bucket"], 2)
+	assert.Len(t, got.Differences(), 1)
+}
+
+func TestAnalysis_SetOrigin_KeepExistingOrigin(t *testing.T) {
+	analysis := NewAnalysis(AnalyzerOptions{})
+	analysis.AddManaged(
+		&resource.Resource{Id: "foo", Type: "aws_s3_bucket", Origin: &resource.Origin{File: "merged.json", Account: "prod"}},
+		&resource.Resource{Id: "bar", Type: "aws_s3_bucket"},
+	)
+	analysis.SetOrigin(resource.Origin{File: "other.json"})
+
+	assert.Equal(t, "merged.json", analysis.Managed()[0].Origin.File)
+	assert.Equal(t, "other.json", analysis.Managed()[1].Origin.File)
+}
@@ -0,0 +1,33 @@
+package analyser
+
+import (
+	_ "embed"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// JSONSchema describes the analysis JSON format
+//
+//go:embed analysis.schema.json
+var JSONSchema []byte
+
+// ValidateJSON checks that the given serialized analysis matches the JSON schema
+func ValidateJSON(analysis []byte) error {
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(JSONSchema), gojsonschema.NewBytesLoader(analysis))
+	if err != nil {
+		return errors.Wrap(err, "unable to validate analysis")
+	}
+	if result.Valid() {
+		return nil
+	}
+
+	messages := make([]string, 0, len(result.Errors()))
+	for _, e := range result.Errors() {
+		messages = append(messages, "  - "+e.String())
+	}
+	sort.Strings(messages)
+	return errors.Errorf("invalid analysis:\n%s", strings.Join(messages, "\n"))
+}
@@ -0,0 +1,56 @@
+package analyser
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateJSON(t *testing.T) {
+	cases := []struct {
+		name  string
+		input func() []byte
+		err   string
+	}{
+		{
+			name: "current format",
+			input: func() []byte {
+				b, _ := ioutil.ReadFile("testdata/output.json")
+				return b
+			},
+		},
+		{
+			name: "format without version",
+			input: func() []byte {
+				b, _ := ioutil.ReadFile("testdata/input.json")
+				return b
+			},
+		},
+		{
+			name: "invalid analysis",
+			input: func() []byte {
+				return []byte(`{"options": {}, "summary": {"total_resources": "6"}, "coverage": 120, "managed": [{"id": "foo"}]}`)
+			},
+			err: "invalid analysis:\n" +
+				"  - coverage: Must be less than or equal to 100\n" +
+				"  - managed.0: type is required\n" +
+				"  - summary.total_resources: Invalid type. Expected: integer, given: string\n" +
+				"  - summary: total_changed is required\n" +
+				"  - summary: total_managed is required\n" +
+				"  - summary: total_missing is required\n" +
+				"  - summary: total_unmanaged is required",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := ValidateJSON(c.input())
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/snyk/driftctl/pkg"
+	"github.com/snyk/driftctl/pkg/alerter"
+	"github.com/snyk/driftctl/pkg/filter"
+	globaloutput "github.com/snyk/driftctl/pkg/output"
+	"github.com/snyk/driftctl/pkg/remote"
+	remoteaws "github.com/snyk/driftctl/pkg/remote/aws"
+	"github.com/snyk/driftctl/pkg/remote/common"
+	remoteerror "github.com/snyk/driftctl/pkg/remote/error"
+	"github.com/snyk/driftctl/pkg/remote/paging"
+	"github.com/snyk/driftctl/pkg/remote/retry"
+	"github.com/snyk/driftctl/pkg/resource"
+	"github.com/snyk/driftctl/pkg/terraform"
+)
+
+func NewDoctorCmd(opts *pkg.DoctorOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check credentials, permissions and terraform provider before a scan",
+		Long: "Check that driftctl is able to authenticate on the cloud provider, that the terraform provider is installed " +
+			"or can be downloaded, and that every resource type can be listed.\n\n" +
+			"Example: driftctl doctor --to aws+tf",
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			to, _ := cmd.Flags().GetString("to")
+			if !remote.IsSupported(to) {
+				return errors.Errorf(
+					"unsupported cloud provider '%s'\nValid values are: %s",
+					to,
+					strings.Join(remote.GetSupportedRemotes(), ","),
+				)
+			}
+
+			var err error
+			opts.ProviderVersion, err = getProviderVersion(cmd, to)
+			if err != nil {
+				return err
+			}
+
+			opts.ProviderInstallOptions = getProviderInstallOptions(cmd, to, opts.ProviderVersion)
+
+			return opts.AWSEndpoints.Validate()
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return doctorRun(opts, os.Stdout)
+		},
+	}
+
+	fl := cmd.Flags()
+	supportedRemotes := remote.GetSupportedRemotes()
+	fl.StringVarP(
+		&opts.To,
+		"to",
+		"t",
+		supportedRemotes[0],
+		"Cloud provider source\n"+
+			"Accepted values are: "+strings.Join(supportedRemotes, ",")+"\n",
+	)
+	fl.String(
+		"tf-provider-version",
+		"",
+		"Terraform provider version to use.\n",
+	)
+	fl.String(
+		"tf-lockfile",
+		".terraform.lock.hcl",
+		"Terraform lock file to get the provider's version from. Will be ignored if the file doesn't exist.\n",
+	)
+	fl.StringVar(&opts.DriftignorePath,
+		"driftignore",
+		".driftignore",
+		"Path to the driftignore file, ignored resource types are not checked",
+	)
+	fl.StringSliceVar(&opts.Driftignores,
+		"ignore",
+		[]string{},
+		"Patterns to be used for ignoring resources\n"+
+			"When using this parameter the driftignore file is not processed\n",
+	)
+
+	configDir, err := homedir.Dir()
+	if err != nil {
+		configDir = os.TempDir()
+	}
+	fl.String(
+		"config-dir",
+		configDir,
+		"Directory path that driftctl uses for configuration.\n",
+	)
+	fl.String(
+		"tf-provider-mirror-dir",
+		"",
+		"Terraform filesystem mirror to install the provider from, using either the packed or the unpacked layout\n"+
+			"The TF_PLUGIN_CACHE_DIR environment variable is also honoured\n",
+	)
+	fl.String(
+		"tf-provider-mirror-url",
+		"",
+		"Terraform network mirror to download the provider from instead of releases.hashicorp.com\n",
+	)
+	fl.String(
+		"tf-provider-registry",
+		"",
+		"Provider registry to download the provider from instead of releases.hashicorp.com, optionally followed by a namespace\n"+
+			"e.g. registry.opentofu.org or registry.example.com/acme. Also used to find the provider in the terraform lock file\n",
+	)
+	fl.StringVar(&opts.AWSEndpoints.URL,
+		"aws-endpoint-url",
+		os.Getenv("AWS_ENDPOINT_URL"),
+		"Endpoint of every AWS service, e.g. http://localhost:4566 to check LocalStack\n"+
+			"Defaults to the AWS_ENDPOINT_URL environment variable\n",
+	)
+	fl.StringToStringVar(&opts.AWSEndpoints.Services,
+		"aws-endpoints",
+		map[string]string{},
+		"Endpoints of specific AWS services, named as in the endpoints block of the terraform AWS provider\n"+
+			"e.g. s3=http://localhost:4566,sts=http://localhost:4566\n",
+	)
+
+	return cmd
+}
+
+func doctorRun(opts *pkg.DoctorOptions, out io.Writer) error {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+
+	alerter := alerter.NewAlerter()
+
+	globaloutput.ChangePrinter(globaloutput.NewConsolePrinter())
+
+	providerLibrary := terraform.NewProviderLibrary()
+	remoteLibrary := common.NewRemoteLibrary()
+
+	progress := globaloutput.NewProgress("Checking resources", "Checked resources", false)
+
+	resourceSchemaRepository := resource.NewSchemaRepository()
+
+	resFactory := terraform.NewTerraformResourceFactory(resourceSchemaRepository)
+
+	defer func() {
+		logrus.Trace("Exiting doctor cmd")
+		providerLibrary.Cleanup()
+		logrus.Trace("Exited")
+	}()
+
+	results := make([]remote.CheckResult, 0)
+
+	err := remote.Activate(opts.To, opts.ProviderVersion, alerter, providerLibrary, remoteLibrary, progress, resourceSchemaRepository, resFactory, remote.Options{
+		Options: common.Options{
+			ProviderInstallOptions: opts.ProviderInstallOptions,
+			RetryPolicy:            retry.NewPolicy(retry.DefaultOptions()),
+			PageLimiter:            paging.NewFirstPageLimiter(),
+		},
+		AWS: remoteaws.Options{
+			Endpoints: opts.AWSEndpoints,
+		},
+	})
+	switch err.(type) {
+	case nil:
+		results = append(results,
+			remote.CheckResult{Name: "credentials", Status: remote.CheckPass},
+			remote.CheckResult{
+				Name:    "terraform provider",
+				Status:  remote.CheckPass,
+				Message: fmt.Sprintf("%s@%s", resourceSchemaRepository.ProviderName, resourceSchemaRepository.ProviderVersion),
+			},
+		)
+	case *remoteerror.CredentialsError:
+		results = append(results, remote.CheckResult{Name: "credentials", Status: remote.CheckFail, Message: err.Error()})
+		printCheckResults(out, results)
+		return errors.New("Preflight checks failed")
+	case *remoteerror.ProviderInitError:
+		results = append(results,
+			remote.CheckResult{Name: "credentials", Status: remote.CheckPass},
+			remote.CheckResult{Name: "terraform provider", Status: remote.CheckFail, Message: err.Error()},
+		)
+		printCheckResults(out, results)
+		return errors.New("Preflight checks failed")
+	default:
+		return err
+	}
+
+	logrus.Debug("Checking for driftignore")
+	driftIgnore := filter.NewDriftIgnore(opts.DriftignorePath, opts.Driftignores...)
+
+	doctor := remote.NewDoctor(remoteLibrary, alerter, driftIgnore)
+
+	go func() {
+		<-c
+		logrus.Warn("Detected interrupt, cleanup ...")
+		doctor.Stop()
+	}()
+
+	progress.Start()
+	probes, err := doctor.Probe()
+	progress.Stop()
+	if err != nil {
+		return err
+	}
+	results = append(results, probes...)
+
+	printCheckResults(out, results)
+
+	for _, r := range results {
+		if r.Status == remote.CheckFail {
+			return errors.New("Preflight checks failed")
+		}
+	}
+
+	return nil
+}
+
+func printCheckResults(out io.Writer, results []remote.CheckResult) {
+	nameWidth := 0
+	for _, r := range results {
+		if len(r.Name) > nameWidth {
+			nameWidth = len(r.Name)
+		}
+	}
+
+	counts := make(map[remote.CheckStatus]int)
+	for _, r := range results {
+		counts[r.Status]++
+
+		status := strings.ToUpper(string(r.Status))
+		switch r.Status {
+		case remote.CheckPass:
+			status = color.GreenString(status)
+		case remote.CheckWarn:
+			status = color.YellowString(status)
+		case remote.CheckFail:
+			status = color.RedString(status)
+		}
+
+		_, _ = fmt.Fprintf(out, "%s  %-*s", status, nameWidth, r.Name)
+		for i, line := range strings.Split(r.Message, "\n") {
+			if line == "" {
+				continue
+			}
+			if i == 0 {
+				_, _ = fmt.Fprintf(out, "  %s", line)
+				continue
+			}
+			_, _ = fmt.Fprintf(out, "\n      %-*s  %s", nameWidth, "", line)
+		}
+		_, _ = fmt.Fprintln(out)
+	}
+
+	_, _ = fmt.Fprintf(out, "\n%d passed, %d warning(s), %d failed\n", counts[remote.CheckPass], counts[remote.CheckWarn], counts[remote.CheckFail])
+}
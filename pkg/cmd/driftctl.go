@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/snyk/driftctl/build"
 	"github.com/snyk/driftctl/pkg"
+	"github.com/snyk/driftctl/pkg/resource"
 	"github.com/snyk/driftctl/sentry"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -71,6 +72,9 @@ func NewDriftctlCmd(build build.BuildInterface) *DriftctlCmd {
 	cmd.AddCommand(NewScanCmd(&pkg.ScanOptions{}))
 	cmd.AddCommand(NewFmtCmd(&pkg.FmtOptions{}))
 	cmd.AddCommand(NewGenDriftIgnoreCmd())
+	cmd.AddCommand(NewDoctorCmd(&pkg.DoctorOptions{}))
+	cmd.AddCommand(NewMergeCmd(&pkg.MergeOptions{}))
+	cmd.AddCommand(NewProvidersCmd(&pkg.ProvidersOptions{}))
 
 	return cmd
 }
@@ -108,6 +112,15 @@ func handleReporting(cmd *cobra.Command) error {
 	return nil
 }
 
+// sensitiveFlagAnnotation marks flags holding secrets, their values are never logged
+const sensitiveFlagAnnotation = "driftctl_sensitive"
+
+func markFlagsSensitive(fl *pflag.FlagSet, names ...string) {
+	for _, name := range names {
+		_ = fl.SetAnnotation(name, sensitiveFlagAnnotation, []string{"true"})
+	}
+}
+
 // Iterate over command flags
 // If the command flag is not manually set (f.Changed) we override its value
 // from the according env value
@@ -132,6 +145,9 @@ func bindEnvToFlags(cmd *cobra.Command) error {
 			if err != nil {
 				return
 			}
+			if _, sensitive := f.Annotations[sensitiveFlagAnnotation]; sensitive {
+				envVal = resource.SensitiveValue
+			}
 			logrus.WithFields(logrus.Fields{
 				"env":   envKey,
 				"flag":  f.Name,
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/snyk/driftctl/pkg/config"
 	"github.com/snyk/driftctl/test"
 	"github.com/snyk/driftctl/test/mocks"
@@ -82,7 +84,7 @@ func TestDriftctlCmd_Scan(t *testing.T) {
 			env: map[string]string{
 				"DCTL_FROM": "test",
 			},
-			err: fmt.Errorf("Unable to parse from flag 'test': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://"),
+			err: fmt.Errorf("Unable to parse from flag 'test': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://,tfdir://"),
 		},
 		{
 			env: map[string]string{
@@ -94,7 +96,7 @@ func TestDriftctlCmd_Scan(t *testing.T) {
 			env: map[string]string{
 				"DCTL_OUTPUT": "test",
 			},
-			err: fmt.Errorf("Unable to parse output flag 'test': \nAccepted formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json"),
+			err: fmt.Errorf("Unable to parse output flag 'test': \nAccepted formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json,template://PATH/TO/TEMPLATE.tmpl:PATH/TO/FILE"),
 		},
 		{
 			env: map[string]string{
@@ -140,6 +142,38 @@ func TestDriftctlCmd_Scan(t *testing.T) {
 	}
 }
 
+func TestDriftctlCmd_SensitiveEnvIsNotLogged(t *testing.T) {
+	hook := logtest.NewGlobal()
+	level := logrus.GetLevel()
+	logrus.SetLevel(logrus.DebugLevel)
+	defer logrus.SetLevel(level)
+
+	_ = os.Setenv("DCTL_HTTP_OAUTH2_CLIENT_ID", "driftctl")
+	defer os.Unsetenv("DCTL_HTTP_OAUTH2_CLIENT_ID")
+	_ = os.Setenv("DCTL_HTTP_OAUTH2_CLIENT_SECRET", "s3cr3t")
+	defer os.Unsetenv("DCTL_HTTP_OAUTH2_CLIENT_SECRET")
+	_ = os.Setenv("DCTL_TF_STATE_PASSPHRASE", "correct horse battery staple")
+	defer os.Unsetenv("DCTL_TF_STATE_PASSPHRASE")
+
+	config.Init()
+	cmd := NewDriftctlCmd(mocks.MockBuild{})
+	scanCmd, _, _ := cmd.Find([]string{"scan"})
+	scanCmd.PreRunE = nil
+	scanCmd.RunE = func(_ *cobra.Command, args []string) error { return nil }
+	_, err := test.Execute(&cmd.Command, "scan")
+	assert.NoError(t, err)
+
+	values := make(map[string]interface{})
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "Bound environment variable to flag" {
+			values[entry.Data["flag"].(string)] = entry.Data["value"]
+		}
+	}
+	assert.Equal(t, "driftctl", values["http-oauth2-client-id"])
+	assert.Equal(t, "(sensitive value)", values["http-oauth2-client-secret"])
+	assert.Equal(t, "(sensitive value)", values["tf-state-passphrase"])
+}
+
 func TestDriftctlCmd_Invalid(t *testing.T) {
 	cmd := NewDriftctlCmd(mocks.MockBuild{})
 
@@ -1,7 +1,26 @@
 package errors
 
-type InfrastructureNotInSync struct{}
+import (
+	"fmt"
+	"strings"
+)
+
+// InfrastructureNotInSync is returned when drifts were found. It takes precedence over an incomplete scan
+// as found drifts are real whatever the resources that could not be scanned.
+type InfrastructureNotInSync struct {
+	// IncompleteKinds are the kinds of failing alerts raised along with drifts
+	IncompleteKinds []string
+}
 
 func (i InfrastructureNotInSync) Error() string {
 	return "Infrastructure is not in sync"
 }
+
+// IncompleteScan is returned when the scan raised alerts configured to fail with the alert policy
+type IncompleteScan struct {
+	Kinds []string
+}
+
+func (i IncompleteScan) Error() string {
+	return fmt.Sprintf("Scan is incomplete, got alerts of kind: %s", strings.Join(i.Kinds, ", "))
+}
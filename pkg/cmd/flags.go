@@ -9,6 +9,7 @@ import (
 	"github.com/snyk/driftctl/pkg/cmd/scan/output"
 	"github.com/snyk/driftctl/pkg/iac/config"
 	"github.com/snyk/driftctl/pkg/iac/supplier"
+	"github.com/snyk/driftctl/pkg/iac/terraform/discovery"
 	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend"
 )
 
@@ -17,7 +18,8 @@ func parseFromFlag(from []string) ([]config.SupplierConfig, error) {
 	configs := make([]config.SupplierConfig, 0, len(from))
 
 	for _, flag := range from {
-		schemePath := strings.Split(flag, "://")
+		// Paths can hold URLs in their options (e.g. ?endpoint=https://minio.example.com), only split on the first scheme
+		schemePath := strings.SplitN(flag, "://", 2)
 		if len(schemePath) != 2 || schemePath[1] == "" || schemePath[0] == "" {
 			return nil, errors.Wrapf(
 				cmderrors.NewUsageError(
@@ -47,6 +49,15 @@ func parseFromFlag(from []string) ([]config.SupplierConfig, error) {
 		}
 
 		supplierKey := supplierBackend[0]
+		if scheme == discovery.TerraformDirScheme {
+			discovered, err := discovery.Discover(path)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Unable to discover IaC sources from '%s'", flag)
+			}
+			configs = append(configs, discovered...)
+			continue
+		}
+
 		if !supplier.IsSupplierSupported(supplierKey) {
 			return nil, errors.Wrapf(
 				cmderrors.NewUsageError(
@@ -100,7 +111,8 @@ func parseOutputFlags(out []string) ([]output.OutputConfig, error) {
 }
 
 func parseOutputFlag(out string) (*output.OutputConfig, error) {
-	schemeOpts := strings.Split(out, "://")
+	// Destinations can be remote objects (e.g. json://s3://bucket/key.json), only split on the first scheme
+	schemeOpts := strings.SplitN(out, "://", 2)
 	if len(schemeOpts) < 2 || schemeOpts[0] == "" {
 		return nil, errors.Wrapf(
 			cmderrors.NewUsageError(
@@ -175,7 +187,49 @@ func parseOutputFlag(out string) (*output.OutputConfig, error) {
 			)
 		}
 		o.Path = opts[0]
+	case output.TemplateOutputType:
+		var paths []string
+		if len(opts) == 1 {
+			paths = splitTemplateOutput(opts[0])
+		}
+		if len(paths) != 2 || paths[0] == "" || paths[1] == "" {
+			return nil, errors.Wrapf(
+				cmderrors.NewUsageError(
+					fmt.Sprintf(
+						"\nMust be of kind: %s",
+						output.Example(output.TemplateOutputType),
+					),
+				),
+				"Invalid template output '%s'",
+				out,
+			)
+		}
+		o.TemplatePath = paths[0]
+		o.Path = paths[1]
 	}
 
 	return o, nil
 }
+
+// splitTemplateOutput splits TEMPLATE:DESTINATION on the first colon that is not the one of a Windows drive letter
+// (e.g. C:\tpl.tmpl:out.txt). Destinations can be remote objects holding colons, e.g. gs://bucket/report.md.
+func splitTemplateOutput(opts string) []string {
+	for i := 0; i < len(opts); i++ {
+		if opts[i] != ':' {
+			continue
+		}
+		if i == 1 && isDriveLetterColon(opts, i) {
+			continue
+		}
+		return []string{opts[:i], opts[i+1:]}
+	}
+	return []string{opts}
+}
+
+func isDriveLetterColon(path string, i int) bool {
+	letter := path[i-1]
+	if !('a' <= letter && letter <= 'z' || 'A' <= letter && letter <= 'Z') {
+		return false
+	}
+	return i+1 < len(path) && (path[i+1] == '\\' || path[i+1] == '/')
+}
@@ -52,6 +52,20 @@ func Test_parseFromFlag(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "test from parsing with an URL in options",
+			args: args{
+				from: []string{"tfstate+s3://bucket/path/to/state.tfstate?endpoint=http://localhost:9000"},
+			},
+			want: []config.SupplierConfig{
+				{
+					Key:     "tfstate",
+					Backend: "s3",
+					Path:    "bucket/path/to/state.tfstate?endpoint=http://localhost:9000",
+				},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -83,7 +97,7 @@ func Test_parseOutputFlag(t *testing.T) {
 				out: []string{""},
 			},
 			want: []output.OutputConfig{},
-			err:  fmt.Errorf("Unable to parse output flag '': \nAccepted formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json"),
+			err:  fmt.Errorf("Unable to parse output flag '': \nAccepted formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json,template://PATH/TO/TEMPLATE.tmpl:PATH/TO/FILE"),
 		},
 		{
 			name: "test empty array",
@@ -99,7 +113,7 @@ func Test_parseOutputFlag(t *testing.T) {
 				out: []string{"sdgjsdgjsdg"},
 			},
 			want: []output.OutputConfig{},
-			err:  fmt.Errorf("Unable to parse output flag 'sdgjsdgjsdg': \nAccepted formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json"),
+			err:  fmt.Errorf("Unable to parse output flag 'sdgjsdgjsdg': \nAccepted formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json,template://PATH/TO/TEMPLATE.tmpl:PATH/TO/FILE"),
 		},
 		{
 			name: "test invalid",
@@ -107,7 +121,7 @@ func Test_parseOutputFlag(t *testing.T) {
 				out: []string{"://"},
 			},
 			want: []output.OutputConfig{},
-			err:  fmt.Errorf("Unable to parse output flag '://': \nAccepted formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json"),
+			err:  fmt.Errorf("Unable to parse output flag '://': \nAccepted formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json,template://PATH/TO/TEMPLATE.tmpl:PATH/TO/FILE"),
 		},
 		{
 			name: "test unsupported",
@@ -115,7 +129,7 @@ func Test_parseOutputFlag(t *testing.T) {
 				out: []string{"foobar://"},
 			},
 			want: []output.OutputConfig{},
-			err:  fmt.Errorf("Unsupported output 'foobar': \nValid formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json"),
+			err:  fmt.Errorf("Unsupported output 'foobar': \nValid formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json,template://PATH/TO/TEMPLATE.tmpl:PATH/TO/FILE"),
 		},
 		{
 			name: "test empty json",
@@ -171,6 +185,70 @@ func Test_parseOutputFlag(t *testing.T) {
 			},
 			err: nil,
 		},
+		{
+			name: "test template output without destination",
+			args: args{
+				out: []string{"template://report.tmpl"},
+			},
+			want: []output.OutputConfig{},
+			err:  fmt.Errorf("Invalid template output 'template://report.tmpl': \nMust be of kind: template://PATH/TO/TEMPLATE.tmpl:PATH/TO/FILE"),
+		},
+		{
+			name: "test valid template output",
+			args: args{
+				out: []string{"template://report.tmpl:/tmp/report.md"},
+			},
+			want: []output.OutputConfig{
+				{
+					Key:          "template",
+					Path:         "/tmp/report.md",
+					TemplatePath: "report.tmpl",
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "test template output with windows paths",
+			args: args{
+				out: []string{"template://C:\\tpl.tmpl:out.txt", "template://C:\\tpl.tmpl:D:\\reports\\out.txt", "template://c:/tpl.tmpl:s3://bucket/out.txt"},
+			},
+			want: []output.OutputConfig{
+				{
+					Key:          "template",
+					Path:         "out.txt",
+					TemplatePath: "C:\\tpl.tmpl",
+				},
+				{
+					Key:          "template",
+					Path:         "D:\\reports\\out.txt",
+					TemplatePath: "C:\\tpl.tmpl",
+				},
+				{
+					Key:          "template",
+					Path:         "s3://bucket/out.txt",
+					TemplatePath: "c:/tpl.tmpl",
+				},
+			},
+			err: nil,
+		},
+		{
+			name: "test remote output destinations",
+			args: args{
+				out: []string{"json://s3://bucket/drift/{{date}}/result.json", "template://report.tmpl:gs://bucket/report.md"},
+			},
+			want: []output.OutputConfig{
+				{
+					Key:  "json",
+					Path: "s3://bucket/drift/{{date}}/result.json",
+				},
+				{
+					Key:          "template",
+					Path:         "gs://bucket/report.md",
+					TemplatePath: "report.tmpl",
+				},
+			},
+			err: nil,
+		},
 		{
 			name: "test multiple output values",
 			args: args{
@@ -197,7 +275,7 @@ func Test_parseOutputFlag(t *testing.T) {
 					Key: "console",
 				},
 			},
-			err: fmt.Errorf("Unsupported output 'invalid': \nValid formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json"),
+			err: fmt.Errorf("Unsupported output 'invalid': \nValid formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json,template://PATH/TO/TEMPLATE.tmpl:PATH/TO/FILE"),
 		},
 		{
 			name: "test multiple valid output values",
@@ -1,9 +1,9 @@
 package cmd
 
 import (
-	"bufio"
 	"encoding/json"
 	"io"
+	"io/ioutil"
 	"os"
 	"strings"
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/snyk/driftctl/pkg"
 	"github.com/snyk/driftctl/pkg/cmd/scan/output"
+	globaloutput "github.com/snyk/driftctl/pkg/output"
 )
 
 func NewFmtCmd(opts *pkg.FmtOptions) *cobra.Command {
@@ -31,6 +32,7 @@ func NewFmtCmd(opts *pkg.FmtOptions) *cobra.Command {
 				return err
 			}
 			opts.Output = out[0]
+			opts.Quiet, _ = cmd.Flags().GetBool("quiet")
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -46,21 +48,40 @@ func NewFmtCmd(opts *pkg.FmtOptions) *cobra.Command {
 		"Output format, by default it will write to the console\n"+
 			"Accepted formats are: "+strings.Join(output.SupportedOutputsExample(), ",")+"\n",
 	)
+	fl.Bool(
+		"quiet",
+		false,
+		"Do not display anything but the analysis",
+	)
+	fl.BoolVar(&opts.Validate,
+		"validate",
+		false,
+		"Only check that the analysis matches the JSON schema of the analysis format, nothing is rendered\n",
+	)
 
 	return cmd
 }
 
 func runFmt(opts *pkg.FmtOptions, reader io.Reader) error {
 
-	var analysisText []byte
-	scanner := bufio.NewScanner(reader)
-	for scanner.Scan() {
-		analysisText = append(analysisText, scanner.Bytes()...)
+	analysisText, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return errors.Wrap(err, "unable to read analysis from stdin")
+	}
+
+	if opts.Validate {
+		if err := analyser.ValidateJSON(analysisText); err != nil {
+			return err
+		}
+		if output.ShouldPrint([]output.OutputConfig{opts.Output}, opts.Quiet) {
+			globaloutput.ChangePrinter(globaloutput.NewConsolePrinter())
+		}
+		globaloutput.Printf("Analysis is valid\n")
+		return nil
 	}
 
 	analysis := analyser.NewAnalysis(analyser.AnalyzerOptions{})
-	err := json.Unmarshal(analysisText, analysis)
-	if err != nil {
+	if err := json.Unmarshal(analysisText, analysis); err != nil {
 		return err
 	}
 
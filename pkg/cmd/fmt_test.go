@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"github.com/snyk/driftctl/pkg"
@@ -79,6 +80,34 @@ func Test_runFmt(t *testing.T) {
 	assert.Equal(t, string(expectedBytes), string(output))
 }
 
+func Test_runFmt_Validate(t *testing.T) {
+	opts := &pkg.FmtOptions{
+		Output: output.OutputConfig{
+			Key: output.ConsoleOutputType,
+		},
+		Validate: true,
+	}
+
+	input, err := os.Open("testdata/fmt/input_stdin_valid.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer input.Close()
+	assert.Nil(t, runFmt(opts, input))
+
+	err = runFmt(opts, strings.NewReader(`{"summary": {}, "coverage": 0}`))
+	require.NotNil(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "invalid analysis:\n  - (root): options is required"))
+
+	// Lines longer than the default buffer of a bufio.Scanner must be read entirely
+	valid, err := os.ReadFile("testdata/fmt/input_stdin_valid.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	longLine := strings.ReplaceAll(string(valid), "\n", strings.Repeat(" ", 1024))
+	assert.Nil(t, runFmt(opts, strings.NewReader(longLine)))
+}
+
 func TestFmtCmd_Valid(t *testing.T) {
 	rootCmd := &cobra.Command{Use: "root"}
 	scanCmd := NewFmtCmd(&pkg.FmtOptions{})
@@ -90,6 +119,7 @@ func TestFmtCmd_Valid(t *testing.T) {
 	}{
 		{args: []string{"fmt"}},
 		{args: []string{"fmt", "-o", "json://test.json"}},
+		{args: []string{"fmt", "--validate"}},
 	}
 
 	for _, tt := range cases {
@@ -112,7 +142,7 @@ func TestFmtCmd_Invalid(t *testing.T) {
 	}{
 		{args: []string{"fmt", "test"}, expected: `unknown command "test" for "root fmt"`},
 		{args: []string{"fmt", "-o", "json://test.json", "-o", "html://test.html"}, expected: "Only one output format can be set"},
-		{args: []string{"fmt", "-o", "foobar://barfoo"}, expected: "Unsupported output 'foobar': \nValid formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json"},
+		{args: []string{"fmt", "-o", "foobar://barfoo"}, expected: "Unsupported output 'foobar': \nValid formats are: console://,html://PATH/TO/FILE.html,json://PATH/TO/FILE.json,plan://PATH/TO/FILE.json,template://PATH/TO/TEMPLATE.tmpl:PATH/TO/FILE"},
 	}
 
 	for _, tt := range cases {
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/snyk/driftctl/pkg"
+	"github.com/snyk/driftctl/pkg/analyser"
+	cmderrors "github.com/snyk/driftctl/pkg/cmd/errors"
+	"github.com/snyk/driftctl/pkg/cmd/scan/output"
+	"github.com/snyk/driftctl/pkg/resource"
+)
+
+func NewMergeCmd(opts *pkg.MergeOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge [ACCOUNT=]FILE...",
+		Short: "Merge several analysis JSON files into a single report",
+		Long: "Merge analyses produced by several scans (e.g. one per account or per provider) into a single report.\n" +
+			"Each file can be prefixed with the name of the scanned account, it is kept as the origin of every resource.\n" +
+			"Without prefix the account recorded by the scan is used, or the file name if the scan did not record one.\n\n" +
+			"Example: driftctl merge prod=prod.json staging=staging.json -o html://report.html",
+		Args: cobra.MinimumNArgs(1),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			outputFlag, _ := cmd.Flags().GetStringSlice("output")
+			out, err := parseOutputFlags(outputFlag)
+			if err != nil {
+				return err
+			}
+			opts.Output = out
+
+			opts.Inputs = make([]pkg.MergeInput, 0, len(args))
+			for _, arg := range args {
+				opts.Inputs = append(opts.Inputs, parseMergeInput(arg))
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return mergeRun(opts)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.StringSliceP(
+		"output",
+		"o",
+		[]string{output.Example(output.ConsoleOutputType)},
+		"Output format, by default it will write to the console\n"+
+			"Accepted formats are: "+strings.Join(output.SupportedOutputsExample(), ",")+"\n",
+	)
+
+	return cmd
+}
+
+func parseMergeInput(arg string) pkg.MergeInput {
+	parts := strings.SplitN(arg, "=", 2)
+	if len(parts) == 2 && parts[0] != "" {
+		return pkg.MergeInput{Account: parts[0], Path: parts[1]}
+	}
+	return pkg.MergeInput{Path: arg}
+}
+
+// mergeInputAccount returns the account resources of an analysis belong to. Without an explicit account we rely on
+// the account recorded by the scan, or on the file name so analyses of unknown accounts are never mixed up.
+func mergeInputAccount(input pkg.MergeInput, analysis *analyser.Analysis) string {
+	if input.Account != "" {
+		return input.Account
+	}
+	if analysis.Scan != nil && analysis.Scan.Account != "" {
+		return analysis.Scan.Account
+	}
+	return input.Path
+}
+
+func mergeRun(opts *pkg.MergeOptions) error {
+	analyses := make([]*analyser.Analysis, 0, len(opts.Inputs))
+	for _, input := range opts.Inputs {
+		content, err := ioutil.ReadFile(input.Path)
+		if err != nil {
+			return err
+		}
+
+		analysis := analyser.NewAnalysis(analyser.AnalyzerOptions{})
+		if err := json.Unmarshal(content, analysis); err != nil {
+			return errors.Wrapf(err, "unable to read analysis %s", input.Path)
+		}
+		analysis.SetOrigin(resource.Origin{
+			Provider: analysis.ProviderName,
+			Account:  mergeInputAccount(input, analysis),
+			File:     input.Path,
+		})
+		analyses = append(analyses, analysis)
+	}
+
+	analysis := analyser.Merge(analyses...)
+
+	validOutput := false
+	for _, o := range opts.Output {
+		if err := output.GetOutput(o).Write(analysis); err != nil {
+			logrus.Errorf("Error writing to output %s: %v", o.String(), err.Error())
+			continue
+		}
+		validOutput = true
+	}
+
+	// Fallback to console output if all output failed
+	if !validOutput {
+		logrus.Debug("All outputs failed, fallback to console output")
+		if err := output.NewConsole().Write(analysis); err != nil {
+			return err
+		}
+	}
+
+	if !analysis.IsSync() {
+		return cmderrors.InfrastructureNotInSync{}
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/snyk/driftctl/pkg"
+	"github.com/snyk/driftctl/pkg/analyser"
+	cmderrors "github.com/snyk/driftctl/pkg/cmd/errors"
+	"github.com/snyk/driftctl/pkg/cmd/scan/output"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_parseMergeInput(t *testing.T) {
+	assert.Equal(t, pkg.MergeInput{Path: "prod.json"}, parseMergeInput("prod.json"))
+	assert.Equal(t, pkg.MergeInput{Path: "prod.json", Account: "prod"}, parseMergeInput("prod=prod.json"))
+	assert.Equal(t, pkg.MergeInput{Path: "=prod.json"}, parseMergeInput("=prod.json"))
+}
+
+func Test_mergeInputAccount(t *testing.T) {
+	analysis := &analyser.Analysis{}
+	assert.Equal(t, "prod", mergeInputAccount(pkg.MergeInput{Path: "prod.json", Account: "prod"}, analysis))
+	assert.Equal(t, "prod.json", mergeInputAccount(pkg.MergeInput{Path: "prod.json"}, analysis))
+
+	analysis.Scan = &analyser.ScanMetadata{Account: "123456789012"}
+	assert.Equal(t, "prod", mergeInputAccount(pkg.MergeInput{Path: "prod.json", Account: "prod"}, analysis))
+	assert.Equal(t, "123456789012", mergeInputAccount(pkg.MergeInput{Path: "prod.json"}, analysis))
+}
+
+func Test_mergeRun(t *testing.T) {
+	outputPath := path.Join(t.TempDir(), "merged.json")
+	opts := &pkg.MergeOptions{
+		Inputs: []pkg.MergeInput{
+			{Path: "testdata/fmt/input_stdin_valid.json", Account: "prod"},
+			{Path: "testdata/fmt/input_stdin_valid.json", Account: "staging"},
+		},
+		Output: []output.OutputConfig{{Key: output.JSONOutputType, Path: outputPath}},
+	}
+
+	err := mergeRun(opts)
+	assert.IsType(t, cmderrors.InfrastructureNotInSync{}, err)
+
+	content, err := ioutil.ReadFile(outputPath)
+	require.Nil(t, err)
+
+	single := &analyser.Analysis{}
+	input, _ := ioutil.ReadFile("testdata/fmt/input_stdin_valid.json")
+	require.Nil(t, json.Unmarshal(input, single))
+
+	merged := &analyser.Analysis{}
+	require.Nil(t, json.Unmarshal(content, merged))
+	assert.Equal(t, 2*single.Summary().TotalResources, merged.Summary().TotalResources)
+	assert.Equal(t, single.Coverage(), merged.Coverage())
+	for _, res := range merged.Unmanaged() {
+		assert.Equal(t, "testdata/fmt/input_stdin_valid.json", res.Origin.File)
+		assert.Contains(t, []string{"prod", "staging"}, res.Origin.Account)
+	}
+}
+
+func Test_mergeRun_InvalidInput(t *testing.T) {
+	opts := &pkg.MergeOptions{
+		Inputs: []pkg.MergeInput{{Path: "testdata/fmt/input_stdin_invalid.json"}},
+		Output: []output.OutputConfig{{Key: output.ConsoleOutputType}},
+	}
+	err := mergeRun(opts)
+	assert.EqualError(t, err, "unable to read analysis testdata/fmt/input_stdin_invalid.json: invalid character 'i' looking for beginning of value")
+}
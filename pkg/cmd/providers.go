@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/snyk/driftctl/pkg"
+	globaloutput "github.com/snyk/driftctl/pkg/output"
+	"github.com/snyk/driftctl/pkg/remote"
+	"github.com/snyk/driftctl/pkg/remote/common"
+	"github.com/snyk/driftctl/pkg/terraform"
+)
+
+func NewProvidersCmd(opts *pkg.ProvidersOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "providers",
+		Short: "Manage the terraform providers downloaded by driftctl",
+		Long: "List, install and remove the terraform provider binaries driftctl keeps in its configuration directory.\n\n" +
+			"Example: driftctl providers install --to aws+tf --version 3.47.0",
+		Args: cobra.NoArgs,
+	}
+
+	configDir, err := homedir.Dir()
+	if err != nil {
+		configDir = os.TempDir()
+	}
+	cmd.PersistentFlags().StringVar(
+		&opts.ConfigDir,
+		"config-dir",
+		configDir,
+		"Directory path that driftctl uses for configuration.\n",
+	)
+
+	cmd.AddCommand(newProvidersListCmd(opts))
+	cmd.AddCommand(newProvidersInstallCmd(opts))
+	cmd.AddCommand(newProvidersPruneCmd(opts))
+
+	return cmd
+}
+
+func newProvidersListCmd(opts *pkg.ProvidersOptions) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List downloaded terraform providers and their size",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return providersListRun(opts, os.Stdout)
+		},
+	}
+}
+
+func newProvidersInstallCmd(opts *pkg.ProvidersOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install",
+		Short: "Download a terraform provider, e.g. to pre-bake it in a CI image",
+		Long: "Download the terraform provider used to scan a cloud provider.\n" +
+			"The version is read from the terraform lock file when the version flag is not set, " +
+			"or defaults to the version driftctl uses for scans.\n\n" +
+			"Example: driftctl providers install --to aws+tf --version 3.47.0",
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !remote.IsSupported(opts.To) {
+				return errors.Errorf(
+					"unsupported cloud provider '%s'\nValid values are: %s",
+					opts.To,
+					strings.Join(remote.GetSupportedRemotes(), ","),
+				)
+			}
+
+			opts.ProviderVersion, _ = cmd.Flags().GetString("version")
+			if err := validateTfProviderVersionString(opts.ProviderVersion); err != nil {
+				return err
+			}
+			if opts.ProviderVersion == "" {
+				opts.ProviderVersion, _ = getProviderVersion(cmd, opts.To)
+			}
+			if opts.ProviderVersion == "" {
+				opts.ProviderVersion = common.RemoteParameter(opts.To).GetDefaultProviderVersion()
+			}
+
+			opts.ProviderInstallOptions = getProviderInstallOptions(cmd, opts.To, opts.ProviderVersion)
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return providersInstallRun(opts, os.Stdout)
+		},
+	}
+
+	fl := cmd.Flags()
+	supportedRemotes := remote.GetSupportedRemotes()
+	fl.StringVarP(
+		&opts.To,
+		"to",
+		"t",
+		supportedRemotes[0],
+		"Cloud provider to install the terraform provider of\n"+
+			"Accepted values are: "+strings.Join(supportedRemotes, ",")+"\n",
+	)
+	fl.String(
+		"version",
+		"",
+		"Terraform provider version to install.\n",
+	)
+	fl.String(
+		"tf-lockfile",
+		".terraform.lock.hcl",
+		"Terraform lock file to get the provider's version and hashes from. Will be ignored if the file doesn't exist.\n",
+	)
+	fl.String(
+		"tf-provider-mirror-dir",
+		"",
+		"Terraform filesystem mirror to install the provider from, using either the packed or the unpacked layout\n"+
+			"The TF_PLUGIN_CACHE_DIR environment variable is also honoured\n",
+	)
+	fl.String(
+		"tf-provider-mirror-url",
+		"",
+		"Terraform network mirror to download the provider from instead of releases.hashicorp.com\n",
+	)
+	fl.String(
+		"tf-provider-registry",
+		"",
+		"Provider registry to download the provider from instead of releases.hashicorp.com, optionally followed by a namespace\n"+
+			"e.g. registry.opentofu.org or registry.example.com/acme. Also used to find the provider in the terraform lock file\n",
+	)
+
+	return cmd
+}
+
+func newProvidersPruneCmd(opts *pkg.ProvidersOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove terraform providers that are not used anymore",
+		Long: "Remove every downloaded terraform provider except the versions driftctl uses by default, " +
+			"the versions locked in the terraform lock file and the versions given with the keep flag.\n\n" +
+			"Example: driftctl providers prune --keep aws@3.47.0",
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			for _, keep := range opts.Keep {
+				if parts := strings.SplitN(keep, "@", 2); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+					return errors.Errorf("invalid provider version '%s', expected format is provider@version, e.g. aws@3.47.0", keep)
+				}
+			}
+
+			for _, to := range remote.GetSupportedRemotes() {
+				if provider := getLockedProvider(cmd, to); provider != nil {
+					opts.Keep = append(opts.Keep, fmt.Sprintf("%s@%s", common.RemoteParameter(to).GetProviderKey(), provider.Version))
+				}
+			}
+
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return providersPruneRun(opts, os.Stdout)
+		},
+	}
+
+	fl := cmd.Flags()
+	fl.StringSliceVar(
+		&opts.Keep,
+		"keep",
+		[]string{},
+		"Provider versions to keep, as provider@version (e.g. aws@3.47.0)\n",
+	)
+	fl.BoolVar(
+		&opts.DryRun,
+		"dry-run",
+		false,
+		"List the providers that would be removed without removing them\n",
+	)
+	fl.String(
+		"tf-lockfile",
+		".terraform.lock.hcl",
+		"Terraform lock file whose provider versions are kept. Will be ignored if the file doesn't exist.\n",
+	)
+	fl.String(
+		"tf-provider-registry",
+		"",
+		"Provider registry whose provider versions are kept when found in the terraform lock file, e.g. registry.opentofu.org\n",
+	)
+
+	return cmd
+}
+
+func providersListRun(opts *pkg.ProvidersOptions, out io.Writer) error {
+	providers, err := terraform.ListCachedProviders(opts.ConfigDir)
+	if err != nil {
+		return err
+	}
+	if len(providers) == 0 {
+		_, _ = fmt.Fprintf(out, "No provider found in %s\n", terraform.GetProvidersDirectory(opts.ConfigDir))
+		return nil
+	}
+
+	var total int64
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "PROVIDER\tVERSION\tSIZE\tPATH")
+	for _, provider := range providers {
+		total += provider.Size
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", provider.Key, provider.Version, formatSize(provider.Size), provider.Path)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "\n%d provider(s), %s\n", len(providers), formatSize(total))
+
+	return nil
+}
+
+func providersInstallRun(opts *pkg.ProvidersOptions, out io.Writer) error {
+	globaloutput.ChangePrinter(globaloutput.NewConsolePrinter())
+
+	installer, err := terraform.NewProviderInstaller(terraform.ProviderConfig{
+		Key:                    common.RemoteParameter(opts.To).GetProviderKey(),
+		Version:                opts.ProviderVersion,
+		ProviderInstallOptions: opts.ProviderInstallOptions,
+	})
+	if err != nil {
+		return err
+	}
+	providerPath, err := installer.Install()
+	if err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(out, "Installed %s@%s in %s\n", common.RemoteParameter(opts.To).GetProviderKey(), opts.ProviderVersion, providerPath)
+	return nil
+}
+
+func providersPruneRun(opts *pkg.ProvidersOptions, out io.Writer) error {
+	keep := make(map[string]struct{}, len(opts.Keep))
+	for _, to := range remote.GetSupportedRemotes() {
+		remoteParameter := common.RemoteParameter(to)
+		keep[fmt.Sprintf("%s@%s", remoteParameter.GetProviderKey(), remoteParameter.GetDefaultProviderVersion())] = struct{}{}
+	}
+	for _, k := range opts.Keep {
+		keep[k] = struct{}{}
+	}
+
+	providers, err := terraform.ListCachedProviders(opts.ConfigDir)
+	if err != nil {
+		return err
+	}
+
+	action := "Removed"
+	if opts.DryRun {
+		action = "Would remove"
+	}
+
+	removed := 0
+	var freed int64
+	for _, provider := range providers {
+		if _, exist := keep[fmt.Sprintf("%s@%s", provider.Key, provider.Version)]; exist {
+			logrus.WithField("path", provider.Path).Debug("Keeping provider")
+			continue
+		}
+		if !opts.DryRun {
+			if err := provider.Remove(); err != nil {
+				return errors.Wrapf(err, "unable to remove provider %s@%s", provider.Key, provider.Version)
+			}
+		}
+		removed++
+		freed += provider.Size
+		_, _ = fmt.Fprintf(out, "%s %s@%s (%s)\n", action, provider.Key, provider.Version, formatSize(provider.Size))
+	}
+
+	if removed == 0 {
+		_, _ = fmt.Fprintln(out, "No provider to remove")
+		return nil
+	}
+	_, _ = fmt.Fprintf(out, "\n%s %d provider(s), %s\n", action, removed, formatSize(freed))
+
+	return nil
+}
+
+// formatSize returns a human readable size, e.g. 180.3 MB
+func formatSize(size int64) string {
+	const unit = 1000
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/snyk/driftctl/pkg"
+	"github.com/snyk/driftctl/pkg/terraform"
+	"github.com/snyk/driftctl/test"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeCachedProviders(t *testing.T, configDir string, names ...string) string {
+	dir := terraform.GetProvidersDirectory(configDir)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(path.Join(dir, name), bytes.Repeat([]byte("a"), 1500), 0755))
+	}
+	return dir
+}
+
+func Test_providersListRun(t *testing.T) {
+	configDir := t.TempDir()
+	out := &bytes.Buffer{}
+	require.NoError(t, providersListRun(&pkg.ProvidersOptions{ConfigDir: configDir}, out))
+	assert.Equal(t, "No provider found in "+terraform.GetProvidersDirectory(configDir)+"\n", out.String())
+
+	dir := writeCachedProviders(t, configDir, "terraform-provider-aws_v3.19.0_x5", "terraform-provider-github_v4.4.0")
+	out.Reset()
+	require.NoError(t, providersListRun(&pkg.ProvidersOptions{ConfigDir: configDir}, out))
+	assert.Equal(t, "PROVIDER  VERSION  SIZE    PATH\n"+
+		"aws       3.19.0   1.5 kB  "+path.Join(dir, "terraform-provider-aws_v3.19.0_x5")+"\n"+
+		"github    4.4.0    1.5 kB  "+path.Join(dir, "terraform-provider-github_v4.4.0")+"\n"+
+		"\n2 provider(s), 3.0 kB\n", out.String())
+}
+
+func Test_providersPruneRun(t *testing.T) {
+	configDir := t.TempDir()
+	dir := writeCachedProviders(t, configDir,
+		"terraform-provider-aws_v3.19.0_x5",
+		"terraform-provider-aws_v3.40.0_x5",
+		"terraform-provider-aws_v3.47.0_x5",
+		"terraform-provider-google_v3.50.0_x5",
+	)
+
+	out := &bytes.Buffer{}
+	opts := &pkg.ProvidersOptions{ConfigDir: configDir, Keep: []string{"aws@3.47.0"}, DryRun: true}
+	require.NoError(t, providersPruneRun(opts, out))
+	assert.Equal(t, "Would remove aws@3.40.0 (1.5 kB)\nWould remove google@3.50.0 (1.5 kB)\n\nWould remove 2 provider(s), 3.0 kB\n", out.String())
+	providers, err := terraform.ListCachedProviders(configDir)
+	require.NoError(t, err)
+	assert.Len(t, providers, 4)
+
+	out.Reset()
+	opts.DryRun = false
+	require.NoError(t, providersPruneRun(opts, out))
+	assert.Equal(t, "Removed aws@3.40.0 (1.5 kB)\nRemoved google@3.50.0 (1.5 kB)\n\nRemoved 2 provider(s), 3.0 kB\n", out.String())
+	providers, err = terraform.ListCachedProviders(configDir)
+	require.NoError(t, err)
+	assert.Equal(t, []terraform.CachedProvider{
+		{Key: "aws", Version: "3.19.0", Path: path.Join(dir, "terraform-provider-aws_v3.19.0_x5"), Size: 1500},
+		{Key: "aws", Version: "3.47.0", Path: path.Join(dir, "terraform-provider-aws_v3.47.0_x5"), Size: 1500},
+	}, providers)
+
+	out.Reset()
+	require.NoError(t, providersPruneRun(opts, out))
+	assert.Equal(t, "No provider to remove\n", out.String())
+}
+
+func TestProvidersCmd_Options(t *testing.T) {
+	cases := []struct {
+		name          string
+		args          []string
+		expected      string
+		assertOptions func(*testing.T, *pkg.ProvidersOptions)
+	}{
+		{
+			name: "install should use the version flag",
+			args: []string{"providers", "install", "--to", "aws+tf", "--version", "3.41.0", "--tf-lockfile", "testdata/terraform_valid.lock.hcl"},
+			assertOptions: func(t *testing.T, opts *pkg.ProvidersOptions) {
+				assert.Equal(t, "3.41.0", opts.ProviderVersion)
+				assert.Empty(t, opts.ProviderInstallOptions.Hashes)
+			},
+		},
+		{
+			name: "install should use the version and hashes of the lockfile",
+			args: []string{"providers", "install", "--to", "aws+tf", "--tf-lockfile", "testdata/terraform_valid.lock.hcl"},
+			assertOptions: func(t *testing.T, opts *pkg.ProvidersOptions) {
+				assert.Equal(t, "3.47.0", opts.ProviderVersion)
+				assert.Len(t, opts.ProviderInstallOptions.Hashes, 12)
+			},
+		},
+		{
+			name: "install should default to the version used for scans",
+			args: []string{"providers", "install", "--to", "gcp+tf", "--tf-lockfile", "testdata/terraform_valid.lock.hcl", "--config-dir", "config"},
+			assertOptions: func(t *testing.T, opts *pkg.ProvidersOptions) {
+				assert.Equal(t, "3.78.0", opts.ProviderVersion)
+				assert.Equal(t, "config", opts.ProviderInstallOptions.ConfigDir)
+			},
+		},
+		{
+			name:     "install should fail with an unsupported remote",
+			args:     []string{"providers", "install", "--to", "foo"},
+			expected: "unsupported cloud provider 'foo'\nValid values are: aws+tf,github+tf,gcp+tf,azure+tf",
+		},
+		{
+			name:     "install should fail with an invalid version",
+			args:     []string{"providers", "install", "--version", "foo"},
+			expected: "Invalid version argument foo, expected a valid semver string (e.g. 2.13.4)",
+		},
+		{
+			name: "prune should keep versions of the lockfile",
+			args: []string{"providers", "prune", "--keep", "google@3.50.0", "--tf-lockfile", "testdata/terraform_valid.lock.hcl"},
+			assertOptions: func(t *testing.T, opts *pkg.ProvidersOptions) {
+				assert.Equal(t, []string{"google@3.50.0", "aws@3.47.0"}, opts.Keep)
+			},
+		},
+		{
+			name:     "prune should fail with an invalid keep flag",
+			args:     []string{"providers", "prune", "--keep", "3.47.0"},
+			expected: "invalid provider version '3.47.0', expected format is provider@version, e.g. aws@3.47.0",
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &pkg.ProvidersOptions{}
+
+			rootCmd := &cobra.Command{Use: "root"}
+			providersCmd := NewProvidersCmd(opts)
+			for _, c := range providersCmd.Commands() {
+				c.RunE = func(_ *cobra.Command, args []string) error { return nil }
+			}
+			rootCmd.AddCommand(providersCmd)
+
+			_, err := test.Execute(rootCmd, tt.args...)
+			if tt.expected != "" {
+				assert.EqualError(t, err, tt.expected)
+				return
+			}
+			assert.NoError(t, err)
+			tt.assertOptions(t, opts)
+		})
+	}
+}
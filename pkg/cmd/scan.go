@@ -24,13 +24,21 @@ import (
 	"github.com/snyk/driftctl/pkg"
 	"github.com/snyk/driftctl/pkg/alerter"
 	cmderrors "github.com/snyk/driftctl/pkg/cmd/errors"
+	"github.com/snyk/driftctl/pkg/cmd/scan"
 	"github.com/snyk/driftctl/pkg/cmd/scan/output"
 	"github.com/snyk/driftctl/pkg/filter"
 	"github.com/snyk/driftctl/pkg/iac/supplier"
 	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+	"github.com/snyk/driftctl/pkg/middlewares"
 	globaloutput "github.com/snyk/driftctl/pkg/output"
 	"github.com/snyk/driftctl/pkg/remote"
+	remoteaws "github.com/snyk/driftctl/pkg/remote/aws"
+	remoteazurerm "github.com/snyk/driftctl/pkg/remote/azurerm"
+	"github.com/snyk/driftctl/pkg/remote/recording"
+	"github.com/snyk/driftctl/pkg/remote/retry"
 	"github.com/snyk/driftctl/pkg/resource"
+	"github.com/snyk/driftctl/pkg/rules"
 	"github.com/snyk/driftctl/pkg/terraform"
 )
 
@@ -67,6 +75,10 @@ func NewScanCmd(opts *pkg.ScanOptions) *cobra.Command {
 			if err != nil {
 				return err
 			}
+			// azblob outputs are written with the same storage account and key as azurerm states
+			for i := range out {
+				out[i].AzureRMOptions = opts.BackendOptions.AzureRMBackendOptions
+			}
 			opts.Output = out
 
 			filterFlag, _ := cmd.Flags().GetStringArray("filter")
@@ -83,35 +95,64 @@ func NewScanCmd(opts *pkg.ScanOptions) *cobra.Command {
 				opts.Filter = expr
 			}
 
-			providerVersion, _ := cmd.Flags().GetString("tf-provider-version")
-			if err := validateTfProviderVersionString(providerVersion); err != nil {
+			opts.ProviderVersion, err = getProviderVersion(cmd, to)
+			if err != nil {
 				return err
 			}
-			opts.ProviderVersion = providerVersion
 
-			if opts.ProviderVersion == "" {
-				lockfilePath, _ := cmd.Flags().GetString("tf-lockfile")
-
-				// Attempt to read the provider version from a terraform lock file
-				lockFile, err := lock.ReadLocksFromFile(lockfilePath)
+			alertPolicyFlag, _ := cmd.Flags().GetStringSlice("alert-policy")
+			opts.AlertPolicy, err = alerter.ParsePolicy(alertPolicyFlag)
+			if err != nil {
+				return err
+			}
+			// Entries of the flag take precedence over the ones of the file
+			if alertPolicyFile, _ := cmd.Flags().GetString("alert-policy-file"); alertPolicyFile != "" {
+				filePolicy, err := alerter.ReadPolicy(alertPolicyFile)
 				if err != nil {
-					logrus.WithField("error", err.Error()).Debug("Error while parsing terraform lock file")
-				}
-				if provider := lockFile.GetProviderByAddress(common.RemoteParameter(to).GetProviderAddress()); provider != nil {
-					opts.ProviderVersion = provider.Version
-					logrus.WithFields(logrus.Fields{"version": opts.ProviderVersion, "provider": to}).Debug("Found provider version in terraform lock file")
+					return err
 				}
+				opts.AlertPolicy = filePolicy.Merge(opts.AlertPolicy)
+			}
+
+			if opts.RecordDir != "" && opts.ReplayDir != "" {
+				return errors.New("--record and --replay cannot be used together")
+			}
+
+			if err := validateHTTPBackendOptions(opts.BackendOptions.HTTPBackendOptions); err != nil {
+				return err
+			}
+
+			if err := opts.AWSEndpoints.Validate(); err != nil {
+				return err
 			}
 
 			opts.Quiet, _ = cmd.Flags().GetBool("quiet")
 			opts.DisableTelemetry, _ = cmd.Flags().GetBool("disable-telemetry")
 
-			opts.ConfigDir, _ = cmd.Flags().GetString("config-dir")
+			opts.ProviderInstallOptions = getProviderInstallOptions(cmd, to, opts.ProviderVersion)
 
 			if onlyManaged, _ := cmd.Flags().GetBool("only-managed"); onlyManaged {
 				opts.Deep = true
 			}
 
+			if opts.AzureResourceGraph && to != common.RemoteAzureTerraform {
+				return errors.Errorf("--azure-resource-graph can only be used with --to %s", common.RemoteAzureTerraform)
+			}
+
+			if len(opts.AWSConfigSnapshot.Sources) == 0 &&
+				(len(opts.AWSConfigSnapshot.Accounts) > 0 || len(opts.AWSConfigSnapshot.Regions) > 0) {
+				return errors.New("--aws-config-account and --aws-config-region can only be used with --aws-config-snapshot")
+			}
+
+			if len(opts.AWSConfigSnapshot.Sources) > 0 {
+				if to != common.RemoteAWSTerraform {
+					return errors.Errorf("--aws-config-snapshot can only be used with --to %s", common.RemoteAWSTerraform)
+				}
+				if opts.Deep {
+					return errors.New("--aws-config-snapshot can't be used with --deep or --only-managed, snapshots don't give resource details")
+				}
+			}
+
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -141,7 +182,9 @@ func NewScanCmd(opts *pkg.ScanOptions) *cobra.Command {
 		"o",
 		[]string{output.Example(output.ConsoleOutputType)},
 		"Output format, by default it will write to the console\n"+
-			"Accepted formats are: "+strings.Join(output.SupportedOutputsExample(), ",")+"\n",
+			"Accepted formats are: "+strings.Join(output.SupportedOutputsExample(), ",")+"\n"+
+			"Files can be written to s3://, gs:// or azblob:// destinations, {{date}} is replaced by the scan date\n"+
+			"Example: html://s3://my-bucket/drift/{{date}}/report.html\n",
 	)
 	fl.StringSliceP(
 		"from",
@@ -188,6 +231,61 @@ func NewScanCmd(opts *pkg.ScanOptions) *cobra.Command {
 		os.Getenv("AZURE_STORAGE_KEY"),
 		"Azure storage account key for state backend.\n",
 	)
+	fl.StringVar(&opts.BackendOptions.HTTPBackendOptions.ClientCertificate,
+		"http-client-cert",
+		"",
+		"PEM client certificate presented to the state server for mutual TLS.\n"+
+			"Only used with tfstate+https backend.\n",
+	)
+	fl.StringVar(&opts.BackendOptions.HTTPBackendOptions.ClientKey,
+		"http-client-key",
+		"",
+		"PEM private key of the client certificate.\n"+
+			"Only used with tfstate+https backend.\n",
+	)
+	fl.StringVar(&opts.BackendOptions.HTTPBackendOptions.CACertificate,
+		"http-ca-cert",
+		"",
+		"PEM bundle of certificate authorities to trust in addition to the system ones.\n"+
+			"Only used with tfstate+https backend.\n",
+	)
+	fl.StringVar(&opts.BackendOptions.HTTPBackendOptions.OAuth2TokenURL,
+		"http-oauth2-token-url",
+		"",
+		"OAuth2 token endpoint used to authenticate with the client credentials flow, tokens are refreshed when they expire.\n"+
+			"Only used with tfstate+http(s) backend.\n",
+	)
+	fl.StringVar(&opts.BackendOptions.HTTPBackendOptions.OAuth2ClientID,
+		"http-oauth2-client-id",
+		"",
+		"OAuth2 client ID.\n"+
+			"Only used with tfstate+http(s) backend.\n",
+	)
+	fl.StringVar(&opts.BackendOptions.HTTPBackendOptions.OAuth2ClientSecret,
+		"http-oauth2-client-secret",
+		"",
+		"OAuth2 client secret, prefer the DCTL_HTTP_OAUTH2_CLIENT_SECRET environment variable.\n"+
+			"Only used with tfstate+http(s) backend.\n",
+	)
+	fl.StringSliceVar(&opts.BackendOptions.HTTPBackendOptions.OAuth2Scopes,
+		"http-oauth2-scopes",
+		[]string{},
+		"OAuth2 scopes to request.\n"+
+			"Only used with tfstate+http(s) backend.\n",
+	)
+	markFlagsSensitive(fl, "headers", "tfc-token", "azurerm-account-key", "http-oauth2-client-secret")
+	fl.StringVar(&opts.BackendOptions.StateEncryptionOptions.Passphrase,
+		"tf-state-passphrase",
+		"",
+		"Passphrase of OpenTofu encrypted states using a pbkdf2 key provider, prefer the DCTL_TF_STATE_PASSPHRASE environment variable.\n",
+	)
+	markFlagsSensitive(fl, "tf-state-passphrase")
+	fl.StringVar(&opts.BackendOptions.StateEncryptionOptions.ConfigFile,
+		"tf-encryption-config",
+		"",
+		"OpenTofu encryption configuration holding the key providers of encrypted states, pbkdf2 and aws_kms are supported.\n"+
+			"Defaults to the TF_ENCRYPTION environment variable\n",
+	)
 	fl.String(
 		"tf-provider-version",
 		"",
@@ -231,6 +329,63 @@ func NewScanCmd(opts *pkg.ScanOptions) *cobra.Command {
 		configDir,
 		"Directory path that driftctl uses for configuration.\n",
 	)
+	fl.String(
+		"tf-provider-mirror-dir",
+		"",
+		"Terraform filesystem mirror to install the provider from, using either the packed or the unpacked layout\n"+
+			"The TF_PLUGIN_CACHE_DIR environment variable is also honoured\n",
+	)
+	fl.String(
+		"tf-provider-mirror-url",
+		"",
+		"Terraform network mirror to download the provider from instead of releases.hashicorp.com\n",
+	)
+	fl.String(
+		"tf-provider-registry",
+		"",
+		"Provider registry to download the provider from instead of releases.hashicorp.com, optionally followed by a namespace\n"+
+			"e.g. registry.opentofu.org or registry.example.com/acme. Also used to find the provider in the terraform lock file\n",
+	)
+	fl.StringVar(&opts.AWSEndpoints.URL,
+		"aws-endpoint-url",
+		os.Getenv("AWS_ENDPOINT_URL"),
+		"Endpoint of every AWS service, e.g. http://localhost:4566 to scan LocalStack\n"+
+			"Defaults to the AWS_ENDPOINT_URL environment variable\n",
+	)
+	fl.StringToStringVar(&opts.AWSEndpoints.Services,
+		"aws-endpoints",
+		map[string]string{},
+		"Endpoints of specific AWS services, named as in the endpoints block of the terraform AWS provider\n"+
+			"e.g. s3=http://localhost:4566,sts=http://localhost:4566\n",
+	)
+	fl.StringSliceVar(&opts.AWSConfigSnapshot.Sources,
+		"aws-config-snapshot",
+		[]string{},
+		"Read AWS resources from AWS Config snapshots or aggregator exports instead of scanning the account\n"+
+			"Accepts JSON files, optionally gzipped, directories, S3 objects or S3 prefixes\n"+
+			"In directories and S3 prefixes, configuration histories are skipped and only the latest snapshot of every account and region is read\n"+
+			"Only resource types recorded by AWS Config are compared, other IaC resources are ignored\n"+
+			"e.g. snapshot.json.gz,s3://config-bucket/AWSLogs/\n",
+	)
+	fl.StringSliceVar(&opts.AWSConfigSnapshot.Accounts,
+		"aws-config-account",
+		[]string{},
+		"Only read AWS Config snapshot resources of the given accounts, all accounts are read by default\n"+
+			"e.g. 123456789012,210987654321\n",
+	)
+	fl.StringSliceVar(&opts.AWSConfigSnapshot.Regions,
+		"aws-config-region",
+		[]string{},
+		"Only read AWS Config snapshot resources of the given regions, global resources (e.g. IAM) are always read\n"+
+			"e.g. us-east-1,eu-west-3\n",
+	)
+	fl.BoolVar(&opts.AzureResourceGraph,
+		"azure-resource-graph",
+		false,
+		fmt.Sprintf("%s List top level Azure resources with Resource Graph queries instead of listing every resource type\n", warn("EXPERIMENTAL:"))+
+			"Faster on large subscriptions, but resources you are not allowed to read are silently left out instead of raising an alert\n"+
+			"and the Resource Graph index may lag behind recent changes\n",
+	)
 	fl.BoolVar(&opts.OnlyManaged,
 		"only-managed",
 		false,
@@ -241,6 +396,54 @@ func NewScanCmd(opts *pkg.ScanOptions) *cobra.Command {
 		false,
 		"Report only what's not managed by your IaC\n",
 	)
+	fl.StringVar(&opts.RulesPath,
+		"rules",
+		"",
+		fmt.Sprintf("%s Path to a YAML file containing custom drift rules written as CEL expressions\n", warn("EXPERIMENTAL:"))+
+			"You should check the documentation for more details: https://docs.driftctl.com/rules\n",
+	)
+	fl.IntVar(&opts.RetryOptions.MaxAttempts,
+		"retry-max-attempts",
+		retry.DefaultMaxAttempts,
+		"Maximum number of attempts for a cloud provider request failing with a transient or throttling error\n",
+	)
+	fl.DurationVar(&opts.RetryOptions.MaxElapsedTime,
+		"retry-max-elapsed-time",
+		retry.DefaultMaxElapsedTime,
+		"Stop retrying a cloud provider request after this duration (e.g. 30s, 5m), 0 disables the limit\n",
+	)
+	fl.StringSlice(
+		"alert-policy",
+		[]string{},
+		"Action to take for each kind of alert, as KIND=ACTION where ACTION is one of ignore, warn or fail\n"+
+			"Use * as KIND to set the action of every other kind, alerts are reported as warnings by default\n"+
+			"Kinds: "+strings.Join(alerter.Kinds(), ", ")+"\n"+
+			fmt.Sprintf("When a failing alert is raised, driftctl exits with code %d, unless drifts were found: ", scan.EXIT_INCOMPLETE_SCAN)+
+			fmt.Sprintf("drifts take precedence and exit with code %d\n", scan.EXIT_NOT_IN_SYNC),
+	)
+	fl.String(
+		"alert-policy-file",
+		"",
+		"YAML file mapping alert kinds to actions under an alert_policy key, entries of --alert-policy take precedence\n",
+	)
+	fl.StringVar(&opts.MiddlewaresPath,
+		"middlewares",
+		"",
+		fmt.Sprintf("%s Path to a YAML file declaring custom middlewares to ignore default resources, reconcile IDs or expand embedded attributes\n", warn("EXPERIMENTAL:"))+
+			"You should check the documentation for more details: https://docs.driftctl.com/middlewares\n",
+	)
+	fl.StringVar(&opts.RecordDir,
+		"record",
+		"",
+		fmt.Sprintf("%s Save every cloud provider response to the given directory, secrets are redacted\n", warn("EXPERIMENTAL:"))+
+			"The directory can be used later with --replay to run the scan again without cloud credentials\n",
+	)
+	fl.StringVar(&opts.ReplayDir,
+		"replay",
+		"",
+		fmt.Sprintf("%s Run the scan against cloud provider responses saved with --record, without reaching the cloud provider\n", warn("EXPERIMENTAL:"))+
+			"IaC sources are still read from --from, changes of sensitive fields are not reported as they were redacted when recording\n",
+	)
 
 	return cmd
 }
@@ -269,7 +472,60 @@ func scanRun(opts *pkg.ScanOptions) error {
 
 	resFactory := terraform.NewTerraformResourceFactory(resourceSchemaRepository)
 
-	err := remote.Activate(opts.To, opts.ProviderVersion, alerter, providerLibrary, remoteLibrary, scanProgress, resourceSchemaRepository, resFactory, opts.ConfigDir)
+	var ruleSet *rules.RuleSet
+	if opts.RulesPath != "" {
+		var err error
+		ruleSet, err = rules.ReadFile(opts.RulesPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.MiddlewaresPath != "" {
+		var err error
+		opts.Middlewares, err = middlewares.ReadUserMiddlewares(opts.MiddlewaresPath, resFactory)
+		if err != nil {
+			return err
+		}
+	}
+
+	var recorder *recording.Recorder
+	var err error
+	if opts.RecordDir != "" {
+		recorder, err = recording.NewRecorder(opts.RecordDir)
+		if err != nil {
+			return err
+		}
+	}
+	if opts.ReplayDir != "" {
+		recorder, err = recording.NewReplayer(opts.ReplayDir)
+		if err != nil {
+			return err
+		}
+		// Responses are read from disk, retrying a request missing from the recording would not help
+		opts.RetryOptions.MaxAttempts = 1
+	}
+	opts.To, err = recorder.Value("to", opts.To)
+	if err != nil {
+		return err
+	}
+
+	retryPolicy := retry.NewPolicy(opts.RetryOptions)
+
+	err = remote.Activate(opts.To, opts.ProviderVersion, alerter, providerLibrary, remoteLibrary, scanProgress, resourceSchemaRepository, resFactory, remote.Options{
+		Options: common.Options{
+			ProviderInstallOptions: opts.ProviderInstallOptions,
+			RetryPolicy:            retryPolicy,
+			Recorder:               recorder,
+		},
+		AWS: remoteaws.Options{
+			Endpoints:      opts.AWSEndpoints,
+			ConfigSnapshot: opts.AWSConfigSnapshot,
+		},
+		Azure: remoteazurerm.Options{
+			ResourceGraph: opts.AzureResourceGraph,
+		},
+	})
 	if err != nil {
 		return err
 	}
@@ -284,9 +540,16 @@ func scanRun(opts *pkg.ScanOptions) error {
 	logrus.Debug("Checking for driftignore")
 	driftIgnore := filter.NewDriftIgnore(opts.DriftignorePath, opts.Driftignores...)
 
-	scanner := remote.NewScanner(remoteLibrary, alerter, remote.ScannerOptions{Deep: opts.Deep}, driftIgnore)
+	var scanFilter filter.Filter = driftIgnore
+	if len(opts.AWSConfigSnapshot.Sources) > 0 {
+		// Snapshots only hold some resource types, IaC resources of other types would all be reported as deleted
+		logrus.Warn("Only resource types read from AWS Config snapshots are compared, other resources of your IaC are ignored")
+		scanFilter = filter.NewTypeFilter(driftIgnore, remoteaws.ConfigSnapshotResourceTypes())
+	}
+
+	scanner := remote.NewScanner(remoteLibrary, alerter, remote.ScannerOptions{Deep: opts.Deep}, scanFilter)
 
-	iacSupplier, err := supplier.GetIACSupplier(opts.From, providerLibrary, opts.BackendOptions, iacProgress, alerter, resFactory, driftIgnore)
+	iacSupplier, err := supplier.GetIACSupplier(opts.From, providerLibrary, opts.BackendOptions, iacProgress, alerter, resFactory, scanFilter)
 	if err != nil {
 		return err
 	}
@@ -295,7 +558,12 @@ func scanRun(opts *pkg.ScanOptions) error {
 		scanner,
 		iacSupplier,
 		alerter,
-		analyser.NewAnalyzer(alerter, analyser.AnalyzerOptions{Deep: opts.Deep, OnlyManaged: opts.OnlyManaged, OnlyUnmanaged: opts.OnlyUnmanaged}, driftIgnore),
+		analyser.NewAnalyzer(alerter, analyser.AnalyzerOptions{
+			Deep:                   opts.Deep,
+			OnlyManaged:            opts.OnlyManaged,
+			OnlyUnmanaged:          opts.OnlyUnmanaged,
+			IgnoreSensitiveChanges: opts.ReplayDir != "",
+		}, scanFilter, ruleSet),
 		resFactory,
 		opts,
 		scanProgress,
@@ -317,8 +585,11 @@ func scanRun(opts *pkg.ScanOptions) error {
 
 	analysis.ProviderVersion = resourceSchemaRepository.ProviderVersion.String()
 	analysis.ProviderName = resourceSchemaRepository.ProviderName
+	analysis.Scan = newScanMetadata(opts, remoteLibrary.Identity(), driftIgnore)
 	store.Bucket(memstore.TelemetryBucket).Set("provider_name", analysis.ProviderName)
 
+	failedAlertKinds := analysis.ApplyAlertPolicy(opts.AlertPolicy)
+
 	validOutput := false
 	for _, o := range opts.Output {
 		if err = output.GetOutput(o).Write(analysis); err != nil {
@@ -338,6 +609,9 @@ func scanRun(opts *pkg.ScanOptions) error {
 
 	globaloutput.Printf(color.WhiteString("Scan duration: %s\n", analysis.Duration.Round(time.Second)))
 	globaloutput.Printf(color.WhiteString("Provider version used to scan: %s. Use --tf-provider-version to use another version.\n"), resourceSchemaRepository.ProviderVersion.String())
+	if retries := retryPolicy.Retries(); retries > 0 {
+		globaloutput.Printf(color.WhiteString("Requests retried after transient errors: %d\n", retries))
+	}
 
 	if !opts.DisableTelemetry {
 		tl := telemetry.NewTelemetry(&build.Build{})
@@ -345,12 +619,66 @@ func scanRun(opts *pkg.ScanOptions) error {
 	}
 
 	if !analysis.IsSync() {
-		return cmderrors.InfrastructureNotInSync{}
+		return cmderrors.InfrastructureNotInSync{IncompleteKinds: failedAlertKinds}
+	}
+
+	if len(failedAlertKinds) > 0 {
+		return cmderrors.IncompleteScan{Kinds: failedAlertKinds}
 	}
 
 	return nil
 }
 
+// getProviderVersion returns the version set with the tf-provider-version flag,
+// or attempt to read it from the terraform lock file when the flag is not set.
+func getProviderVersion(cmd *cobra.Command, to string) (string, error) {
+	providerVersion, _ := cmd.Flags().GetString("tf-provider-version")
+	if err := validateTfProviderVersionString(providerVersion); err != nil {
+		return "", err
+	}
+	if providerVersion != "" {
+		return providerVersion, nil
+	}
+
+	// Attempt to read the provider version from a terraform lock file
+	if provider := getLockedProvider(cmd, to); provider != nil {
+		logrus.WithFields(logrus.Fields{"version": provider.Version, "provider": to}).Debug("Found provider version in terraform lock file")
+		return provider.Version, nil
+	}
+
+	return "", nil
+}
+
+// getLockedProvider returns the provider found in the terraform lock file,
+// at the address of the registry set with the tf-provider-registry flag
+func getLockedProvider(cmd *cobra.Command, to string) *lock.ProviderBlock {
+	lockfilePath, _ := cmd.Flags().GetString("tf-lockfile")
+	registry, _ := cmd.Flags().GetString("tf-provider-registry")
+
+	lockFile, err := lock.ReadLocksFromFile(lockfilePath)
+	if err != nil {
+		logrus.WithField("error", err.Error()).Debug("Error while parsing terraform lock file")
+	}
+	return lockFile.GetProviderByAddress(common.RemoteParameter(to).GetProviderAddress(registry))
+}
+
+// getProviderInstallOptions reads the flags telling where to find the terraform provider,
+// along with the hashes of the given provider version found in the terraform lock file
+func getProviderInstallOptions(cmd *cobra.Command, to, version string) terraform.ProviderInstallOptions {
+	opts := terraform.ProviderInstallOptions{}
+	opts.ConfigDir, _ = cmd.Flags().GetString("config-dir")
+	opts.MirrorDir, _ = cmd.Flags().GetString("tf-provider-mirror-dir")
+	opts.MirrorURL, _ = cmd.Flags().GetString("tf-provider-mirror-url")
+	opts.Registry, _ = cmd.Flags().GetString("tf-provider-registry")
+
+	if provider := getLockedProvider(cmd, to); provider != nil && provider.Version == version {
+		logrus.WithFields(logrus.Fields{"version": provider.Version, "provider": to}).Debug("Provider will be verified against terraform lock file hashes")
+		opts.Hashes = provider.Hashes
+	}
+
+	return opts
+}
+
 func validateTfProviderVersionString(version string) error {
 	if version == "" {
 		return nil
@@ -360,3 +688,16 @@ func validateTfProviderVersionString(version string) error {
 	}
 	return nil
 }
+
+func validateHTTPBackendOptions(opts options.HTTPBackendOptions) error {
+	if (opts.ClientCertificate == "") != (opts.ClientKey == "") {
+		return errors.New("--http-client-cert and --http-client-key must be used together")
+	}
+	if opts.OAuth2TokenURL != "" && opts.OAuth2ClientID == "" {
+		return errors.New("--http-oauth2-client-id is required with --http-oauth2-token-url")
+	}
+	if opts.OAuth2TokenURL == "" && (opts.OAuth2ClientID != "" || opts.OAuth2ClientSecret != "") {
+		return errors.New("--http-oauth2-token-url is required to authenticate with OAuth2 client credentials")
+	}
+	return nil
+}
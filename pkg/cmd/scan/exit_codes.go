@@ -1,7 +1,8 @@
 package scan
 
 const (
-	EXIT_IN_SYNC     = 0
-	EXIT_NOT_IN_SYNC = 1
-	EXIT_ERROR       = 2
+	EXIT_IN_SYNC         = 0
+	EXIT_NOT_IN_SYNC     = 1
+	EXIT_ERROR           = 2
+	EXIT_INCOMPLETE_SCAN = 3
 )
@@ -1,12 +1,22 @@
 package output
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+)
 
 type OutputConfig struct {
-	Key  string
-	Path string
+	Key          string
+	Path         string
+	TemplatePath string
+	// AzureRMOptions are used to write azblob:// destinations, scans use the ones of the state backend
+	AzureRMOptions options.AzureRMBackendOptions
 }
 
 func (o *OutputConfig) String() string {
+	if o.TemplatePath != "" {
+		return fmt.Sprintf("%s://%s:%s", o.Key, o.TemplatePath, o.Path)
+	}
 	return fmt.Sprintf("%s://%s", o.Key, o.Path)
 }
@@ -0,0 +1,57 @@
+package output
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+)
+
+const (
+	s3DestinationPrefix     = "s3://"
+	gsDestinationPrefix     = "gs://"
+	azblobDestinationPrefix = "azblob://"
+
+	dateTemplate = "{{date}}"
+)
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// openDestination returns a writer for the given output path, which can be stdout, a local file,
+// or an object in a S3, Google Storage or Azure blob storage bucket (e.g. s3://bucket/drift/{{date}}/report.html).
+// Remote objects are only written once the writer is closed. Azure blobs are written with the given storage account
+// and key, or with the AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY environment variables when none is given.
+func openDestination(path string, date time.Time, azureRMOptions options.AzureRMBackendOptions) (io.WriteCloser, error) {
+	if isStdOut(path) {
+		return nopCloser{os.Stdout}, nil
+	}
+
+	if date.IsZero() {
+		date = time.Now()
+	}
+	path = strings.ReplaceAll(path, dateTemplate, date.Format("2006-01-02"))
+
+	switch {
+	case strings.HasPrefix(path, s3DestinationPrefix):
+		return backend.NewS3Writer(strings.TrimPrefix(path, s3DestinationPrefix))
+	case strings.HasPrefix(path, gsDestinationPrefix):
+		return backend.NewGSWriter(strings.TrimPrefix(path, gsDestinationPrefix))
+	case strings.HasPrefix(path, azblobDestinationPrefix):
+		if azureRMOptions == (options.AzureRMBackendOptions{}) {
+			azureRMOptions = options.AzureRMBackendOptions{
+				StorageAccount: os.Getenv("AZURE_STORAGE_ACCOUNT"),
+				StorageKey:     os.Getenv("AZURE_STORAGE_KEY"),
+			}
+		}
+		return backend.NewAzureRMWriter(strings.TrimPrefix(path, azblobDestinationPrefix), azureRMOptions)
+	default:
+		return os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0600)
+	}
+}
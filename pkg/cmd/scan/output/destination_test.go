@@ -0,0 +1,58 @@
+package output
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenDestination_DateTemplate(t *testing.T) {
+	dir := t.TempDir()
+	date := time.Date(2021, 06, 10, 0, 0, 0, 0, time.UTC)
+
+	file, err := openDestination(path.Join(dir, "report-{{date}}.txt"), date, options.AzureRMBackendOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = fmt.Fprint(file, "foobar")
+	assert.Nil(t, file.Close())
+
+	content, err := ioutil.ReadFile(path.Join(dir, "report-2021-06-10.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, "foobar", string(content))
+}
+
+func TestOpenDestination_InvalidRemotePath(t *testing.T) {
+	_, err := openDestination("gs://bucket", time.Time{}, options.AzureRMBackendOptions{})
+	assert.EqualError(t, err, "Unable to parse Google Storage path: bucket. Must be BUCKET_NAME/PATH/TO/OBJECT")
+}
+
+func TestOpenDestination_AzureBlobOptions(t *testing.T) {
+	t.Setenv("AZURE_STORAGE_ACCOUNT", "envaccount")
+	t.Setenv("AZURE_STORAGE_KEY", "ZW52a2V5")
+
+	file, err := openDestination("azblob://container/report.json", time.Time{}, options.AzureRMBackendOptions{})
+	assert.Nil(t, err)
+	assert.NotNil(t, file)
+
+	_, err = openDestination("azblob://container/report.json", time.Time{}, options.AzureRMBackendOptions{
+		StorageAccount: "flagaccount",
+		StorageKey:     "not a base64 key",
+	})
+	assert.EqualError(t, err, "decode account key: illegal base64 data at input byte 3")
+}
+
+func TestGetOutput_AzureRMOptions(t *testing.T) {
+	opts := options.AzureRMBackendOptions{StorageAccount: "account", StorageKey: "key"}
+
+	out := GetOutput(OutputConfig{Key: JSONOutputType, Path: "azblob://container/report.json", AzureRMOptions: opts})
+	assert.Equal(t, opts, out.(*JSON).azureRMOptions)
+
+	out = GetOutput(OutputConfig{Key: TemplateOutputType, Path: "azblob://container/report.txt", AzureRMOptions: opts})
+	assert.Equal(t, opts, out.(*Template).azureRMOptions)
+}
@@ -7,7 +7,6 @@ import (
 	"fmt"
 	"html/template"
 	"math"
-	"os"
 	"reflect"
 	"regexp"
 	"strconv"
@@ -18,6 +17,7 @@ import (
 	"github.com/r3labs/diff/v2"
 	"github.com/snyk/driftctl/pkg/alerter"
 	"github.com/snyk/driftctl/pkg/analyser"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
 	"github.com/snyk/driftctl/pkg/resource"
 )
 
@@ -29,7 +29,8 @@ const HTMLOutputExample = "html://PATH/TO/FILE.html"
 var assets embed.FS
 
 type HTML struct {
-	path string
+	path           string
+	azureRMOptions options.AzureRMBackendOptions
 }
 
 type HTMLTemplateParams struct {
@@ -45,25 +46,16 @@ type HTMLTemplateParams struct {
 	ScanDuration    string
 	ProviderName    string
 	ProviderVersion string
+	Scan            *analyser.ScanMetadata
 	LogoSvg         template.HTML
 	FaviconBase64   string
 }
 
 func NewHTML(path string) *HTML {
-	return &HTML{path}
+	return &HTML{path: path}
 }
 
 func (c *HTML) Write(analysis *analyser.Analysis) error {
-	file := os.Stdout
-	if !isStdOut(c.path) {
-		f, err := os.OpenFile(c.path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0600)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		file = f
-	}
-
 	tmplFile, err := assets.ReadFile("assets/index.tmpl")
 	if err != nil {
 		return err
@@ -84,68 +76,42 @@ func (c *HTML) Write(analysis *analyser.Analysis) error {
 		return err
 	}
 
-	funcMap := template.FuncMap{
-		"getResourceTypes": func() []string {
-			resources := make([]*resource.Resource, 0)
-			resources = append(resources, analysis.Unmanaged()...)
-			resources = append(resources, analysis.Deleted()...)
+	funcMap := template.FuncMap(analysisFuncMap(analysis))
+	funcMap["jsonDiff"] = func(ch analyser.Changelog) template.HTML {
+		var buf bytes.Buffer
 
-			for _, d := range analysis.Differences() {
-				resources = append(resources, d.Res)
-			}
-
-			return distinctResourceTypes(resources)
-		},
-		"getIaCSources": func() []string {
-			resources := make([]*resource.Resource, 0)
-			resources = append(resources, analysis.Deleted()...)
-			resources = append(resources, analysis.Managed()...)
-
-			return distinctIaCSources(resources)
-		},
-		"rate": func(count int) float64 {
-			if analysis.Summary().TotalResources == 0 {
-				return 0
-			}
-			rate := 100 * float64(count) / float64(analysis.Summary().TotalResources)
-			return math.Floor(rate*100) / 100
-		},
-		"jsonDiff": func(ch analyser.Changelog) template.HTML {
-			var buf bytes.Buffer
-
-			whiteSpace := "&emsp;"
-			for _, change := range ch {
-				for i, v := range change.Path {
-					if _, err := strconv.Atoi(v); err == nil {
-						change.Path[i] = fmt.Sprintf("[%s]", v)
-					}
+		whiteSpace := "&emsp;"
+		for _, change := range ch {
+			for i, v := range change.Path {
+				if _, err := strconv.Atoi(v); err == nil {
+					change.Path[i] = fmt.Sprintf("[%s]", v)
 				}
-				path := strings.Join(change.Path, ".")
-
-				switch change.Type {
-				case diff.CREATE:
-					pref := fmt.Sprintf("%s %s:", "+", path)
-					_, _ = fmt.Fprintf(&buf, "%s%s <span class=\"code-box-line-create\">%s</span>", whiteSpace, pref, prettify(change.To))
-				case diff.DELETE:
-					pref := fmt.Sprintf("%s %s:", "-", path)
-					_, _ = fmt.Fprintf(&buf, "%s%s <span class=\"code-box-line-delete\">%s</span>", whiteSpace, pref, prettify(change.From))
-				case diff.UPDATE:
-					prefix := fmt.Sprintf("%s %s:", "~", path)
-					if change.JsonString {
-						_, _ = fmt.Fprintf(&buf, "%s%s<br>%s%s<br>", whiteSpace, prefix, whiteSpace, jsonDiffHTML(change.From, change.To))
-						continue
-					}
-					_, _ = fmt.Fprintf(&buf, "%s%s <span class=\"code-box-line-delete\">%s</span> => <span class=\"code-box-line-create\">%s</span>", whiteSpace, prefix, htmlPrettify(change.From), htmlPrettify(change.To))
+			}
+			path := strings.Join(change.Path, ".")
+
+			switch change.Type {
+			case diff.CREATE:
+				pref := fmt.Sprintf("%s %s:", "+", path)
+				_, _ = fmt.Fprintf(&buf, "%s%s <span class=\"code-box-line-create\">%s</span>", whiteSpace, pref, prettify(change.To))
+			case diff.DELETE:
+				pref := fmt.Sprintf("%s %s:", "-", path)
+				_, _ = fmt.Fprintf(&buf, "%s%s <span class=\"code-box-line-delete\">%s</span>", whiteSpace, pref, prettify(change.From))
+			case diff.UPDATE:
+				prefix := fmt.Sprintf("%s %s:", "~", path)
+				if change.JsonString {
+					_, _ = fmt.Fprintf(&buf, "%s%s<br>%s%s<br>", whiteSpace, prefix, whiteSpace, jsonDiffHTML(change.From, change.To))
+					continue
 				}
+				_, _ = fmt.Fprintf(&buf, "%s%s <span class=\"code-box-line-delete\">%s</span> => <span class=\"code-box-line-create\">%s</span>", whiteSpace, prefix, htmlPrettify(change.From), htmlPrettify(change.To))
+			}
 
-				if change.Computed {
-					_, _ = fmt.Fprintf(&buf, " %s", "(computed)")
-				}
-				_, _ = fmt.Fprintf(&buf, "<br>")
+			if change.Computed {
+				_, _ = fmt.Fprintf(&buf, " %s", "(computed)")
 			}
+			_, _ = fmt.Fprintf(&buf, "<br>")
+		}
 
-			return template.HTML(buf.String())
-		},
+		return template.HTML(buf.String())
 	}
 
 	tmpl, err := template.New("main").Funcs(funcMap).Parse(string(tmplFile))
@@ -166,16 +132,58 @@ func (c *HTML) Write(analysis *analyser.Analysis) error {
 		ScanDuration:    analysis.Duration.Round(time.Second).String(),
 		ProviderName:    analysis.ProviderName,
 		ProviderVersion: analysis.ProviderVersion,
+		Scan:            analysis.Scan,
 		LogoSvg:         template.HTML(logoSvgFile),
 		FaviconBase64:   base64.StdEncoding.EncodeToString(faviconFile),
 	}
 
-	err = tmpl.Execute(file, data)
+	// Render in memory first, closing a remote destination uploads it, so a failed rendering must never reach it
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	file, err := openDestination(c.path, analysis.Date, c.azureRMOptions)
 	if err != nil {
 		return err
 	}
+	if _, err := buf.WriteTo(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// analysisFuncMap returns template helpers shared by every output rendering the analysis with a template
+func analysisFuncMap(analysis *analyser.Analysis) map[string]interface{} {
+	return map[string]interface{}{
+		"getResourceTypes": func() []string {
+			resources := make([]*resource.Resource, 0)
+			resources = append(resources, analysis.Unmanaged()...)
+			resources = append(resources, analysis.Deleted()...)
+
+			for _, d := range analysis.Differences() {
+				resources = append(resources, d.Res)
+			}
+
+			return distinctResourceTypes(resources)
+		},
+		"getIaCSources": func() []string {
+			resources := make([]*resource.Resource, 0)
+			resources = append(resources, analysis.Deleted()...)
+			resources = append(resources, analysis.Managed()...)
 
-	return nil
+			return distinctIaCSources(resources)
+		},
+		"rate": func(count int) float64 {
+			if analysis.Summary().TotalResources == 0 {
+				return 0
+			}
+			rate := 100 * float64(count) / float64(analysis.Summary().TotalResources)
+			return math.Floor(rate*100) / 100
+		},
+	}
 }
 
 func distinctResourceTypes(resources []*resource.Resource) []string {
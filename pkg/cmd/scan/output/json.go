@@ -2,39 +2,36 @@ package output
 
 import (
 	"encoding/json"
-	"os"
 
 	"github.com/snyk/driftctl/pkg/analyser"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
 )
 
 const JSONOutputType = "json"
 const JSONOutputExample = "json://PATH/TO/FILE.json"
 
 type JSON struct {
-	path string
+	path           string
+	azureRMOptions options.AzureRMBackendOptions
 }
 
 func NewJSON(path string) *JSON {
-	return &JSON{path}
+	return &JSON{path: path}
 }
 
 func (c *JSON) Write(analysis *analyser.Analysis) error {
-	file := os.Stdout
-	if !isStdOut(c.path) {
-		f, err := os.OpenFile(c.path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0600)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		file = f
+	json, err := json.MarshalIndent(analysis, "", "\t")
+	if err != nil {
+		return err
 	}
 
-	json, err := json.MarshalIndent(analysis, "", "\t")
+	file, err := openDestination(c.path, analysis.Date, c.azureRMOptions)
 	if err != nil {
 		return err
 	}
 	if _, err := file.Write(json); err != nil {
+		_ = file.Close()
 		return err
 	}
-	return nil
+	return file.Close()
 }
@@ -16,13 +16,15 @@ var supportedOutputTypes = []string{
 	JSONOutputType,
 	HTMLOutputType,
 	PlanOutputType,
+	TemplateOutputType,
 }
 
 var supportedOutputExample = map[string]string{
-	ConsoleOutputType: ConsoleOutputExample,
-	JSONOutputType:    JSONOutputExample,
-	HTMLOutputType:    HTMLOutputExample,
-	PlanOutputType:    PlanOutputExample,
+	ConsoleOutputType:  ConsoleOutputExample,
+	JSONOutputType:     JSONOutputExample,
+	HTMLOutputType:     HTMLOutputExample,
+	PlanOutputType:     PlanOutputExample,
+	TemplateOutputType: TemplateOutputExample,
 }
 
 func SupportedOutputsExample() []string {
@@ -50,11 +52,21 @@ func IsSupported(key string) bool {
 func GetOutput(config OutputConfig) Output {
 	switch config.Key {
 	case JSONOutputType:
-		return NewJSON(config.Path)
+		o := NewJSON(config.Path)
+		o.azureRMOptions = config.AzureRMOptions
+		return o
 	case HTMLOutputType:
-		return NewHTML(config.Path)
+		o := NewHTML(config.Path)
+		o.azureRMOptions = config.AzureRMOptions
+		return o
 	case PlanOutputType:
-		return NewPlan(config.Path)
+		o := NewPlan(config.Path)
+		o.azureRMOptions = config.AzureRMOptions
+		return o
+	case TemplateOutputType:
+		o := NewTemplate(config.TemplatePath, config.Path)
+		o.azureRMOptions = config.AzureRMOptions
+		return o
 	case ConsoleOutputType:
 		fallthrough
 	default:
@@ -83,6 +95,8 @@ func GetPrinter(config OutputConfig, quiet bool) output.Printer {
 		fallthrough
 	case PlanOutputType:
 		fallthrough
+	case TemplateOutputType:
+		fallthrough
 	case HTMLOutputType:
 		fallthrough
 	case ConsoleOutputType:
@@ -114,6 +114,16 @@ func fakeAnalysis(opts analyser.AnalyzerOptions) *analyser.Analysis {
 		}})
 	a.ProviderName = "AWS"
 	a.ProviderVersion = "3.19.0"
+	a.Scan = &analyser.ScanMetadata{
+		DriftctlVersion: "v0.40.0",
+		From:            []string{"tfstate://state.tfstate", "tfstate://delete_state.tfstate"},
+		To:              "aws+tf",
+		Account:         "123456789012",
+		Region:          "us-east-1",
+		CallerIdentity:  "arn:aws:iam::123456789012:user/driftctl",
+		GitCommit:       "0f1e2d3c4b5a69788796a5b4c3d2e1f00f1e2d3c",
+		DriftignoreHash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
 	return a
 }
 
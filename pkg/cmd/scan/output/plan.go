@@ -3,9 +3,9 @@ package output
 import (
 	"encoding/json"
 	"fmt"
-	"os"
 
 	"github.com/snyk/driftctl/pkg/analyser"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
 	"github.com/snyk/driftctl/pkg/resource"
 )
 
@@ -48,23 +48,15 @@ type rsc struct {
 }
 
 type Plan struct {
-	path string
+	path           string
+	azureRMOptions options.AzureRMBackendOptions
 }
 
 func NewPlan(path string) *Plan {
-	return &Plan{path}
+	return &Plan{path: path}
 }
 
 func (c *Plan) Write(analysis *analyser.Analysis) error {
-	file := os.Stdout
-	if !isStdOut(c.path) {
-		f, err := os.OpenFile(c.path, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0600)
-		if err != nil {
-			return err
-		}
-		defer f.Close()
-		file = f
-	}
 	output := plan{FormatVersion: FormatVersion}
 	output.PlannedValues.RootModule = addPlannedValues(analysis)
 	output.ResourceChanges = addResourceChanges(analysis)
@@ -72,10 +64,16 @@ func (c *Plan) Write(analysis *analyser.Analysis) error {
 	if err != nil {
 		return err
 	}
+
+	file, err := openDestination(c.path, analysis.Date, c.azureRMOptions)
+	if err != nil {
+		return err
+	}
 	if _, err := file.Write(jsonPlan); err != nil {
+		_ = file.Close()
 		return err
 	}
-	return nil
+	return file.Close()
 }
 
 func addPlannedValues(analysis *analyser.Analysis) module {
@@ -0,0 +1,176 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/r3labs/diff/v2"
+	"github.com/snyk/driftctl/pkg/alerter"
+	"github.com/snyk/driftctl/pkg/analyser"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+	"github.com/snyk/driftctl/pkg/resource"
+)
+
+const TemplateOutputType = "template"
+const TemplateOutputExample = "template://PATH/TO/TEMPLATE.tmpl:PATH/TO/FILE"
+
+// Template renders the analysis using a user provided text/template file
+type Template struct {
+	templatePath   string
+	path           string
+	azureRMOptions options.AzureRMBackendOptions
+}
+
+type TemplateParams struct {
+	IsSync          bool
+	ScanDate        time.Time
+	ScanDuration    time.Duration
+	Coverage        int
+	Summary         analyser.Summary
+	Managed         []*resource.Resource
+	Unmanaged       []*resource.Resource
+	Differences     []analyser.Difference
+	Deleted         []*resource.Resource
+	Alerts          alerter.Alerts
+	ProviderName    string
+	ProviderVersion string
+	Scan            *analyser.ScanMetadata
+}
+
+func NewTemplate(templatePath, path string) *Template {
+	return &Template{templatePath: templatePath, path: path}
+}
+
+func (c *Template) Write(analysis *analyser.Analysis) error {
+	tmplFile, err := ioutil.ReadFile(c.templatePath)
+	if err != nil {
+		return err
+	}
+
+	funcMap := template.FuncMap(analysisFuncMap(analysis))
+	for name, fn := range templateHelpers() {
+		funcMap[name] = fn
+	}
+	funcMap["jsonDiff"] = func(ch analyser.Changelog) string {
+		var buf bytes.Buffer
+		for _, change := range ch {
+			path := changePath(change.Path)
+			switch change.Type {
+			case diff.CREATE:
+				_, _ = fmt.Fprintf(&buf, "+ %s: %s", path, prettify(change.To))
+			case diff.DELETE:
+				_, _ = fmt.Fprintf(&buf, "- %s: %s", path, prettify(change.From))
+			case diff.UPDATE:
+				if change.JsonString {
+					_, _ = fmt.Fprintf(&buf, "~ %s:\n%s", path, jsonDiff(change.From, change.To, false))
+					continue
+				}
+				_, _ = fmt.Fprintf(&buf, "~ %s: %s => %s", path, prettify(change.From), prettify(change.To))
+			}
+			if change.Computed {
+				_, _ = fmt.Fprint(&buf, " (computed)")
+			}
+			_, _ = fmt.Fprintln(&buf)
+		}
+		return buf.String()
+	}
+
+	tmpl, err := template.New("main").Funcs(funcMap).Parse(string(tmplFile))
+	if err != nil {
+		return err
+	}
+
+	data := &TemplateParams{
+		IsSync:          analysis.IsSync(),
+		ScanDate:        analysis.Date,
+		ScanDuration:    analysis.Duration.Round(time.Second),
+		Coverage:        analysis.Coverage(),
+		Summary:         analysis.Summary(),
+		Managed:         analysis.Managed(),
+		Unmanaged:       analysis.Unmanaged(),
+		Differences:     analysis.Differences(),
+		Deleted:         analysis.Deleted(),
+		Alerts:          analysis.Alerts(),
+		ProviderName:    analysis.ProviderName,
+		ProviderVersion: analysis.ProviderVersion,
+		Scan:            analysis.Scan,
+	}
+
+	// Render in memory first so we do not leave a truncated file behind on template errors
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return err
+	}
+
+	file, err := openDestination(c.path, analysis.Date, c.azureRMOptions)
+	if err != nil {
+		return err
+	}
+	if _, err := buf.WriteTo(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func templateHelpers() map[string]interface{} {
+	return map[string]interface{}{
+		"lower":     strings.ToLower,
+		"upper":     strings.ToUpper,
+		"trim":      strings.TrimSpace,
+		"replace":   strings.ReplaceAll,
+		"contains":  strings.Contains,
+		"hasPrefix": strings.HasPrefix,
+		"hasSuffix": strings.HasSuffix,
+		"split":     strings.Split,
+		"join": func(sep string, elems []string) string {
+			return strings.Join(elems, sep)
+		},
+		"changePath": changePath,
+		"prettify":   prettify,
+		"toJson": func(v interface{}) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"toPrettyJson": func(v interface{}) (string, error) {
+			b, err := json.MarshalIndent(v, "", "  ")
+			return string(b), err
+		},
+		"csv": func(fields ...interface{}) (string, error) {
+			record := make([]string, 0, len(fields))
+			for _, f := range fields {
+				record = append(record, fmt.Sprint(f))
+			}
+			var buf bytes.Buffer
+			w := csv.NewWriter(&buf)
+			if err := w.Write(record); err != nil {
+				return "", err
+			}
+			w.Flush()
+			return strings.TrimSuffix(buf.String(), "\n"), w.Error()
+		},
+	}
+}
+
+// changePath joins a change path with dots, using brackets for list indexes (e.g. tags[0].name)
+func changePath(path []string) string {
+	var b strings.Builder
+	for i, v := range path {
+		if _, err := strconv.Atoi(v); err == nil {
+			_, _ = fmt.Fprintf(&b, "[%s]", v)
+			continue
+		}
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(v)
+	}
+	return b.String()
+}
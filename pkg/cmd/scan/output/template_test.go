@@ -0,0 +1,85 @@
+package output
+
+import (
+	"io/ioutil"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/snyk/driftctl/pkg/analyser"
+	"github.com/snyk/driftctl/test/goldenfile"
+)
+
+func TestTemplate_Write(t *testing.T) {
+	tests := []struct {
+		name       string
+		template   string
+		goldenfile string
+		analysis   func() *analyser.Analysis
+		err        string
+	}{
+		{
+			name:       "test markdown template output",
+			template:   "template.md.tmpl",
+			goldenfile: "output_template.md",
+			analysis: func() *analyser.Analysis {
+				a := fakeAnalysis(analyser.AnalyzerOptions{Deep: true})
+				a.ProviderName = "AWS"
+				a.ProviderVersion = "3.19.0"
+				return a
+			},
+		},
+		{
+			name:     "test missing template",
+			template: "not_found.tmpl",
+			analysis: func() *analyser.Analysis {
+				return &analyser.Analysis{}
+			},
+			err: "open testdata/not_found.tmpl: no such file or directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			tempFile, err := ioutil.TempFile(tempDir, "result")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			c := NewTemplate(path.Join("./testdata/", tt.template), tempFile.Name())
+
+			err = c.Write(tt.analysis())
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+
+			got, err := ioutil.ReadFile(tempFile.Name())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedFilePath := path.Join("./testdata/", tt.goldenfile)
+			if *goldenfile.Update == tt.goldenfile {
+				if err := ioutil.WriteFile(expectedFilePath, got, 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			expected, err := ioutil.ReadFile(expectedFilePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, string(expected), string(got))
+		})
+	}
+}
+
+func TestTemplate_ChangePath(t *testing.T) {
+	assert.Equal(t, "tags[0].name", changePath([]string{"tags", "0", "name"}))
+	assert.Equal(t, "policy", changePath([]string{"policy"}))
+	assert.Equal(t, "", changePath(nil))
+}
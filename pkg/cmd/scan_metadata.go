@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"net/url"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg"
+	"github.com/snyk/driftctl/pkg/analyser"
+	"github.com/snyk/driftctl/pkg/filter"
+	"github.com/snyk/driftctl/pkg/remote/common"
+	"github.com/snyk/driftctl/pkg/version"
+)
+
+// newScanMetadata records what was scanned so a report can be traced back to the scanned account and IaC sources
+func newScanMetadata(opts *pkg.ScanOptions, identity common.Identity, driftIgnore *filter.DriftIgnore) *analyser.ScanMetadata {
+	from := make([]string, 0, len(opts.From))
+	for _, source := range opts.From {
+		from = append(from, redactSource(source.String()))
+	}
+	return &analyser.ScanMetadata{
+		DriftctlVersion: version.Current(),
+		From:            from,
+		To:              opts.To,
+		Account:         identity.Account,
+		Region:          identity.Region,
+		CallerIdentity:  identity.Caller,
+		GitCommit:       gitCommit("."),
+		DriftignoreHash: driftIgnore.Hash(),
+	}
+}
+
+// redactSource hides the password of sources given with basic auth credentials, e.g. an HTTP backend
+func redactSource(source string) string {
+	u, err := url.Parse(source)
+	if err != nil || u.User == nil {
+		return source
+	}
+	return u.Redacted()
+}
+
+// gitCommit returns the commit checked out in the git repository holding dir, if any
+func gitCommit(dir string) string {
+	repository, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return ""
+	}
+	head, err := repository.Head()
+	if err != nil {
+		logrus.WithField("error", err).Debug("Unable to read the git commit of the working directory")
+		return ""
+	}
+	return head.Hash().String()
+}
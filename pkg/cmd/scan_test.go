@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/snyk/driftctl/pkg"
+	"github.com/snyk/driftctl/pkg/iac/config"
 	"github.com/snyk/driftctl/test"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -48,6 +49,12 @@ func TestScanCmd_Valid(t *testing.T) {
 		{args: []string{"scan", "--tf-lockfile", "../.terraform.lock.hcl"}},
 		{args: []string{"scan", "--only-managed"}},
 		{args: []string{"scan", "--only-unmanaged"}},
+		{args: []string{"scan", "--alert-policy", "remote_access_denied=fail,*=ignore"}},
+		{args: []string{"scan", "--http-client-cert", "client.crt", "--http-client-key", "client.key", "--http-ca-cert", "ca.crt"}},
+		{args: []string{"scan", "--http-oauth2-token-url", "https://auth.example.com/token", "--http-oauth2-client-id", "driftctl", "--http-oauth2-scopes", "states:read"}},
+		{args: []string{"scan", "--record", "record"}},
+		{args: []string{"scan", "--aws-endpoint-url", "http://localhost:4566", "--aws-endpoints", "s3=http://localhost:4572"}},
+		{args: []string{"scan", "--to", "azure+tf", "--azure-resource-graph"}},
 	}
 
 	for _, tt := range cases {
@@ -61,6 +68,44 @@ func TestScanCmd_Valid(t *testing.T) {
 	}
 }
 
+func TestScanCmd_AWSConfigSnapshot(t *testing.T) {
+	opts := &pkg.ScanOptions{}
+	rootCmd := &cobra.Command{Use: "root"}
+	scanCmd := NewScanCmd(opts)
+	scanCmd.RunE = func(_ *cobra.Command, args []string) error { return nil }
+	rootCmd.AddCommand(scanCmd)
+
+	output, err := test.Execute(rootCmd, "scan",
+		"--aws-config-snapshot", "snapshot.json.gz,s3://config-bucket/AWSLogs/",
+		"--aws-config-account", "123456789012",
+		"--aws-config-region", "us-east-1,eu-west-3",
+	)
+	assert.Empty(t, output)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"snapshot.json.gz", "s3://config-bucket/AWSLogs/"}, opts.AWSConfigSnapshot.Sources)
+	assert.Equal(t, []string{"123456789012"}, opts.AWSConfigSnapshot.Accounts)
+	assert.Equal(t, []string{"us-east-1", "eu-west-3"}, opts.AWSConfigSnapshot.Regions)
+}
+
+func TestScanCmd_S3StateEndpoint(t *testing.T) {
+	opts := &pkg.ScanOptions{}
+	rootCmd := &cobra.Command{Use: "root"}
+	scanCmd := NewScanCmd(opts)
+	scanCmd.RunE = func(_ *cobra.Command, args []string) error { return nil }
+	rootCmd.AddCommand(scanCmd)
+
+	output, err := test.Execute(rootCmd, "scan", "--from", "tfstate+s3://bucket/key?endpoint=https://minio.example.com&force_path_style=true")
+	assert.Empty(t, output)
+	assert.NoError(t, err)
+	assert.Equal(t, []config.SupplierConfig{
+		{
+			Key:     "tfstate",
+			Backend: "s3",
+			Path:    "bucket/key?endpoint=https://minio.example.com&force_path_style=true",
+		},
+	}, opts.From)
+}
+
 func TestScanCmd_Invalid(t *testing.T) {
 	cases := []struct {
 		args     []string
@@ -76,11 +121,11 @@ func TestScanCmd_Invalid(t *testing.T) {
 		{args: []string{"scan", "-f"}, expected: `flag needs an argument: 'f' in -f`},
 		{args: []string{"scan", "--from"}, expected: `flag needs an argument: --from`},
 		{args: []string{"scan", "--from"}, expected: `flag needs an argument: --from`},
-		{args: []string{"scan", "--from", "tosdgjhgsdhgkjs"}, expected: "Unable to parse from flag 'tosdgjhgsdhgkjs': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://"},
-		{args: []string{"scan", "--from", "://"}, expected: "Unable to parse from flag '://': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://"},
-		{args: []string{"scan", "--from", "://test"}, expected: "Unable to parse from flag '://test': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://"},
-		{args: []string{"scan", "--from", "tosdgjhgsdhgkjs://"}, expected: "Unable to parse from flag 'tosdgjhgsdhgkjs://': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://"},
-		{args: []string{"scan", "--from", "terraform+foo+bar://test"}, expected: "Unable to parse from scheme 'terraform+foo+bar': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://"},
+		{args: []string{"scan", "--from", "tosdgjhgsdhgkjs"}, expected: "Unable to parse from flag 'tosdgjhgsdhgkjs': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://,tfdir://"},
+		{args: []string{"scan", "--from", "://"}, expected: "Unable to parse from flag '://': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://,tfdir://"},
+		{args: []string{"scan", "--from", "://test"}, expected: "Unable to parse from flag '://test': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://,tfdir://"},
+		{args: []string{"scan", "--from", "tosdgjhgsdhgkjs://"}, expected: "Unable to parse from flag 'tosdgjhgsdhgkjs://': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://,tfdir://"},
+		{args: []string{"scan", "--from", "terraform+foo+bar://test"}, expected: "Unable to parse from scheme 'terraform+foo+bar': \nAccepted schemes are: tfstate://,tfstate+s3://,tfstate+http://,tfstate+https://,tfstate+tfcloud://,tfstate+gs://,tfstate+azurerm://,tfdir://"},
 		{args: []string{"scan", "--from", "unsupported://test"}, expected: "Unsupported IaC source 'unsupported': \nAccepted values are: tfstate"},
 		{args: []string{"scan", "--from", "tfstate+foobar://test"}, expected: "Unsupported IaC backend 'foobar': \nAccepted values are: s3,http,https,tfcloud,gs,azurerm"},
 		{args: []string{"scan", "--from", "tfstate:///tmp/test", "--from", "tfstate+toto://test"}, expected: "Unsupported IaC backend 'toto': \nAccepted values are: s3,http,https,tfcloud,gs,azurerm"},
@@ -90,6 +135,22 @@ func TestScanCmd_Invalid(t *testing.T) {
 		{args: []string{"scan", "--tf-provider-version", "foo"}, expected: "Invalid version argument foo, expected a valid semver string (e.g. 2.13.4)"},
 		{args: []string{"scan", "--driftignore"}, expected: "flag needs an argument: --driftignore"},
 		{args: []string{"scan", "--tf-lockfile"}, expected: "flag needs an argument: --tf-lockfile"},
+		{args: []string{"scan", "--alert-policy", "remote_access_denied"}, expected: "unable to parse alert policy 'remote_access_denied', expected KIND=ACTION"},
+		{args: []string{"scan", "--alert-policy", "remote_access_denied=panic"}, expected: "invalid action 'panic' for alert kind 'remote_access_denied', valid actions are: ignore, warn, fail"},
+		{args: []string{"scan", "--alert-policy", "remote_acess_denied=fail"}, expected: "unknown alert kind 'remote_acess_denied', valid kinds are: computed_diff, remote_access_denied, state_reading, unmanaged_security_group_rules, unsupported_state_version, wrong_arn_topic, *"},
+		{args: []string{"scan", "--alert-policy-file", "missing.yml"}, expected: "unable to read alert policy file missing.yml: open missing.yml: no such file or directory"},
+		{args: []string{"scan", "--record", "foo", "--replay", "bar"}, expected: "--record and --replay cannot be used together"},
+		{args: []string{"scan", "--http-client-cert", "client.crt"}, expected: "--http-client-cert and --http-client-key must be used together"},
+		{args: []string{"scan", "--http-oauth2-token-url", "https://auth.example.com/token"}, expected: "--http-oauth2-client-id is required with --http-oauth2-token-url"},
+		{args: []string{"scan", "--http-oauth2-client-id", "driftctl"}, expected: "--http-oauth2-token-url is required to authenticate with OAuth2 client credentials"},
+		{args: []string{"scan", "--aws-endpoint-url", "localhost:4566"}, expected: "invalid AWS endpoint: localhost:4566 is not a URL, e.g. http://localhost:4566"},
+		{args: []string{"scan", "--aws-endpoints", "s3=/tmp"}, expected: "invalid AWS endpoint for s3: /tmp is not a URL, e.g. http://localhost:4566"},
+		{args: []string{"scan", "--aws-endpoints", "elb2=http://localhost:4566"}, expected: "unknown AWS service 'elb2' in endpoints, valid services are: apigateway, apigatewayv2, applicationautoscaling, autoscaling, cloudformation, cloudfront, dynamodb, ec2, ecr, elasticache, elb, elbv2, iam, kms, lambda, rds, route53, s3, sns, sqs, sts"},
+		{args: []string{"scan", "--aws-config-snapshot", "snapshot.json", "--to", "gcp+tf"}, expected: "--aws-config-snapshot can only be used with --to aws+tf"},
+		{args: []string{"scan", "--aws-config-snapshot", "snapshot.json", "--only-managed"}, expected: "--aws-config-snapshot can't be used with --deep or --only-managed, snapshots don't give resource details"},
+		{args: []string{"scan", "--aws-config-account", "123456789012"}, expected: "--aws-config-account and --aws-config-region can only be used with --aws-config-snapshot"},
+		{args: []string{"scan", "--aws-config-region", "us-east-1"}, expected: "--aws-config-account and --aws-config-region can only be used with --aws-config-snapshot"},
+		{args: []string{"scan", "--azure-resource-graph"}, expected: "--azure-resource-graph can only be used with --to azure+tf"},
 	}
 
 	for _, tt := range cases {
@@ -125,6 +186,39 @@ func Test_Options(t *testing.T) {
 				assert.Equal(t, "3.47.0", opts.ProviderVersion)
 			},
 		},
+		{
+			name: "should verify provider against lockfile hashes",
+			args: []string{"scan", "--to", "aws+tf", "--tf-lockfile", "testdata/terraform_valid.lock.hcl", "--tf-provider-mirror-dir", "mirror"},
+			assertOptions: func(t *testing.T, opts *pkg.ScanOptions) {
+				assert.Equal(t, "mirror", opts.ProviderInstallOptions.MirrorDir)
+				assert.Len(t, opts.ProviderInstallOptions.Hashes, 12)
+				assert.Equal(t, "h1:gXncRh1KtgLNMeb3/bYq5CvGfy8YTR+n6ds1noc5ggc=", opts.ProviderInstallOptions.Hashes[0])
+			},
+		},
+		{
+			name: "should get provider version from lockfile of another registry",
+			args: []string{"scan", "--to", "aws+tf", "--tf-lockfile", "testdata/terraform_opentofu.lock.hcl", "--tf-provider-registry", "registry.opentofu.org"},
+			assertOptions: func(t *testing.T, opts *pkg.ScanOptions) {
+				assert.Equal(t, "3.76.1", opts.ProviderVersion)
+				assert.Equal(t, "registry.opentofu.org", opts.ProviderInstallOptions.Registry)
+				assert.Len(t, opts.ProviderInstallOptions.Hashes, 2)
+			},
+		},
+		{
+			name: "should not find provider version in lockfile of another registry",
+			args: []string{"scan", "--to", "aws+tf", "--tf-lockfile", "testdata/terraform_opentofu.lock.hcl"},
+			assertOptions: func(t *testing.T, opts *pkg.ScanOptions) {
+				assert.Equal(t, "", opts.ProviderVersion)
+			},
+		},
+		{
+			name: "should not use lockfile hashes for another provider version",
+			args: []string{"scan", "--to", "aws+tf", "--tf-lockfile", "testdata/terraform_valid.lock.hcl", "--tf-provider-version", "3.41.0", "--tf-provider-mirror-url", "https://mirror.example.com"},
+			assertOptions: func(t *testing.T, opts *pkg.ScanOptions) {
+				assert.Equal(t, "https://mirror.example.com", opts.ProviderInstallOptions.MirrorURL)
+				assert.Empty(t, opts.ProviderInstallOptions.Hashes)
+			},
+		},
 		{
 			name: "should not find provider version in lockfile",
 			args: []string{"scan", "--to", "gcp+tf", "--tf-lockfile", "testdata/terraform_valid.lock.hcl"},
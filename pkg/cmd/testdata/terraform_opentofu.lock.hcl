@@ -0,0 +1,8 @@
+provider "registry.opentofu.org/hashicorp/aws" {
+  version     = "3.76.1"
+  constraints = "~> 3.76"
+  hashes = [
+    "h1:Zf1zwyU9wUBHrHLtUOFXcKpw7Tq7Xo4U9+yVIkVKRXI=",
+    "zh:1cf933104a641ffdb64d71a76806f4df35d19101b47e0eb02c9c36bd64bfdd2d",
+  ]
+}
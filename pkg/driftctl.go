@@ -16,11 +16,48 @@ import (
 	"github.com/snyk/driftctl/pkg/iac/config"
 	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend"
 	"github.com/snyk/driftctl/pkg/middlewares"
+	awsclient "github.com/snyk/driftctl/pkg/remote/aws/client"
+	awsrepository "github.com/snyk/driftctl/pkg/remote/aws/repository"
+	"github.com/snyk/driftctl/pkg/remote/retry"
 	"github.com/snyk/driftctl/pkg/resource"
+	"github.com/snyk/driftctl/pkg/terraform"
 )
 
 type FmtOptions struct {
-	Output output.OutputConfig
+	Output   output.OutputConfig
+	Quiet    bool
+	Validate bool
+}
+
+type MergeOptions struct {
+	Inputs []MergeInput
+	Output []output.OutputConfig
+}
+
+type MergeInput struct {
+	Path    string
+	Account string
+}
+
+type DoctorOptions struct {
+	To              string
+	ProviderVersion string
+	DriftignorePath string
+	Driftignores    []string
+	// ProviderInstallOptions tells where to find the terraform provider and how to verify it
+	ProviderInstallOptions terraform.ProviderInstallOptions
+	// AWSEndpoints overrides the endpoints of AWS services, e.g. to check LocalStack
+	AWSEndpoints awsclient.EndpointOptions
+}
+
+type ProvidersOptions struct {
+	ConfigDir       string
+	To              string
+	ProviderVersion string
+	// Keep lists provider versions that must not be pruned, as provider@version
+	Keep                   []string
+	DryRun                 bool
+	ProviderInstallOptions terraform.ProviderInstallOptions
 }
 
 type ScanOptions struct {
@@ -35,12 +72,28 @@ type ScanOptions struct {
 	StrictMode       bool
 	DisableTelemetry bool
 	ProviderVersion  string
-	ConfigDir        string
 	DriftignorePath  string
 	Driftignores     []string
 	Deep             bool
 	OnlyManaged      bool
 	OnlyUnmanaged    bool
+	RulesPath        string
+	MiddlewaresPath  string
+	AlertPolicy      alerter.Policy
+	RetryOptions     retry.Options
+	RecordDir        string
+	ReplayDir        string
+	// ProviderInstallOptions tells where to find the terraform provider and how to verify it
+	ProviderInstallOptions terraform.ProviderInstallOptions
+	// Middlewares declared by users, executed after built-in ones
+	Middlewares middlewares.Chain
+	// AWSEndpoints overrides the endpoints of AWS services, e.g. to scan LocalStack
+	AWSEndpoints awsclient.EndpointOptions
+	// AWSConfigSnapshot tells which AWS Config snapshots, local or in S3, remote resources are read from instead of
+	// enumerating the AWS account, and which accounts and regions to keep
+	AWSConfigSnapshot awsrepository.ConfigSnapshotOptions
+	// AzureResourceGraph lists top level Azure resources with Resource Graph queries instead of ARM listing calls
+	AzureResourceGraph bool
 }
 
 type DriftCTL struct {
@@ -146,6 +199,8 @@ func (d DriftCTL) Run() (*analyser.Analysis, error) {
 		)
 	}
 
+	middleware = append(middleware, d.opts.Middlewares...)
+
 	logrus.Debug("Ready to run middlewares")
 	err = middleware.Execute(&remoteResources, &resourcesFromState)
 	if err != nil {
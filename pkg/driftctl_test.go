@@ -11,6 +11,7 @@ import (
 	"github.com/snyk/driftctl/pkg/filter"
 	"github.com/snyk/driftctl/pkg/memstore"
 	"github.com/snyk/driftctl/pkg/output"
+	remoteaws "github.com/snyk/driftctl/pkg/remote/aws"
 	"github.com/snyk/driftctl/pkg/resource"
 	"github.com/snyk/driftctl/pkg/resource/aws"
 	"github.com/snyk/driftctl/pkg/resource/github"
@@ -35,6 +36,7 @@ type TestCase struct {
 	assert          func(t *testing.T, result *test.ScanResult, err error)
 	assertStore     func(*testing.T, memstore.Store)
 	options         *pkg.ScanOptions
+	filter          filter.Filter
 }
 
 type TestCases []TestCase
@@ -96,11 +98,15 @@ func runTest(t *testing.T, cases TestCases) {
 			iacProgress.On("Start").Return().Once()
 			iacProgress.On("Stop").Return().Once()
 
-			testFilter := &filter.MockFilter{}
-			testFilter.On("IsTypeIgnored", mock.Anything).Return(false)
-			testFilter.On("IsResourceIgnored", mock.Anything).Return(false)
-			testFilter.On("IsFieldIgnored", mock.Anything, mock.Anything).Return(false)
-			analyzer := analyser.NewAnalyzer(testAlerter, analyser.AnalyzerOptions{Deep: c.options.Deep}, testFilter)
+			var testFilter filter.Filter = c.filter
+			if testFilter == nil {
+				mockFilter := &filter.MockFilter{}
+				mockFilter.On("IsTypeIgnored", mock.Anything).Return(false)
+				mockFilter.On("IsResourceIgnored", mock.Anything).Return(false)
+				mockFilter.On("IsFieldIgnored", mock.Anything, mock.Anything).Return(false)
+				testFilter = mockFilter
+			}
+			analyzer := analyser.NewAnalyzer(testAlerter, analyser.AnalyzerOptions{Deep: c.options.Deep}, testFilter, nil)
 
 			store := memstore.New()
 			driftctl := pkg.NewDriftCTL(remoteSupplier, stateSupplier, testAlerter, analyzer, resourceFactory, c.options, scanProgress, iacProgress, repo, store)
@@ -765,6 +771,55 @@ func TestDriftctlRun_BasicFilter(t *testing.T) {
 	runTest(t, cases)
 }
 
+func TestDriftctlRun_AWSConfigSnapshot(t *testing.T) {
+	cases := TestCases{
+		{
+			name: "IaC resources without snapshot enumerator are ignored",
+			stateResources: []*resource.Resource{
+				{
+					Id:    "driftctl-bucket",
+					Type:  aws.AwsS3BucketResourceType,
+					Attrs: &resource.Attributes{},
+				},
+				{
+					Id:    "driftctl-bucket",
+					Type:  aws.AwsS3BucketPolicyResourceType,
+					Attrs: &resource.Attributes{"bucket": "driftctl-bucket"},
+				},
+				{
+					Id:   "driftctl-role-20210603",
+					Type: aws.AwsIamRolePolicyAttachmentResourceType,
+					Attrs: &resource.Attributes{
+						"role":       "driftctl-role",
+						"policy_arn": "arn:aws:iam::aws:policy/ReadOnlyAccess",
+					},
+				},
+				{
+					Id:    "sgrule-3970541193",
+					Type:  aws.AwsSecurityGroupRuleResourceType,
+					Attrs: &resource.Attributes{},
+				},
+			},
+			remoteResources: []*resource.Resource{
+				{
+					Id:    "driftctl-bucket",
+					Type:  aws.AwsS3BucketResourceType,
+					Attrs: &resource.Attributes{},
+				},
+			},
+			filter: filter.NewTypeFilter(filter.NewDriftIgnore(""), remoteaws.ConfigSnapshotResourceTypes()),
+			assert: func(t *testing.T, result *test.ScanResult, err error) {
+				result.AssertManagedCount(1)
+				result.AssertDeletedCount(0)
+				result.AssertUnmanagedCount(0)
+			},
+			options: &pkg.ScanOptions{},
+		},
+	}
+
+	runTest(t, cases)
+}
+
 func TestDriftctlRun_Middlewares(t *testing.T) {
 	cases := TestCases{
 		{
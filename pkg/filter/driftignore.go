@@ -2,6 +2,8 @@ package filter
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"strings"
@@ -17,6 +19,7 @@ type DriftIgnore struct {
 	driftignorePath string
 	ignorePatterns  []string
 	matcher         gitignore.Matcher
+	hash            string
 }
 
 func NewDriftIgnore(path string, ignorePatterns ...string) *DriftIgnore {
@@ -46,9 +49,11 @@ func (r *DriftIgnore) readIgnoreFile() error {
 	defer file.Close()
 
 	var lines []gitignore.Pattern
+	h := sha256.New()
 	scanner := bufio.NewScanner(file)
 	for lineNumber := 1; scanner.Scan(); lineNumber++ {
 		line := scanner.Text()
+		_, _ = fmt.Fprintln(h, line)
 		r.parseIgnorePattern(line, &lines)
 	}
 
@@ -57,19 +62,29 @@ func (r *DriftIgnore) readIgnoreFile() error {
 	}
 
 	r.matcher = gitignore.NewMatcher(lines)
+	r.hash = hex.EncodeToString(h.Sum(nil))
 
 	return nil
 }
 
 func (r *DriftIgnore) parseIgnorePatterns() error {
 	var lines []gitignore.Pattern
+	h := sha256.New()
 	for _, p := range r.ignorePatterns {
+		_, _ = fmt.Fprintln(h, p)
 		r.parseIgnorePattern(p, &lines)
 	}
 	r.matcher = gitignore.NewMatcher(lines)
+	r.hash = hex.EncodeToString(h.Sum(nil))
 	return nil
 }
 
+// Hash returns the SHA-256 of the ignore patterns that were read, one per line, or an empty string when there is
+// no driftignore
+func (r *DriftIgnore) Hash() string {
+	return r.hash
+}
+
 func (r *DriftIgnore) parseIgnorePattern(line string, patterns *[]gitignore.Pattern) {
 	if len(strings.ReplaceAll(line, " ", "")) <= 0 {
 		return // empty
@@ -771,3 +771,17 @@ func TestDriftIgnore_IsTypeIgnored(t *testing.T) {
 		})
 	}
 }
+
+func TestDriftIgnore_Hash(t *testing.T) {
+	content, err := os.ReadFile("testdata/drift_ignore_valid/.driftignore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	patterns := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+
+	fromFile := NewDriftIgnore("testdata/drift_ignore_valid/.driftignore")
+	assert.Len(t, fromFile.Hash(), 64)
+	assert.Equal(t, fromFile.Hash(), NewDriftIgnore("", patterns...).Hash())
+	assert.NotEqual(t, fromFile.Hash(), NewDriftIgnore("testdata/drift_ignore_all/.driftignore").Hash())
+	assert.Empty(t, NewDriftIgnore("testdata/drift_ignore_no_file/.driftignore").Hash())
+}
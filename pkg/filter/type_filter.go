@@ -0,0 +1,38 @@
+package filter
+
+import "github.com/snyk/driftctl/pkg/resource"
+
+// TypeFilter ignores every resource type that is not in a given list, on top of another filter
+type TypeFilter struct {
+	filter Filter
+	types  map[resource.ResourceType]struct{}
+}
+
+func NewTypeFilter(filter Filter, types []resource.ResourceType) *TypeFilter {
+	f := &TypeFilter{
+		filter: filter,
+		types:  make(map[resource.ResourceType]struct{}, len(types)),
+	}
+	for _, ty := range types {
+		f.types[ty] = struct{}{}
+	}
+	return f
+}
+
+func (f *TypeFilter) IsTypeIgnored(ty resource.ResourceType) bool {
+	if _, exist := f.types[ty]; !exist {
+		return true
+	}
+	return f.filter.IsTypeIgnored(ty)
+}
+
+func (f *TypeFilter) IsResourceIgnored(res *resource.Resource) bool {
+	if _, exist := f.types[resource.ResourceType(res.ResourceType())]; !exist {
+		return true
+	}
+	return f.filter.IsResourceIgnored(res)
+}
+
+func (f *TypeFilter) IsFieldIgnored(res *resource.Resource, path []string) bool {
+	return f.filter.IsFieldIgnored(res, path)
+}
@@ -0,0 +1,28 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/snyk/driftctl/pkg/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTypeFilter(t *testing.T) {
+	base := &MockFilter{}
+	base.On("IsTypeIgnored", resource.ResourceType("aws_s3_bucket")).Return(false)
+	base.On("IsTypeIgnored", resource.ResourceType("aws_iam_role")).Return(true)
+	base.On("IsResourceIgnored", mock.Anything).Return(false)
+	base.On("IsFieldIgnored", mock.Anything, mock.Anything).Return(true)
+
+	f := NewTypeFilter(base, []resource.ResourceType{"aws_s3_bucket", "aws_iam_role"})
+
+	assert.False(t, f.IsTypeIgnored("aws_s3_bucket"))
+	assert.True(t, f.IsTypeIgnored("aws_iam_role"))
+	assert.True(t, f.IsTypeIgnored("aws_s3_bucket_policy"))
+
+	assert.False(t, f.IsResourceIgnored(&resource.Resource{Id: "foo", Type: "aws_s3_bucket"}))
+	assert.True(t, f.IsResourceIgnored(&resource.Resource{Id: "foo", Type: "aws_s3_bucket_policy"}))
+
+	assert.True(t, f.IsFieldIgnored(&resource.Resource{Id: "foo", Type: "aws_s3_bucket"}, []string{"tags"}))
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/snyk/driftctl/pkg/alerter"
 	"github.com/snyk/driftctl/pkg/filter"
+	"github.com/snyk/driftctl/pkg/iac/terraform/discovery"
 	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend"
 	"github.com/snyk/driftctl/pkg/output"
 	"github.com/snyk/driftctl/pkg/terraform"
@@ -84,5 +85,6 @@ func GetSupportedSchemes() []string {
 			schemes = append(schemes, fmt.Sprintf("%s+%s://", supplier, backend))
 		}
 	}
+	schemes = append(schemes, fmt.Sprintf("%s://", discovery.TerraformDirScheme))
 	return schemes
 }
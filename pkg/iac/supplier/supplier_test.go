@@ -115,6 +115,7 @@ func TestGetSupportedSchemes(t *testing.T) {
 		"tfstate+tfcloud://",
 		"tfstate+gs://",
 		"tfstate+azurerm://",
+		"tfdir://",
 	}
 
 	if got := GetSupportedSchemes(); !reflect.DeepEqual(got, want) {
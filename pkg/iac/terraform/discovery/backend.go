@@ -0,0 +1,156 @@
+package discovery
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+)
+
+// backendConfig is a backend block of a terraform configuration or a remote_state block of a terragrunt one
+type backendConfig struct {
+	// Type is the terraform backend type, e.g. s3 or gcs
+	Type string
+	// Attributes holds the settings of the backend as strings, e.g. bucket or key
+	Attributes map[string]string
+	// Workspaces holds the settings of the workspaces block of the remote and cloud backends
+	Workspaces map[string]string
+	// Tags are the workspace tags of the cloud backend
+	Tags []string
+}
+
+func (b *backendConfig) get(name, defaultValue string) string {
+	if value, exist := b.Attributes[name]; exist && value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func (b *backendConfig) required(names ...string) error {
+	for _, name := range names {
+		if b.Attributes[name] == "" {
+			return errors.Errorf("%s backend is missing the %s setting, partial backend configurations are not supported", b.Type, name)
+		}
+	}
+	return nil
+}
+
+// supplierConfig returns the state source of a backend, for the workspace selected in the directory
+func (b *backendConfig) supplierConfig(dir, workspace string) (*config.SupplierConfig, error) {
+	switch b.Type {
+	case "local":
+		path := b.get("path", "terraform.tfstate")
+		if workspace != defaultWorkspace {
+			path = filepath.Join(b.get("workspace_dir", "terraform.tfstate.d"), workspace, filepath.Base(path))
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		return newSupplierConfig(backend.BackendKeyFile, path), nil
+	case "s3":
+		if err := b.required("bucket", "key"); err != nil {
+			return nil, err
+		}
+		key := b.Attributes["key"]
+		if workspace != defaultWorkspace {
+			key = strings.Join([]string{b.get("workspace_key_prefix", "env:"), workspace, key}, "/")
+		}
+		path := strings.Join([]string{b.Attributes["bucket"], key}, "/")
+		if query := b.s3Options().Encode(); query != "" {
+			path = fmt.Sprintf("%s?%s", path, query)
+		}
+		return newSupplierConfig(backend.BackendKeyS3, path), nil
+	case "gcs":
+		if err := b.required("bucket"); err != nil {
+			return nil, err
+		}
+		path := []string{b.Attributes["bucket"]}
+		if prefix := strings.Trim(b.Attributes["prefix"], "/"); prefix != "" {
+			path = append(path, prefix)
+		}
+		path = append(path, fmt.Sprintf("%s.tfstate", workspace))
+		return newSupplierConfig(backend.BackendKeyGS, strings.Join(path, "/")), nil
+	case "azurerm":
+		if err := b.required("container_name", "key"); err != nil {
+			return nil, err
+		}
+		key := b.Attributes["key"]
+		if workspace != defaultWorkspace {
+			key = fmt.Sprintf("%senv:%s", key, workspace)
+		}
+		path := strings.Join([]string{b.Attributes["container_name"], key}, "/")
+		// The access key of the storage account is never copied from the backend block, it is given with the
+		// global azurerm options or through the access_key_env option of the source
+		if account := b.Attributes[options.AzureRMStorageAccountOption]; account != "" {
+			path = fmt.Sprintf("%s?%s", path, options.AzureRMSourceOptions{StorageAccount: account}.Query().Encode())
+		}
+		return newSupplierConfig(backend.BackendKeyAzureRM, path), nil
+	case "http":
+		if err := b.required("address"); err != nil {
+			return nil, err
+		}
+		schemePath := strings.SplitN(b.Attributes["address"], "://", 2)
+		if len(schemePath) != 2 || (schemePath[0] != backend.BackendKeyHTTP && schemePath[0] != backend.BackendKeyHTTPS) {
+			return nil, errors.Errorf("unsupported http backend address %s", b.Attributes["address"])
+		}
+		return newSupplierConfig(schemePath[0], schemePath[1]), nil
+	case "remote", "cloud":
+		return b.tfCloudSupplierConfig(workspace)
+	default:
+		return nil, errors.Errorf("%s backend is not supported", b.Type)
+	}
+}
+
+// s3Options returns the settings of the s3 backend driftctl needs to read the state, e.g. to assume a role
+func (b *backendConfig) s3Options() url.Values {
+	query := url.Values{}
+	for _, name := range []string{options.S3RegionOption, options.S3EndpointOption, options.S3RoleARNOption, options.S3ExternalIDOption} {
+		if value := b.Attributes[name]; value != "" {
+			query.Set(name, value)
+		}
+	}
+	if b.Attributes[options.S3ForcePathStyleOption] == "true" {
+		query.Set(options.S3ForcePathStyleOption, "true")
+	}
+	return query
+}
+
+// tfCloudSupplierConfig returns the state source of the remote and cloud backends, a single workspace is read when
+// it is named or selected, otherwise every workspace matching the prefix or the tags is enumerated
+func (b *backendConfig) tfCloudSupplierConfig(workspace string) (*config.SupplierConfig, error) {
+	if err := b.required("organization"); err != nil {
+		return nil, err
+	}
+	organization := b.Attributes["organization"]
+
+	if name := b.Workspaces["name"]; name != "" {
+		return newSupplierConfig(backend.BackendKeyTFCloud, fmt.Sprintf("%s/%s", organization, name)), nil
+	}
+	if prefix := b.Workspaces["prefix"]; prefix != "" {
+		if workspace != defaultWorkspace {
+			return newSupplierConfig(backend.BackendKeyTFCloud, fmt.Sprintf("%s/%s%s", organization, prefix, workspace)), nil
+		}
+		return newSupplierConfig(backend.BackendKeyTFCloud, fmt.Sprintf("%s/%s*", organization, prefix)), nil
+	}
+	if len(b.Tags) > 0 {
+		if workspace != defaultWorkspace {
+			return newSupplierConfig(backend.BackendKeyTFCloud, fmt.Sprintf("%s/%s", organization, workspace)), nil
+		}
+		return newSupplierConfig(backend.BackendKeyTFCloud, fmt.Sprintf("%s?tags=%s", organization, strings.Join(b.Tags, ","))), nil
+	}
+	return nil, errors.Errorf("%s backend is missing the workspaces setting", b.Type)
+}
+
+func newSupplierConfig(backendKey, path string) *config.SupplierConfig {
+	return &config.SupplierConfig{
+		Key:     state.TerraformStateReaderSupplier,
+		Backend: backendKey,
+		Path:    path,
+	}
+}
@@ -0,0 +1,137 @@
+package discovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/iac/config"
+)
+
+// TerraformDirScheme is the --from scheme of a directory of terraform root modules or terragrunt configurations,
+// e.g. tfdir://path/to/infra
+const TerraformDirScheme = "tfdir"
+
+const (
+	terragruntConfigFile = "terragrunt.hcl"
+	defaultWorkspace     = "default"
+)
+
+// Discover walks a directory of terraform root modules and terragrunt configurations, and returns the state
+// sources declared by their backend, cloud or remote_state blocks
+func Discover(root string) ([]config.SupplierConfig, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("%s is not a directory", root)
+	}
+
+	terragruntFiles := make([]string, 0)
+	terraformDirs := make([]string, 0)
+	seenTerraformDirs := make(map[string]struct{})
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			// Skip .terraform, .terragrunt-cache and any other hidden directory
+			if path != root && strings.HasPrefix(info.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() == terragruntConfigFile {
+			terragruntFiles = append(terragruntFiles, path)
+			return nil
+		}
+		if isTerraformFile(info.Name()) {
+			dir := filepath.Dir(path)
+			// Files of a directory are not walked in a row when it holds sub directories, e.g. main.tf, modules/, variables.tf
+			if _, exist := seenTerraformDirs[dir]; !exist {
+				seenTerraformDirs[dir] = struct{}{}
+				terraformDirs = append(terraformDirs, dir)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	configs := make([]config.SupplierConfig, 0)
+	terragruntDirs := make(map[string]struct{}, len(terragruntFiles))
+	for _, file := range terragruntFiles {
+		terragruntDirs[filepath.Dir(file)] = struct{}{}
+	}
+	terragruntConfigs, err := discoverTerragrunt(terragruntFiles)
+	if err != nil {
+		return nil, err
+	}
+	configs = append(configs, terragruntConfigs...)
+
+	for _, dir := range terraformDirs {
+		// Terragrunt generates the backend of the modules it deploys, the terragrunt configuration wins
+		if _, exist := terragruntDirs[dir]; exist {
+			continue
+		}
+		dirConfigs, err := discoverTerraform(dir)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"dir":   dir,
+				"error": err,
+			}).Warn("Unable to read terraform backend configuration, skipping directory")
+			continue
+		}
+		configs = append(configs, dirConfigs...)
+	}
+
+	configs = uniqueConfigs(configs)
+	if len(configs) == 0 {
+		return nil, errors.Errorf("no terraform backend configuration was found in %s", root)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"root":    root,
+		"sources": len(configs),
+	}).Debug("Discovered IaC sources")
+
+	return configs, nil
+}
+
+func isTerraformFile(name string) bool {
+	return strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tf.json")
+}
+
+// selectedWorkspace returns the workspace terraform selected in a directory, as recorded in .terraform/environment
+func selectedWorkspace(dir string) string {
+	content, err := ioutil.ReadFile(filepath.Join(dir, ".terraform", "environment"))
+	if err != nil {
+		return defaultWorkspace
+	}
+	if workspace := strings.TrimSpace(string(content)); workspace != "" {
+		return workspace
+	}
+	return defaultWorkspace
+}
+
+func uniqueConfigs(configs []config.SupplierConfig) []config.SupplierConfig {
+	seen := make(map[string]struct{}, len(configs))
+	unique := make([]config.SupplierConfig, 0, len(configs))
+	for _, c := range configs {
+		if _, exist := seen[c.String()]; exist {
+			continue
+		}
+		seen[c.String()] = struct{}{}
+		unique = append(unique, c)
+	}
+	sort.SliceStable(unique, func(i, j int) bool {
+		return unique[i].String() < unique[j].String()
+	})
+	return unique
+}
@@ -0,0 +1,154 @@
+package discovery
+
+import (
+	"testing"
+
+	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscover(t *testing.T) {
+	tests := []struct {
+		name  string
+		root  string
+		env   map[string]string
+		want  []config.SupplierConfig
+		error string
+	}{
+		{
+			name: "terraform root modules",
+			root: "testdata/terraform",
+			want: []config.SupplierConfig{
+				{Key: "tfstate", Backend: "gs", Path: "gcs-states/storage/default.tfstate"},
+				{Key: "tfstate", Backend: "s3", Path: "states/network/terraform.tfstate?region=us-east-1"},
+				{Key: "tfstate", Backend: "s3", Path: "states/workspaces/prod/database.tfstate"},
+				{Key: "tfstate", Backend: "tfcloud", Path: "acme?tags=app,prod"},
+				{Key: "tfstate", Backend: "", Path: "testdata/terraform/legacy/terraform.tfstate"},
+			},
+		},
+		{
+			name: "terragrunt configurations",
+			root: "testdata/terragrunt",
+			env:  map[string]string{"DCTL_TEST_TERRAGRUNT_ENV": "prod"},
+			want: []config.SupplierConfig{
+				{Key: "tfstate", Backend: "s3", Path: "terragrunt-prod/live/eks/terraform.tfstate?region=eu-west-1"},
+				{Key: "tfstate", Backend: "s3", Path: "terragrunt-prod/live/vpc/terraform.tfstate?region=eu-west-1"},
+			},
+		},
+		{
+			name:  "no backend",
+			root:  "testdata/terraform/modules",
+			error: "no terraform backend configuration was found in testdata/terraform/modules",
+		},
+		{
+			name:  "not a directory",
+			root:  "testdata/terraform/network/main.tf",
+			error: "testdata/terraform/network/main.tf is not a directory",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for key, value := range tt.env {
+				t.Setenv(key, value)
+			}
+			got, err := Discover(tt.root)
+			if tt.error != "" {
+				assert.EqualError(t, err, tt.error)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestBackendConfig_SupplierConfig(t *testing.T) {
+	tests := []struct {
+		name      string
+		backend   backendConfig
+		workspace string
+		want      string
+		error     string
+	}{
+		{
+			name:      "local workspace",
+			backend:   backendConfig{Type: "local"},
+			workspace: "staging",
+			want:      "tfstate://infra/terraform.tfstate.d/staging/terraform.tfstate",
+		},
+		{
+			name:      "s3 default workspace prefix",
+			backend:   backendConfig{Type: "s3", Attributes: map[string]string{"bucket": "states", "key": "app.tfstate"}},
+			workspace: "staging",
+			want:      "tfstate+s3://states/env:/staging/app.tfstate",
+		},
+		{
+			name: "s3 options",
+			backend: backendConfig{Type: "s3", Attributes: map[string]string{
+				"bucket":           "states",
+				"key":              "app.tfstate",
+				"endpoint":         "https://minio.example.com",
+				"force_path_style": "true",
+				"role_arn":         "arn:aws:iam::123456789012:role/states",
+			}},
+			workspace: "default",
+			want:      "tfstate+s3://states/app.tfstate?endpoint=https%3A%2F%2Fminio.example.com&force_path_style=true&role_arn=arn%3Aaws%3Aiam%3A%3A123456789012%3Arole%2Fstates",
+		},
+		{
+			name:      "azurerm workspace",
+			backend:   backendConfig{Type: "azurerm", Attributes: map[string]string{"container_name": "states", "key": "app.tfstate"}},
+			workspace: "staging",
+			want:      "tfstate+azurerm://states/app.tfstateenv:staging",
+		},
+		{
+			name: "azurerm storage account",
+			backend: backendConfig{Type: "azurerm", Attributes: map[string]string{
+				"storage_account_name": "acmestates",
+				"container_name":       "states",
+				"key":                  "app.tfstate",
+				"access_key":           "secret",
+			}},
+			workspace: "default",
+			want:      "tfstate+azurerm://states/app.tfstate?storage_account_name=acmestates",
+		},
+		{
+			name:      "http",
+			backend:   backendConfig{Type: "http", Attributes: map[string]string{"address": "https://example.com/states/app"}},
+			workspace: "default",
+			want:      "tfstate+https://example.com/states/app",
+		},
+		{
+			name: "remote workspaces prefix",
+			backend: backendConfig{
+				Type:       "remote",
+				Attributes: map[string]string{"organization": "acme"},
+				Workspaces: map[string]string{"prefix": "app-"},
+			},
+			workspace: "default",
+			want:      "tfstate+tfcloud://acme/app-*",
+		},
+		{
+			name:      "partial configuration",
+			backend:   backendConfig{Type: "s3", Attributes: map[string]string{"region": "us-east-1"}},
+			workspace: "default",
+			error:     "s3 backend is missing the bucket setting, partial backend configurations are not supported",
+		},
+		{
+			name:      "unsupported backend",
+			backend:   backendConfig{Type: "consul"},
+			workspace: "default",
+			error:     "consul backend is not supported",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.backend.supplierConfig("infra", tt.workspace)
+			if tt.error != "" {
+				assert.EqualError(t, err, tt.error)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
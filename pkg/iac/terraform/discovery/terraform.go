@@ -0,0 +1,187 @@
+package discovery
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+)
+
+var terraformFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+	},
+}
+
+var terraformBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "backend", LabelNames: []string{"type"}},
+		{Type: "cloud"},
+	},
+}
+
+var workspacesBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "workspaces"},
+	},
+}
+
+// discoverTerraform returns the state source of a terraform root module. Directories without backend are only
+// considered root modules when they contain a local state.
+func discoverTerraform(dir string) ([]config.SupplierConfig, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	var backend *backendConfig
+	for _, file := range files {
+		if file.IsDir() || !isTerraformFile(file.Name()) {
+			continue
+		}
+		path := filepath.Join(dir, file.Name())
+		var f *hcl.File
+		var diags hcl.Diagnostics
+		if strings.HasSuffix(file.Name(), ".json") {
+			f, diags = parser.ParseJSONFile(path)
+		} else {
+			f, diags = parser.ParseHCLFile(path)
+		}
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		fileBackend, err := readTerraformBackend(f.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, path)
+		}
+		if fileBackend != nil {
+			backend = fileBackend
+		}
+	}
+
+	if backend == nil {
+		if _, err := os.Stat(filepath.Join(dir, "terraform.tfstate")); err != nil {
+			return nil, nil
+		}
+		backend = &backendConfig{Type: "local"}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"dir":     dir,
+		"backend": backend.Type,
+	}).Debug("Found terraform backend")
+
+	supplierConfig, err := backend.supplierConfig(dir, selectedWorkspace(dir))
+	if err != nil {
+		return nil, err
+	}
+	return []config.SupplierConfig{*supplierConfig}, nil
+}
+
+func readTerraformBackend(body hcl.Body) (*backendConfig, error) {
+	content, _, diags := body.PartialContent(terraformFileSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+
+	var backend *backendConfig
+	for _, terraformBlock := range content.Blocks {
+		terraformContent, _, diags := terraformBlock.Body.PartialContent(terraformBlockSchema)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		for _, block := range terraformContent.Blocks {
+			backendType := "cloud"
+			if block.Type == "backend" {
+				backendType = block.Labels[0]
+			}
+			attributes, err := readStringAttributes(block.Body)
+			if err != nil {
+				return nil, err
+			}
+			backend = &backendConfig{
+				Type:       backendType,
+				Attributes: attributes,
+			}
+			if err := readWorkspacesBlock(block.Body, backend); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return backend, nil
+}
+
+// readWorkspacesBlock reads the workspaces block of the remote and cloud backends
+func readWorkspacesBlock(body hcl.Body, backend *backendConfig) error {
+	content, _, diags := body.PartialContent(workspacesBlockSchema)
+	if diags.HasErrors() {
+		return diags
+	}
+	for _, block := range content.Blocks {
+		attrs, diags := block.Body.JustAttributes()
+		if diags.HasErrors() {
+			return diags
+		}
+		backend.Workspaces = make(map[string]string)
+		for name, attr := range attrs {
+			value, diags := attr.Expr.Value(nil)
+			if diags.HasErrors() {
+				return diags
+			}
+			if name == "tags" && value.CanIterateElements() {
+				for it := value.ElementIterator(); it.Next(); {
+					_, tag := it.Element()
+					if tag.Type() == cty.String && tag.IsKnown() && !tag.IsNull() {
+						backend.Tags = append(backend.Tags, tag.AsString())
+					}
+				}
+				continue
+			}
+			if value.Type() == cty.String && !value.IsNull() {
+				backend.Workspaces[name] = value.AsString()
+			}
+		}
+	}
+	return nil
+}
+
+// readStringAttributes returns the string attributes of a backend block, backend settings can't reference
+// variables so they are evaluated without context
+func readStringAttributes(body hcl.Body) (map[string]string, error) {
+	var attrs hcl.Attributes
+	if syntaxBody, ok := body.(*hclsyntax.Body); ok {
+		// Nested blocks, e.g. workspaces, are not attributes so only the attributes of the block are read
+		attrs = make(hcl.Attributes, len(syntaxBody.Attributes))
+		for name, attr := range syntaxBody.Attributes {
+			attrs[name] = attr.AsHCLAttribute()
+		}
+	} else {
+		var diags hcl.Diagnostics
+		attrs, diags = body.JustAttributes()
+		if diags.HasErrors() {
+			return nil, diags
+		}
+	}
+	result := make(map[string]string, len(attrs))
+	for name, attr := range attrs {
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || !value.IsKnown() || value.IsNull() {
+			continue
+		}
+		// Booleans are kept as strings, e.g. force_path_style
+		if value, err := convert.Convert(value, cty.String); err == nil && value.Type() == cty.String {
+			result[name] = value.AsString()
+		}
+	}
+	return result, nil
+}
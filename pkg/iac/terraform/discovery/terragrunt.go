@@ -0,0 +1,326 @@
+package discovery
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/convert"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+// terragruntConfig is a terragrunt.hcl file evaluated for the directory it belongs to
+type terragruntConfig struct {
+	path     string
+	includes []string
+	backend  *backendConfig
+}
+
+// discoverTerragrunt returns the state sources of terragrunt configurations. Configurations included by other
+// ones, e.g. the root terragrunt.hcl holding the remote_state block, are not deployed by themselves and are skipped.
+func discoverTerragrunt(files []string) ([]config.SupplierConfig, error) {
+	parser := hclparse.NewParser()
+	included := make(map[string]struct{})
+	terragruntConfigs := make([]*terragruntConfig, 0, len(files))
+	for _, file := range files {
+		terragruntConfig, err := readTerragruntConfig(parser, file)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"file":  file,
+				"error": err,
+			}).Warn("Unable to read terragrunt configuration, skipping file")
+			continue
+		}
+		for _, include := range terragruntConfig.includes {
+			included[include] = struct{}{}
+		}
+		terragruntConfigs = append(terragruntConfigs, terragruntConfig)
+	}
+
+	configs := make([]config.SupplierConfig, 0)
+	for _, terragruntConfig := range terragruntConfigs {
+		abs, err := filepath.Abs(terragruntConfig.path)
+		if err != nil {
+			return nil, err
+		}
+		if _, exist := included[abs]; exist || terragruntConfig.backend == nil {
+			continue
+		}
+		supplierConfig, err := terragruntConfig.backend.supplierConfig(filepath.Dir(terragruntConfig.path), defaultWorkspace)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"file":  terragruntConfig.path,
+				"error": err,
+			}).Warn("Unable to read terragrunt remote state, skipping file")
+			continue
+		}
+		configs = append(configs, *supplierConfig)
+	}
+	return configs, nil
+}
+
+func readTerragruntConfig(parser *hclparse.Parser, path string) (*terragruntConfig, error) {
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, err
+	}
+	body, err := parseTerragruntFile(parser, path)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := newTerragruntEvalContext(dir, "")
+	evalTerragruntLocals(body, ctx)
+
+	terragruntConfig := &terragruntConfig{path: path}
+	for _, block := range body.Blocks {
+		if block.Type != "include" {
+			continue
+		}
+		attr, exist := block.Body.Attributes["path"]
+		if !exist {
+			return nil, errors.New("include block is missing the path attribute")
+		}
+		value, diags := attr.Expr.Value(ctx)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		if value.Type() != cty.String || value.IsNull() {
+			return nil, errors.New("include path must be a string")
+		}
+		includePath := value.AsString()
+		if !filepath.IsAbs(includePath) {
+			includePath = filepath.Join(dir, includePath)
+		}
+		terragruntConfig.includes = append(terragruntConfig.includes, filepath.Clean(includePath))
+	}
+
+	// The remote_state block of the configuration wins over the included ones
+	if block := findBlock(body, "remote_state"); block != nil {
+		terragruntConfig.backend, err = evalRemoteState(block, ctx)
+		return terragruntConfig, err
+	}
+	for _, include := range terragruntConfig.includes {
+		includeBody, err := parseTerragruntFile(parser, include)
+		if err != nil {
+			return nil, err
+		}
+		block := findBlock(includeBody, "remote_state")
+		if block == nil {
+			continue
+		}
+		// Functions of an included configuration are evaluated for the including one, e.g. path_relative_to_include
+		includeCtx := newTerragruntEvalContext(dir, filepath.Dir(include))
+		evalTerragruntLocals(includeBody, includeCtx)
+		terragruntConfig.backend, err = evalRemoteState(block, includeCtx)
+		return terragruntConfig, err
+	}
+
+	return terragruntConfig, nil
+}
+
+func parseTerragruntFile(parser *hclparse.Parser, path string) (*hclsyntax.Body, error) {
+	f, diags := parser.ParseHCLFile(path)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	body, ok := f.Body.(*hclsyntax.Body)
+	if !ok {
+		return nil, errors.Errorf("unable to read %s", path)
+	}
+	return body, nil
+}
+
+func findBlock(body *hclsyntax.Body, blockType string) *hclsyntax.Block {
+	for _, block := range body.Blocks {
+		if block.Type == blockType {
+			return block
+		}
+	}
+	return nil
+}
+
+// evalRemoteState evaluates the backend and the string settings of a remote_state block. Settings that can't be
+// evaluated, e.g. bucket tags using unsupported functions, are ignored as long as the required ones are known.
+func evalRemoteState(block *hclsyntax.Block, ctx *hcl.EvalContext) (*backendConfig, error) {
+	backendAttr, exist := block.Body.Attributes["backend"]
+	if !exist {
+		return nil, errors.New("remote_state block is missing the backend attribute")
+	}
+	backendType, diags := backendAttr.Expr.Value(ctx)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	if backendType.Type() != cty.String || backendType.IsNull() {
+		return nil, errors.New("remote_state backend must be a string")
+	}
+
+	backend := &backendConfig{
+		Type:       backendType.AsString(),
+		Attributes: make(map[string]string),
+	}
+	configAttr, exist := block.Body.Attributes["config"]
+	if !exist {
+		return backend, nil
+	}
+	object, ok := configAttr.Expr.(*hclsyntax.ObjectConsExpr)
+	if !ok {
+		return nil, errors.New("remote_state config must be an object")
+	}
+	for _, item := range object.Items {
+		key, diags := item.KeyExpr.Value(ctx)
+		if diags.HasErrors() || key.Type() != cty.String || key.IsNull() {
+			continue
+		}
+		value, diags := item.ValueExpr.Value(ctx)
+		if diags.HasErrors() {
+			logrus.WithFields(logrus.Fields{
+				"setting": key.AsString(),
+				"error":   diags.Error(),
+			}).Debug("Unable to evaluate terragrunt remote state setting")
+			continue
+		}
+		if !value.IsKnown() || value.IsNull() {
+			continue
+		}
+		if value, err := convert.Convert(value, cty.String); err == nil && value.Type() == cty.String {
+			backend.Attributes[key.AsString()] = value.AsString()
+		}
+	}
+	return backend, nil
+}
+
+// evalTerragruntLocals adds the locals that can be evaluated to the context, locals may reference each other so they
+// are evaluated until no more local can be resolved
+func evalTerragruntLocals(body *hclsyntax.Body, ctx *hcl.EvalContext) {
+	locals := make(map[string]cty.Value)
+	pending := make(map[string]*hclsyntax.Attribute)
+	for _, block := range body.Blocks {
+		if block.Type != "locals" {
+			continue
+		}
+		for name, attr := range block.Body.Attributes {
+			pending[name] = attr
+		}
+	}
+
+	for resolved := true; resolved && len(pending) > 0; {
+		resolved = false
+		for name, attr := range pending {
+			ctx.Variables["local"] = cty.ObjectVal(locals)
+			value, diags := attr.Expr.Value(ctx)
+			if diags.HasErrors() {
+				continue
+			}
+			locals[name] = value
+			delete(pending, name)
+			resolved = true
+		}
+	}
+	ctx.Variables["local"] = cty.ObjectVal(locals)
+}
+
+// newTerragruntEvalContext returns the context used to evaluate the configuration of a terragrunt directory,
+// includeDir is the directory of the included configuration being evaluated, if any
+func newTerragruntEvalContext(dir, includeDir string) *hcl.EvalContext {
+	return &hcl.EvalContext{
+		Variables: map[string]cty.Value{},
+		Functions: map[string]function.Function{
+			"find_in_parent_folders":     findInParentFoldersFunc(dir),
+			"path_relative_to_include":   relativePathFunc(includeDir, dir),
+			"path_relative_from_include": relativePathFunc(dir, includeDir),
+			"get_terragrunt_dir":         stringFunc(dir),
+			"get_parent_terragrunt_dir":  stringFunc(orDefault(includeDir, dir)),
+			"get_env":                    getEnvFunc,
+			"format":                     stdlib.FormatFunc,
+			"join":                       stdlib.JoinFunc,
+			"lower":                      stdlib.LowerFunc,
+			"replace":                    stdlib.ReplaceFunc,
+			"split":                      stdlib.SplitFunc,
+			"trimspace":                  stdlib.TrimSpaceFunc,
+			"upper":                      stdlib.UpperFunc,
+		},
+	}
+}
+
+func orDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func stringFunc(value string) function.Function {
+	return function.New(&function.Spec{
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.StringVal(value), nil
+		},
+	})
+}
+
+// relativePathFunc returns the path of target relative to base, or "." when not evaluating an included configuration
+func relativePathFunc(base, target string) function.Function {
+	return function.New(&function.Spec{
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if base == "" || target == "" {
+				return cty.StringVal("."), nil
+			}
+			rel, err := filepath.Rel(base, target)
+			if err != nil {
+				return cty.NilVal, err
+			}
+			return cty.StringVal(filepath.ToSlash(rel)), nil
+		},
+	})
+}
+
+// findInParentFoldersFunc looks for a file, terragrunt.hcl by default, in the parent directories of dir
+func findInParentFoldersFunc(dir string) function.Function {
+	return function.New(&function.Spec{
+		VarParam: &function.Parameter{Name: "args", Type: cty.String},
+		Type:     function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			name := terragruntConfigFile
+			if len(args) > 0 {
+				name = args[0].AsString()
+			}
+			for current := filepath.Dir(dir); ; current = filepath.Dir(current) {
+				path := filepath.Join(current, name)
+				if _, err := os.Stat(path); err == nil {
+					return cty.StringVal(path), nil
+				}
+				if filepath.Dir(current) == current {
+					break
+				}
+			}
+			if len(args) > 1 {
+				return args[1], nil
+			}
+			return cty.NilVal, errors.Errorf("unable to find %s in the parent folders of %s", name, dir)
+		},
+	})
+}
+
+var getEnvFunc = function.New(&function.Spec{
+	Params:   []function.Parameter{{Name: "name", Type: cty.String}},
+	VarParam: &function.Parameter{Name: "default", Type: cty.String},
+	Type:     function.StaticReturnType(cty.String),
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		if value, exist := os.LookupEnv(args[0].AsString()); exist {
+			return cty.StringVal(value), nil
+		}
+		if len(args) > 1 {
+			return args[1], nil
+		}
+		return cty.StringVal(""), nil
+	},
+})
@@ -0,0 +1,7 @@
+terraform {
+  backend "s3" {
+    bucket               = "states"
+    key                  = "database.tfstate"
+    workspace_key_prefix = "workspaces"
+  }
+}
@@ -0,0 +1,4 @@
+terraform {
+  # bucket and key are given with -backend-config
+  backend "s3" {}
+}
@@ -0,0 +1,4 @@
+terraform {
+  # Generated by terragrunt
+  backend "s3" {}
+}
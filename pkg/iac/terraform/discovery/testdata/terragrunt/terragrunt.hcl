@@ -0,0 +1,15 @@
+locals {
+  environment = get_env("DCTL_TEST_TERRAGRUNT_ENV", "dev")
+  bucket      = "terragrunt-${local.environment}"
+}
+
+remote_state {
+  backend = "s3"
+  config = {
+    bucket         = local.bucket
+    key            = "${path_relative_to_include()}/terraform.tfstate"
+    region         = "eu-west-1"
+    encrypt        = true
+    s3_bucket_tags = { owner = get_aws_account_id() }
+  }
+}
@@ -1,6 +1,24 @@
 package state
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/snyk/driftctl/pkg/alerter"
+)
+
+const (
+	StateReadingAlertKind            = "state_reading"
+	UnsupportedStateVersionAlertKind = "unsupported_state_version"
+)
+
+func init() {
+	alerter.RegisterKind(StateReadingAlertKind, func(alert *alerter.SerializedAlert) alerter.Alert {
+		return &StateReadingAlert{key: alert.Details()["key"], err: alert.Details()["error"]}
+	})
+	alerter.RegisterKind(UnsupportedStateVersionAlertKind, func(alert *alerter.SerializedAlert) alerter.Alert {
+		return &UnsupportedStateVersionAlert{StateReadingAlert{key: alert.Details()["key"], err: alert.Details()["error"]}}
+	})
+}
 
 type StateReadingAlert struct {
 	key string
@@ -11,6 +29,10 @@ func NewStateReadingAlert(key string, err error) *StateReadingAlert {
 	return &StateReadingAlert{key: key, err: err.Error()}
 }
 
+func (s *StateReadingAlert) Kind() string {
+	return StateReadingAlertKind
+}
+
 func (s *StateReadingAlert) Message() string {
 	return fmt.Sprintf("Your analysis may be incomplete. There was an error reading state file '%s': %s", s.key, s.err)
 }
@@ -18,3 +40,24 @@ func (s *StateReadingAlert) Message() string {
 func (s *StateReadingAlert) ShouldIgnoreResource() bool {
 	return false
 }
+
+func (s *StateReadingAlert) Details() map[string]string {
+	return map[string]string{
+		"key":   s.key,
+		"error": s.err,
+	}
+}
+
+// UnsupportedStateVersionAlert is sent instead of a StateReadingAlert when a state was generated
+// by a Terraform version we do not support, so it can be handled differently by alert policies
+type UnsupportedStateVersionAlert struct {
+	StateReadingAlert
+}
+
+func NewUnsupportedStateVersionAlert(key string, err error) *UnsupportedStateVersionAlert {
+	return &UnsupportedStateVersionAlert{StateReadingAlert{key: key, err: err.Error()}}
+}
+
+func (s *UnsupportedStateVersionAlert) Kind() string {
+	return UnsupportedStateVersionAlertKind
+}
@@ -19,6 +19,11 @@ type AzureRMBackend struct {
 }
 
 func NewAzureRMReader(path string, opts options.AzureRMBackendOptions) (*AzureRMBackend, error) {
+	path, query := options.SplitQuery(path, options.AzureRMOptionKeys...)
+	opts, err := options.ParseAzureRMSourceOptions(query).Apply(opts)
+	if err != nil {
+		return nil, err
+	}
 	bucketPath := strings.Split(path, "/")
 	if len(bucketPath) < 2 || bucketPath[1] == "" {
 		return nil, errors.Errorf("Unable to parse azurerm backend storage path: %s. Must be CONTAINER/PATH/TO/OBJECT", path)
@@ -26,12 +31,23 @@ func NewAzureRMReader(path string, opts options.AzureRMBackendOptions) (*AzureRM
 	containerName := bucketPath[0]
 	objectPath := strings.Join(bucketPath[1:], "/")
 
-	credential, err := azblob.NewSharedKeyCredential(opts.StorageAccount, opts.StorageKey)
+	blobClient, err := newAzureBlockBlobClient(containerName, objectPath, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	blobClient, err := azblob.NewBlockBlobClientWithSharedKey(
+	return &AzureRMBackend{
+		storageClient: blobClient,
+	}, nil
+}
+
+func newAzureBlockBlobClient(containerName, objectPath string, opts options.AzureRMBackendOptions) (azblob.BlockBlobClient, error) {
+	credential, err := azblob.NewSharedKeyCredential(opts.StorageAccount, opts.StorageKey)
+	if err != nil {
+		return azblob.BlockBlobClient{}, err
+	}
+
+	return azblob.NewBlockBlobClientWithSharedKey(
 		fmt.Sprintf(
 			"https://%s.blob.core.windows.net/%s/%s",
 			credential.AccountName(),
@@ -41,13 +57,6 @@ func NewAzureRMReader(path string, opts options.AzureRMBackendOptions) (*AzureRM
 		credential,
 		nil,
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return &AzureRMBackend{
-		storageClient: blobClient,
-	}, nil
 }
 
 func (s *AzureRMBackend) Read(p []byte) (int, error) {
@@ -34,7 +34,26 @@ func TestNewAzureRMReader(t *testing.T) {
 				return false
 			},
 		},
+		{
+			name:    "storage account without access key",
+			options: options.AzureRMBackendOptions{StorageAccount: "global", StorageKey: "Zm9v"},
+			path:    "containerName/valid.tfstate?storage_account_name=other",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				assert.EqualError(t, err, "state is stored in the other storage account, only the key of the global storage account is configured, set the access_key_env option to the environment variable holding its access key")
+				return false
+			},
+		},
+		{
+			name:    "storage account with access key",
+			options: options.AzureRMBackendOptions{StorageAccount: "global", StorageKey: "Zm9v"},
+			path:    "containerName/valid.tfstate?storage_account_name=other&access_key_env=DCTL_TEST_AZURE_ACCESS_KEY",
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				assert.NoError(t, err)
+				return false
+			},
+		},
 	}
+	t.Setenv("DCTL_TEST_AZURE_ACCESS_KEY", "YmFy")
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			_, err := NewAzureRMReader(tt.path, tt.options)
@@ -0,0 +1,42 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+)
+
+// AzureRMWriter buffers written content and uploads it to an Azure blob on close
+type AzureRMWriter struct {
+	buf           bytes.Buffer
+	storageClient azblob.BlockBlobClient
+}
+
+func NewAzureRMWriter(path string, opts options.AzureRMBackendOptions) (*AzureRMWriter, error) {
+	bucketPath := strings.Split(path, "/")
+	if len(bucketPath) < 2 || bucketPath[1] == "" {
+		return nil, errors.Errorf("Unable to parse azurerm storage path: %s. Must be CONTAINER/PATH/TO/OBJECT", path)
+	}
+
+	blobClient, err := newAzureBlockBlobClient(bucketPath[0], strings.Join(bucketPath[1:], "/"), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AzureRMWriter{
+		storageClient: blobClient,
+	}, nil
+}
+
+func (s *AzureRMWriter) Write(p []byte) (int, error) {
+	return s.buf.Write(p)
+}
+
+func (s *AzureRMWriter) Close() error {
+	_, err := s.storageClient.UploadBufferToBlockBlob(context.Background(), s.buf.Bytes(), azblob.HighLevelUploadToBlockBlobOption{})
+	return err
+}
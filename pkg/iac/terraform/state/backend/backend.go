@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 
 	"github.com/pkg/errors"
 	"github.com/snyk/driftctl/pkg/iac/config"
@@ -27,6 +28,22 @@ type Options struct {
 	TFCloudToken    string
 	TFCloudEndpoint string
 	options.AzureRMBackendOptions
+	options.HTTPBackendOptions
+	options.StateEncryptionOptions
+
+	// httpClient is shared by every http(s) state so OAuth2 tokens are only requested when they expire,
+	// states are read in parallel so it is built once
+	httpClientOnce sync.Once
+	httpClient     *http.Client
+	httpClientErr  error
+}
+
+// HTTPClient returns the client of the http(s) backend, built once from the HTTP backend options
+func (o *Options) HTTPClient() (*http.Client, error) {
+	o.httpClientOnce.Do(func() {
+		o.httpClient, o.httpClientErr = NewHTTPClient(o.HTTPBackendOptions)
+	})
+	return o.httpClient, o.httpClientErr
 }
 
 func IsSupported(backend string) bool {
@@ -54,7 +71,11 @@ func GetBackend(config config.SupplierConfig, opts *Options) (Backend, error) {
 	case BackendKeyHTTP:
 		fallthrough
 	case BackendKeyHTTPS:
-		return NewHTTPReader(&http.Client{}, fmt.Sprintf("%s://%s", config.Backend, config.Path), opts)
+		client, err := opts.HTTPClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewHTTPReader(client, fmt.Sprintf("%s://%s", config.Backend, config.Path), opts)
 	case BackendKeyTFCloud:
 		return NewTFCloudReader(config.Path, opts), nil
 	case BackendKeyGS:
@@ -0,0 +1,66 @@
+package backend
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/pkg/errors"
+)
+
+// GSWriter streams written content to a Google Storage object, the object is created on close
+type GSWriter struct {
+	bucketName    string
+	path          string
+	writer        io.WriteCloser
+	storageClient *storage.Client
+}
+
+func NewGSWriter(path string) (*GSWriter, error) {
+	bucketPath := strings.Split(path, "/")
+	if len(bucketPath) < 2 || bucketPath[1] == "" {
+		return nil, errors.Errorf("Unable to parse Google Storage path: %s. Must be BUCKET_NAME/PATH/TO/OBJECT", path)
+	}
+
+	return &GSWriter{
+		bucketName: bucketPath[0],
+		path:       strings.Join(bucketPath[1:], "/"),
+	}, nil
+}
+
+func (s *GSWriter) Write(p []byte) (int, error) {
+	if s.writer == nil {
+		if err := s.open(); err != nil {
+			return 0, err
+		}
+	}
+	return s.writer.Write(p)
+}
+
+func (s *GSWriter) open() error {
+	if s.storageClient == nil {
+		client, err := storage.NewClient(context.Background())
+		if err != nil {
+			return err
+		}
+		s.storageClient = client
+	}
+	s.writer = s.storageClient.Bucket(s.bucketName).Object(s.path).NewWriter(context.Background())
+	return nil
+}
+
+func (s *GSWriter) Close() error {
+	// Make sure the object is created even if nothing was written
+	if s.writer == nil {
+		if err := s.open(); err != nil {
+			return err
+		}
+	}
+	// The client is closed whatever happened to the object, the first error is returned
+	err := s.writer.Close()
+	if closeErr := s.storageClient.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
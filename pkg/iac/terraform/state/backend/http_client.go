@@ -0,0 +1,80 @@
+package backend
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// NewHTTPClient returns the client used by the http(s) backend, presenting a client certificate, trusting
+// additional certificate authorities and authenticating with OAuth2 client credentials when configured
+func NewHTTPClient(opts options.HTTPBackendOptions) (*http.Client, error) {
+	tlsConfig, err := newTLSConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	if opts.OAuth2TokenURL == "" {
+		return client, nil
+	}
+
+	credentials := clientcredentials.Config{
+		ClientID:     opts.OAuth2ClientID,
+		ClientSecret: opts.OAuth2ClientSecret,
+		TokenURL:     opts.OAuth2TokenURL,
+		Scopes:       opts.OAuth2Scopes,
+	}
+	// The token endpoint is queried with the same TLS settings as the state server
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, client)
+	return credentials.Client(ctx), nil
+}
+
+func newTLSConfig(opts options.HTTPBackendOptions) (*tls.Config, error) {
+	if opts.ClientCertificate == "" && opts.ClientKey == "" && opts.CACertificate == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if opts.ClientCertificate != "" || opts.ClientKey != "" {
+		if opts.ClientCertificate == "" || opts.ClientKey == "" {
+			return nil, errors.New("both a client certificate and a client key are required for mutual TLS")
+		}
+		certificate, err := tls.LoadX509KeyPair(opts.ClientCertificate, opts.ClientKey)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to load client certificate")
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	if opts.CACertificate != "" {
+		pem, err := ioutil.ReadFile(opts.CACertificate)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read CA certificate")
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificate found in %s", opts.CACertificate)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,184 @@
+package backend
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestCertificate returns a certificate signed by parent, or a self signed CA when parent is nil
+func newTestCertificate(t *testing.T, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, isCA bool) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: "driftctl"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		BasicConstraintsValid: true,
+		IsCA:                  isCA,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certificate, key
+}
+
+func writePEM(t *testing.T, path, blockType string, bytes []byte) string {
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: bytes}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey := newTestCertificate(t, nil, nil, true)
+	serverCert, serverKey := newTestCertificate(t, ca, caKey, false)
+	clientCert, clientKey := newTestCertificate(t, ca, caKey, false)
+	clientKeyDER, err := x509.MarshalECPrivateKey(clientKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokenRequests := 0
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			tokenRequests++
+			user, password, _ := r.BasicAuth()
+			assert.Equal(t, "driftctl", user)
+			assert.Equal(t, "secret", password)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"access_token": "token", "token_type": "bearer", "expires_in": 3600}`))
+		case "/state":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			_, _ = w.Write([]byte("{}"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca)
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{serverCert.Raw}, PrivateKey: serverKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clientCAs,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	client, err := NewHTTPClient(options.HTTPBackendOptions{
+		ClientCertificate:  writePEM(t, filepath.Join(dir, "client.crt"), "CERTIFICATE", clientCert.Raw),
+		ClientKey:          writePEM(t, filepath.Join(dir, "client.key"), "EC PRIVATE KEY", clientKeyDER),
+		CACertificate:      writePEM(t, filepath.Join(dir, "ca.crt"), "CERTIFICATE", ca.Raw),
+		OAuth2TokenURL:     server.URL + "/token",
+		OAuth2ClientID:     "driftctl",
+		OAuth2ClientSecret: "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		res, err := client.Get(server.URL + "/state")
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(res.Body)
+		_ = res.Body.Close()
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "{}", string(body))
+	}
+	assert.Equal(t, 1, tokenRequests)
+
+	// Without the client certificate the handshake is refused by the server
+	client, err = NewHTTPClient(options.HTTPBackendOptions{CACertificate: filepath.Join(dir, "ca.crt")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Get(server.URL + "/state")
+	assert.Error(t, err)
+}
+
+func TestNewHTTPClient_InvalidOptions(t *testing.T) {
+	dir := t.TempDir()
+	notPEM := filepath.Join(dir, "ca.crt")
+	if err := os.WriteFile(notPEM, []byte("not a certificate"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opts options.HTTPBackendOptions
+		err  string
+	}{
+		{
+			name: "client certificate without key",
+			opts: options.HTTPBackendOptions{ClientCertificate: "client.crt"},
+			err:  "both a client certificate and a client key are required for mutual TLS",
+		},
+		{
+			name: "CA bundle without certificate",
+			opts: options.HTTPBackendOptions{CACertificate: notPEM},
+			err:  "no certificate found in " + notPEM,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewHTTPClient(tt.opts)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestOptions_HTTPClient_Concurrent(t *testing.T) {
+	opts := &Options{}
+
+	var wg sync.WaitGroup
+	clients := make([]*http.Client, 10)
+	for i := range clients {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			client, err := opts.HTTPClient()
+			assert.NoError(t, err)
+			clients[i] = client
+		}(i)
+	}
+	wg.Wait()
+
+	for _, client := range clients {
+		assert.Same(t, clients[0], client)
+	}
+}
@@ -1,5 +1,74 @@
 package options
 
+import (
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
 type AzureRMBackendOptions struct {
 	StorageAccount, StorageKey string
 }
+
+// AzureRM options are given per source in the query of the path,
+// e.g. tfstate+azurerm://container/key?storage_account_name=states&access_key_env=STATES_ACCESS_KEY
+const (
+	AzureRMStorageAccountOption = "storage_account_name"
+	AzureRMAccessKeyEnvOption   = "access_key_env"
+)
+
+// AzureRMOptionKeys lists the query options of the azurerm backend
+var AzureRMOptionKeys = []string{
+	AzureRMStorageAccountOption,
+	AzureRMAccessKeyEnvOption,
+}
+
+type AzureRMSourceOptions struct {
+	// StorageAccount overrides the storage account of the backend options
+	StorageAccount string
+	// AccessKeyEnv is the environment variable holding the access key of the storage account,
+	// keys are never given in the path as it is written in the scan output
+	AccessKeyEnv string
+}
+
+// ParseAzureRMSourceOptions reads azurerm backend options from the query of a path
+func ParseAzureRMSourceOptions(query url.Values) AzureRMSourceOptions {
+	return AzureRMSourceOptions{
+		StorageAccount: query.Get(AzureRMStorageAccountOption),
+		AccessKeyEnv:   query.Get(AzureRMAccessKeyEnvOption),
+	}
+}
+
+// Query returns the options as a query, to be appended to the paths of the states read with them
+func (o AzureRMSourceOptions) Query() url.Values {
+	query := url.Values{}
+	if o.StorageAccount != "" {
+		query.Set(AzureRMStorageAccountOption, o.StorageAccount)
+	}
+	if o.AccessKeyEnv != "" {
+		query.Set(AzureRMAccessKeyEnvOption, o.AccessKeyEnv)
+	}
+	return query
+}
+
+// Apply returns the backend options used to read the source. The key of the backend options only belongs to
+// its storage account, so reading from another storage account requires the AccessKeyEnv option.
+func (o AzureRMSourceOptions) Apply(opts AzureRMBackendOptions) (AzureRMBackendOptions, error) {
+	if o.AccessKeyEnv != "" {
+		key := os.Getenv(o.AccessKeyEnv)
+		if key == "" {
+			return opts, errors.Errorf("environment variable %s given by the %s option is not set", o.AccessKeyEnv, AzureRMAccessKeyEnvOption)
+		}
+		opts.StorageKey = key
+	} else if o.StorageAccount != "" && o.StorageAccount != opts.StorageAccount {
+		return opts, errors.Errorf(
+			"state is stored in the %s storage account, only the key of the %s storage account is configured, set the %s option to the environment variable holding its access key",
+			o.StorageAccount, opts.StorageAccount, AzureRMAccessKeyEnvOption,
+		)
+	}
+	if o.StorageAccount != "" {
+		opts.StorageAccount = o.StorageAccount
+	}
+	return opts, nil
+}
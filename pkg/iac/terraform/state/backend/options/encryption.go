@@ -0,0 +1,9 @@
+package options
+
+// StateEncryptionOptions holds the key material used to read OpenTofu client-side encrypted states
+type StateEncryptionOptions struct {
+	// Passphrase is used by pbkdf2 key providers that don't set one in the encryption configuration
+	Passphrase string
+	// ConfigFile is an OpenTofu encryption configuration, the TF_ENCRYPTION environment variable is used when empty
+	ConfigFile string
+}
@@ -0,0 +1,13 @@
+package options
+
+// HTTPBackendOptions configures the TLS and OAuth2 authentication of the http(s) state backend
+type HTTPBackendOptions struct {
+	// ClientCertificate and ClientKey are the PEM files used for mutual TLS
+	ClientCertificate, ClientKey string
+	// CACertificate is a PEM bundle of certificate authorities trusted in addition to the system ones
+	CACertificate string
+	// OAuth2TokenURL enables the OAuth2 client credentials flow, tokens are refreshed when they expire
+	OAuth2TokenURL                     string
+	OAuth2ClientID, OAuth2ClientSecret string
+	OAuth2Scopes                       []string
+}
@@ -0,0 +1,32 @@
+package options
+
+import (
+	"net/url"
+	"strings"
+)
+
+// SplitQuery extracts options given after a question mark at the end of a path, e.g. bucket/key?workspaces=*.
+// Paths are returned untouched when the query contains unknown options, as a question mark is also a glob pattern.
+func SplitQuery(path string, allowedKeys ...string) (string, url.Values) {
+	i := strings.LastIndex(path, "?")
+	if i < 0 {
+		return path, url.Values{}
+	}
+	query, err := url.ParseQuery(path[i+1:])
+	if err != nil || len(query) == 0 {
+		return path, url.Values{}
+	}
+	for key := range query {
+		allowed := false
+		for _, allowedKey := range allowedKeys {
+			if key == allowedKey {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return path, url.Values{}
+		}
+	}
+	return path[:i], query
+}
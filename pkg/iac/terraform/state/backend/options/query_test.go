@@ -0,0 +1,49 @@
+package options
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		wantPath  string
+		wantQuery url.Values
+	}{
+		{
+			name:      "no query",
+			path:      "bucket/terraform.tfstate",
+			wantPath:  "bucket/terraform.tfstate",
+			wantQuery: url.Values{},
+		},
+		{
+			name:      "workspaces query",
+			path:      "bucket/terraform.tfstate?workspaces=prod-*",
+			wantPath:  "bucket/terraform.tfstate",
+			wantQuery: url.Values{"workspaces": []string{"prod-*"}},
+		},
+		{
+			name:      "question mark glob",
+			path:      "bucket/states/state?.tfstate",
+			wantPath:  "bucket/states/state?.tfstate",
+			wantQuery: url.Values{},
+		},
+		{
+			name:      "unknown option",
+			path:      "bucket/terraform.tfstate?foo=bar",
+			wantPath:  "bucket/terraform.tfstate?foo=bar",
+			wantQuery: url.Values{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, query := SplitQuery(tt.path, "workspaces")
+			assert.Equal(t, tt.wantPath, path)
+			assert.Equal(t, tt.wantQuery, query)
+		})
+	}
+}
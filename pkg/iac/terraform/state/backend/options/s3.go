@@ -0,0 +1,83 @@
+package options
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// S3 options are given per source in the query of the path,
+// e.g. tfstate+s3://bucket/key?endpoint=https://minio.example.com&force_path_style=true
+const (
+	S3EndpointOption          = "endpoint"
+	S3ForcePathStyleOption    = "force_path_style"
+	S3RegionOption            = "region"
+	S3RoleARNOption           = "role_arn"
+	S3ExternalIDOption        = "external_id"
+	S3SSECustomerKeyEnvOption = "sse_customer_key_env"
+)
+
+// S3OptionKeys lists the query options of the S3 backend
+var S3OptionKeys = []string{
+	S3EndpointOption,
+	S3ForcePathStyleOption,
+	S3RegionOption,
+	S3RoleARNOption,
+	S3ExternalIDOption,
+	S3SSECustomerKeyEnvOption,
+}
+
+type S3BackendOptions struct {
+	// Endpoint is the URL of an S3 compatible storage, e.g. MinIO or Ceph
+	Endpoint       string
+	ForcePathStyle bool
+	// Region overrides the region of the session
+	Region string
+	// RoleARN is assumed, with the optional ExternalID, to read states stored in another account
+	RoleARN, ExternalID string
+	// SSECustomerKeyEnv is the environment variable holding the base64 encoded SSE-C key of the states,
+	// keys are never given in the path as it is written in the scan output
+	SSECustomerKeyEnv string
+}
+
+// ParseS3Options reads S3 backend options from the query of a path
+func ParseS3Options(query url.Values) (S3BackendOptions, error) {
+	opts := S3BackendOptions{
+		Endpoint:          query.Get(S3EndpointOption),
+		Region:            query.Get(S3RegionOption),
+		RoleARN:           query.Get(S3RoleARNOption),
+		ExternalID:        query.Get(S3ExternalIDOption),
+		SSECustomerKeyEnv: query.Get(S3SSECustomerKeyEnvOption),
+	}
+	if value := query.Get(S3ForcePathStyleOption); value != "" {
+		forcePathStyle, err := strconv.ParseBool(value)
+		if err != nil {
+			return opts, errors.Errorf("invalid %s option %s, expected true or false", S3ForcePathStyleOption, value)
+		}
+		opts.ForcePathStyle = forcePathStyle
+	}
+	if opts.ExternalID != "" && opts.RoleARN == "" {
+		return opts, errors.Errorf("%s option requires the %s option", S3ExternalIDOption, S3RoleARNOption)
+	}
+	return opts, nil
+}
+
+// Query returns the options as a query, to be appended to the paths of the states read with them
+func (o S3BackendOptions) Query() url.Values {
+	query := url.Values{}
+	set := func(key, value string) {
+		if value != "" {
+			query.Set(key, value)
+		}
+	}
+	set(S3EndpointOption, o.Endpoint)
+	if o.ForcePathStyle {
+		query.Set(S3ForcePathStyleOption, "true")
+	}
+	set(S3RegionOption, o.Region)
+	set(S3RoleARNOption, o.RoleARN)
+	set(S3ExternalIDOption, o.ExternalID)
+	set(S3SSECustomerKeyEnvOption, o.SSECustomerKeyEnv)
+	return query
+}
@@ -1,12 +1,17 @@
 package backend
 
 import (
+	"encoding/base64"
 	"io"
+	"os"
 	"strings"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/pkg/errors"
 	"github.com/snyk/driftctl/pkg/envproxy"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
 
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
@@ -23,6 +28,11 @@ type S3Backend struct {
 func NewS3Reader(path string) (*S3Backend, error) {
 
 	backend := S3Backend{}
+	path, query := options.SplitQuery(path, options.S3OptionKeys...)
+	opts, err := options.ParseS3Options(query)
+	if err != nil {
+		return nil, err
+	}
 	bucketPath := strings.Split(path, "/")
 	if len(bucketPath) < 2 {
 		return nil, errors.Errorf("Unable to parse S3 path: %s. Must be BUCKET_NAME/PATH/TO/OBJECT", path)
@@ -34,14 +44,64 @@ func NewS3Reader(path string) (*S3Backend, error) {
 		Key:    &key,
 		Bucket: &bucket,
 	}
+	if opts.SSECustomerKeyEnv != "" {
+		sseKey, err := readSSECustomerKey(opts.SSECustomerKeyEnv)
+		if err != nil {
+			return nil, err
+		}
+		backend.input.SSECustomerAlgorithm = aws.String(s3.ServerSideEncryptionAes256)
+		backend.input.SSECustomerKey = &sseKey
+	}
+	backend.S3Client = NewS3Client(opts)
+	return &backend, nil
+}
+
+// NewS3Client creates a S3 client using DCTL_S3_ prefixed environment variables
+// in place of AWS_ ones when they are set, configured with the options of the source
+func NewS3Client(opts options.S3BackendOptions) *s3.S3 {
 	envProxy := envproxy.NewEnvProxy("DCTL_S3_", "AWS_")
 	envProxy.Apply()
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
+	sessionOptions := session.Options{
 		SharedConfigState: session.SharedConfigEnable,
-	}))
+	}
+	if opts.Region != "" {
+		sessionOptions.Config.Region = aws.String(opts.Region)
+	}
+	sess := session.Must(session.NewSessionWithOptions(sessionOptions))
 	envProxy.Restore()
-	backend.S3Client = s3.New(sess)
-	return &backend, nil
+
+	config := aws.NewConfig()
+	if opts.Endpoint != "" {
+		config = config.WithEndpoint(opts.Endpoint)
+	}
+	if opts.ForcePathStyle {
+		config = config.WithS3ForcePathStyle(true)
+	}
+	if opts.RoleARN != "" {
+		config = config.WithCredentials(stscreds.NewCredentials(sess, opts.RoleARN, func(p *stscreds.AssumeRoleProvider) {
+			p.RoleSessionName = "driftctl"
+			if opts.ExternalID != "" {
+				p.ExternalID = aws.String(opts.ExternalID)
+			}
+		}))
+	}
+	return s3.New(sess, config)
+}
+
+// readSSECustomerKey returns the SSE-C key stored base64 encoded in an environment variable, as terraform expects it
+func readSSECustomerKey(env string) (string, error) {
+	encoded, exist := os.LookupEnv(env)
+	if !exist || encoded == "" {
+		return "", errors.Errorf("SSE-C key environment variable %s is not set", env)
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to decode SSE-C key from %s", env)
+	}
+	if len(key) != 32 {
+		return "", errors.Errorf("SSE-C key from %s must be 256 bits long", env)
+	}
+	return string(key), nil
 }
 
 func (s *S3Backend) Read(p []byte) (n int, err error) {
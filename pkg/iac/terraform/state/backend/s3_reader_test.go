@@ -35,6 +35,22 @@ func TestNewS3ReaderInvalid(t *testing.T) {
 			want:    nil,
 			wantErr: fmt.Errorf("Unable to parse S3 path: foobar. Must be BUCKET_NAME/PATH/TO/OBJECT"),
 		},
+		{
+			name: "invalid path style option",
+			args: args{
+				path: "sample_bucket/state.tfstate?force_path_style=maybe",
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("invalid force_path_style option maybe, expected true or false"),
+		},
+		{
+			name: "missing SSE-C key",
+			args: args{
+				path: "sample_bucket/state.tfstate?sse_customer_key_env=DCTL_TEST_UNSET_SSE_KEY",
+			},
+			want:    nil,
+			wantErr: fmt.Errorf("SSE-C key environment variable DCTL_TEST_UNSET_SSE_KEY is not set"),
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -67,6 +83,25 @@ func TestNewS3Reader(t *testing.T) {
 	)
 }
 
+func TestNewS3ReaderWithOptions(t *testing.T) {
+	t.Setenv("AWS_DEFAULT_REGION", "us-east-1")
+	t.Setenv("DCTL_TEST_SSE_KEY", "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+
+	reader, err := NewS3Reader("sample_bucket/path/to/state.tfstate?endpoint=https://minio.example.com&force_path_style=true&region=eu-north-1&sse_customer_key_env=DCTL_TEST_SSE_KEY")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "path/to/state.tfstate", *reader.input.Key)
+	assert.Equal(t, "sample_bucket", *reader.input.Bucket)
+	assert.Equal(t, "AES256", aws.StringValue(reader.input.SSECustomerAlgorithm))
+	assert.Equal(t, "0123456789abcdef0123456789abcdef", aws.StringValue(reader.input.SSECustomerKey))
+	client := reader.S3Client.(*s3.S3)
+	assert.Equal(t, "https://minio.example.com", client.Endpoint)
+	assert.True(t, aws.BoolValue(client.Config.S3ForcePathStyle))
+	assert.Equal(t, "eu-north-1", aws.StringValue(client.Config.Region))
+}
+
 func TestNewS3ReaderWithEnvProxy(t *testing.T) {
 	assert := assert.New(t)
 	os.Setenv("AWS_DEFAULT_REGION", "us-east-1")
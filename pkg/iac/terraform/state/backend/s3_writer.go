@@ -0,0 +1,56 @@
+package backend
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+)
+
+// S3Writer buffers written content and uploads it to a S3 object on close
+type S3Writer struct {
+	bucket   string
+	key      string
+	buf      bytes.Buffer
+	S3Client s3iface.S3API
+}
+
+func NewS3Writer(path string) (*S3Writer, error) {
+	bucketPath := strings.Split(path, "/")
+	if len(bucketPath) < 2 || bucketPath[1] == "" {
+		return nil, errors.Errorf("Unable to parse S3 path: %s. Must be BUCKET_NAME/PATH/TO/OBJECT", path)
+	}
+
+	return &S3Writer{
+		bucket:   bucketPath[0],
+		key:      strings.Join(bucketPath[1:], "/"),
+		S3Client: NewS3Client(options.S3BackendOptions{}),
+	}, nil
+}
+
+func (s *S3Writer) Write(p []byte) (int, error) {
+	return s.buf.Write(p)
+}
+
+func (s *S3Writer) Close() error {
+	_, err := s.S3Client.PutObject(&s3.PutObjectInput{
+		Bucket: &s.bucket,
+		Key:    &s.key,
+		Body:   bytes.NewReader(s.buf.Bytes()),
+	})
+	if err != nil {
+		if requestFailure, ok := err.(s3.RequestFailure); ok {
+			return errors.Errorf(
+				"Error writing '%s' to s3 bucket '%s': %s",
+				s.key,
+				s.bucket,
+				requestFailure.Message(),
+			)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package backend
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/s3"
+	awstest "github.com/snyk/driftctl/test/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestNewS3WriterInvalid(t *testing.T) {
+	_, err := NewS3Writer("foobar/")
+	assert.EqualError(t, err, "Unable to parse S3 path: foobar/. Must be BUCKET_NAME/PATH/TO/OBJECT")
+}
+
+func TestS3Writer_Close(t *testing.T) {
+	fakeS3 := &awstest.MockFakeS3{}
+	fakeS3.On("PutObject", mock.MatchedBy(func(input *s3.PutObjectInput) bool {
+		body, _ := ioutil.ReadAll(input.Body)
+		return *input.Bucket == "foobar" && *input.Key == "drift/report.json" && string(body) == `{"foo":"bar"}`
+	})).Return(&s3.PutObjectOutput{}, nil).Once()
+
+	writer, err := NewS3Writer("foobar/drift/report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.S3Client = fakeS3
+
+	_, err = fmt.Fprint(writer, `{"foo":"bar"}`)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Close())
+	fakeS3.AssertExpectations(t)
+}
+
+func TestS3Writer_CloseWithError(t *testing.T) {
+	fakeS3 := &awstest.MockFakeS3{}
+	fakeErr := &awstest.MockFakeRequestFailure{}
+	fakeErr.On("Message").Return("Access Denied")
+	fakeS3.On("PutObject", mock.Anything).Return(nil, fakeErr)
+
+	writer, err := NewS3Writer("foobar/drift/report.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer.S3Client = fakeS3
+
+	assert.EqualError(t, writer.Close(), "Error writing 'drift/report.json' to s3 bucket 'foobar': Access Denied")
+}
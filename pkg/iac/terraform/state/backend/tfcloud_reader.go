@@ -38,8 +38,8 @@ func NewTFCloudReader(workspacePath string, opts *Options) *TFCloudBackend {
 	return &TFCloudBackend{opts: opts, workspacePath: workspacePath}
 }
 
-func (t *TFCloudBackend) getToken() (string, error) {
-	token := t.opts.TFCloudToken
+func getTFCloudToken(opts *Options) (string, error) {
+	token := opts.TFCloudToken
 	if token == "" {
 		tfConfigFile, err := getTerraformConfigFile()
 		if err != nil {
@@ -53,7 +53,7 @@ func (t *TFCloudBackend) getToken() (string, error) {
 		defer file.Close()
 		reader := NewTFCloudConfigReader(file)
 
-		u, err := url.Parse(t.opts.TFCloudEndpoint)
+		u, err := url.Parse(opts.TFCloudEndpoint)
 		if err != nil {
 			return "", err
 		}
@@ -62,6 +62,20 @@ func (t *TFCloudBackend) getToken() (string, error) {
 	return token, nil
 }
 
+// NewTFCloudClient returns a terraform cloud client authenticated with the token of the options,
+// or with the token found in the terraform CLI configuration
+func NewTFCloudClient(opts *Options) (*tfe.Client, error) {
+	token, err := getTFCloudToken(opts)
+	if err != nil {
+		return nil, err
+	}
+	config := &tfe.Config{
+		Token:   token,
+		Address: opts.TFCloudEndpoint,
+	}
+	return tfe.NewClient(config)
+}
+
 // A regular expression used to validate string workspace ID patterns.
 var reStringID = regexp.MustCompile(`^ws-[a-zA-Z0-9\-\._]+$`)
 
@@ -86,15 +100,7 @@ func (t *TFCloudBackend) getWorkspaceId() (string, error) {
 }
 
 func (t *TFCloudBackend) initTFEClient() error {
-	token, err := t.getToken()
-	if err != nil {
-		return err
-	}
-	config := &tfe.Config{
-		Token:   token,
-		Address: t.opts.TFCloudEndpoint,
-	}
-	tfcClient, err := tfe.NewClient(config)
+	tfcClient, err := NewTFCloudClient(t.opts)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,155 @@
+package encryption
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// encryptionConfigEnv holds an OpenTofu encryption configuration, as OpenTofu reads it
+const encryptionConfigEnv = "TF_ENCRYPTION"
+
+var encryptionFileSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "terraform"},
+		{Type: "key_provider", LabelNames: []string{"type", "name"}},
+	},
+}
+
+var terraformBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "encryption"},
+	},
+}
+
+var encryptionBlockSchema = &hcl.BodySchema{
+	Blocks: []hcl.BlockHeaderSchema{
+		{Type: "key_provider", LabelNames: []string{"type", "name"}},
+	},
+}
+
+// keyProviderSchema lists the settings of the key providers driftctl supports, other settings of the OpenTofu
+// encryption configuration, e.g. methods, are only needed to encrypt states
+var keyProviderSchema = &hcl.BodySchema{
+	Attributes: []hcl.AttributeSchema{
+		{Name: "passphrase"},
+		{Name: "kms_key_id"},
+		{Name: "region"},
+		{Name: "encrypted_metadata_alias"},
+	},
+}
+
+// readKeyProviderSettings returns the literal settings of a key provider, settings referencing variables can't be
+// evaluated and are left empty, e.g. a passphrase is then given with a flag
+func readKeyProviderSettings(body hcl.Body) (map[string]string, error) {
+	content, _, diags := body.PartialContent(keyProviderSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	settings := make(map[string]string, len(content.Attributes))
+	for name, attr := range content.Attributes {
+		value, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() || !value.IsKnown() || value.IsNull() || value.Type() != cty.String {
+			continue
+		}
+		settings[name] = value.AsString()
+	}
+	return settings, nil
+}
+
+// readKeyProviders reads the key providers of an OpenTofu encryption configuration, either a terraform block
+// with an encryption block or the content of an encryption block as given in TF_ENCRYPTION
+func readKeyProviders(opts options.StateEncryptionOptions) (map[string]keyProvider, error) {
+	name := opts.ConfigFile
+	var content []byte
+	if opts.ConfigFile != "" {
+		var err error
+		content, err = ioutil.ReadFile(opts.ConfigFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to read encryption configuration")
+		}
+	} else {
+		name = encryptionConfigEnv
+		content = []byte(os.Getenv(encryptionConfigEnv))
+	}
+
+	keyProviders := make(map[string]keyProvider)
+	if strings.TrimSpace(string(content)) == "" {
+		return keyProviders, nil
+	}
+
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diags hcl.Diagnostics
+	if strings.HasPrefix(strings.TrimSpace(string(content)), "{") {
+		file, diags = parser.ParseJSON(content, name)
+	} else {
+		file, diags = parser.ParseHCL(content, name)
+	}
+	if diags.HasErrors() {
+		return nil, errors.Wrap(diags, "unable to parse encryption configuration")
+	}
+
+	blocks, err := keyProviderBlocks(file.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to parse encryption configuration")
+	}
+	for _, block := range blocks {
+		settings, err := readKeyProviderSettings(block.Body)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse encryption configuration")
+		}
+
+		var provider keyProvider
+		switch block.Labels[0] {
+		case pbkdf2KeyProviderType:
+			passphrase := settings["passphrase"]
+			if passphrase == "" {
+				passphrase = opts.Passphrase
+			}
+			provider = &pbkdf2KeyProvider{passphrase: passphrase}
+		case awsKMSKeyProviderType:
+			provider = &awsKMSKeyProvider{keyID: settings["kms_key_id"], region: settings["region"]}
+		default:
+			// Unsupported key providers only fail when a state needs them
+			continue
+		}
+
+		metaKey := fmt.Sprintf("key_provider.%s.%s", block.Labels[0], block.Labels[1])
+		if settings["encrypted_metadata_alias"] != "" {
+			metaKey = settings["encrypted_metadata_alias"]
+		}
+		keyProviders[metaKey] = provider
+	}
+
+	return keyProviders, nil
+}
+
+func keyProviderBlocks(body hcl.Body) (hcl.Blocks, error) {
+	content, _, diags := body.PartialContent(encryptionFileSchema)
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	blocks := content.Blocks.OfType("key_provider")
+	for _, terraformBlock := range content.Blocks.OfType("terraform") {
+		terraformContent, _, diags := terraformBlock.Body.PartialContent(terraformBlockSchema)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		for _, encryptionBlock := range terraformContent.Blocks {
+			encryptionContent, _, diags := encryptionBlock.Body.PartialContent(encryptionBlockSchema)
+			if diags.HasErrors() {
+				return nil, diags
+			}
+			blocks = append(blocks, encryptionContent.Blocks...)
+		}
+	}
+	return blocks, nil
+}
@@ -0,0 +1,129 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+)
+
+// supportedVersion is the version of the OpenTofu encrypted state envelope
+const supportedVersion = "v0"
+
+// envelope is an OpenTofu encrypted state, the metadata of every key provider used to encrypt the state is stored
+// alongside the AES-GCM encrypted statefile
+type envelope struct {
+	Meta    map[string][]byte `json:"meta"`
+	Data    []byte            `json:"encrypted_data"`
+	Version string            `json:"encryption_version"`
+}
+
+// IsEncrypted tells whether the content is an OpenTofu encrypted state rather than a plain statefile
+func IsEncrypted(content []byte) bool {
+	var doc struct {
+		Version *string `json:"encryption_version"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+	return doc.Version != nil
+}
+
+// Decrypter decrypts OpenTofu encrypted states with the key providers of an OpenTofu encryption configuration,
+// or a passphrase for pbkdf2 key providers
+type Decrypter struct {
+	passphrase   string
+	keyProviders map[string]keyProvider
+}
+
+func NewDecrypter(opts options.StateEncryptionOptions) (*Decrypter, error) {
+	keyProviders, err := readKeyProviders(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Decrypter{
+		passphrase:   opts.Passphrase,
+		keyProviders: keyProviders,
+	}, nil
+}
+
+// Decrypt returns the statefile of an encrypted state. Keys of every key provider found in the state metadata are
+// tried in turn, as OpenTofu stores the metadata of the fallback key provider too when rotating keys.
+func (d *Decrypter) Decrypt(content []byte) ([]byte, error) {
+	var state envelope
+	if err := json.Unmarshal(content, &state); err != nil {
+		return nil, errors.Wrap(err, "unable to read encrypted state")
+	}
+	if state.Version != supportedVersion {
+		return nil, errors.Errorf("unsupported state encryption version %s", state.Version)
+	}
+
+	names := make([]string, 0, len(state.Meta))
+	for name := range state.Meta {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []string
+	for _, name := range names {
+		provider, err := d.keyProvider(name)
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		key, err := provider.decryptionKey(state.Meta[name])
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "unable to get the key of %s", name).Error())
+			continue
+		}
+		plaintext, err := decryptAESGCM(key, state.Data)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "unable to decrypt state with %s", name).Error())
+			continue
+		}
+		logrus.WithField("key_provider", name).Debug("Decrypted state")
+		return plaintext, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, errors.New("unable to decrypt state, no key provider metadata found")
+	}
+	return nil, errors.Errorf("unable to decrypt state: %s", strings.Join(errs, ", "))
+}
+
+// keyProvider returns the key provider of a metadata key, key_provider.<type>.<name> or a custom metadata alias
+func (d *Decrypter) keyProvider(name string) (keyProvider, error) {
+	if provider, exist := d.keyProviders[name]; exist {
+		return provider, nil
+	}
+	parts := strings.SplitN(name, ".", 3)
+	if len(parts) == 3 && parts[0] == "key_provider" && parts[1] == pbkdf2KeyProviderType && d.passphrase != "" {
+		return &pbkdf2KeyProvider{passphrase: d.passphrase}, nil
+	}
+	return nil, errors.Errorf(
+		"no key material for %s, use --tf-state-passphrase for pbkdf2 key providers or --tf-encryption-config",
+		name,
+	)
+}
+
+func decryptAESGCM(key, data []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("encrypted data is too short")
+	}
+	// The nonce is written before the ciphertext
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
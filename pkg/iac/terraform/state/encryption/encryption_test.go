@@ -0,0 +1,160 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+	awstest "github.com/snyk/driftctl/test/aws"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const testState = `{"version": 4, "serial": 1, "lineage": "", "outputs": {}, "resources": []}`
+
+// encryptState encrypts a state the way OpenTofu does, with AES-GCM and the nonce written before the ciphertext
+func encryptState(t *testing.T, key []byte, meta map[string][]byte) []byte {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	content, err := json.Marshal(envelope{
+		Meta:    meta,
+		Data:    append(nonce, gcm.Seal(nil, nonce, []byte(testState), nil)...),
+		Version: "v0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func pbkdf2State(t *testing.T, metaKey, passphrase string) []byte {
+	salt := []byte("0123456789abcdef0123456789abcdef")
+	meta, _ := json.Marshal(pbkdf2Metadata{Salt: salt, Iterations: 1000, HashFunction: "sha256", KeyLength: 32})
+	key := pbkdf2.Key([]byte(passphrase), salt, 1000, 32, sha256.New)
+	return encryptState(t, key, map[string][]byte{metaKey: meta})
+}
+
+func TestIsEncrypted(t *testing.T) {
+	assert.True(t, IsEncrypted(pbkdf2State(t, "key_provider.pbkdf2.state", "passphrase")))
+	assert.False(t, IsEncrypted([]byte(testState)))
+	assert.False(t, IsEncrypted([]byte("not json")))
+}
+
+func TestDecrypter_Decrypt(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    options.StateEncryptionOptions
+		env     string
+		config  string
+		content []byte
+		err     string
+	}{
+		{
+			name:    "passphrase",
+			opts:    options.StateEncryptionOptions{Passphrase: "passphrase"},
+			content: pbkdf2State(t, "key_provider.pbkdf2.state", "passphrase"),
+		},
+		{
+			name:    "wrong passphrase",
+			opts:    options.StateEncryptionOptions{Passphrase: "wrong"},
+			content: pbkdf2State(t, "key_provider.pbkdf2.state", "passphrase"),
+			err:     "unable to decrypt state: unable to decrypt state with key_provider.pbkdf2.state: cipher: message authentication failed",
+		},
+		{
+			name:    "no key material",
+			content: pbkdf2State(t, "key_provider.pbkdf2.state", "passphrase"),
+			err:     "unable to decrypt state: no key material for key_provider.pbkdf2.state, use --tf-state-passphrase for pbkdf2 key providers or --tf-encryption-config",
+		},
+		{
+			name: "terraform encryption block",
+			config: `terraform {
+  encryption {
+    key_provider "pbkdf2" "old" {
+      passphrase = "old passphrase"
+    }
+    key_provider "pbkdf2" "new" {
+      passphrase = var.passphrase
+    }
+    method "aes_gcm" "new" {
+      keys = key_provider.pbkdf2.new
+    }
+    state {
+      method = method.aes_gcm.new
+    }
+  }
+}`,
+			opts:    options.StateEncryptionOptions{Passphrase: "passphrase"},
+			content: pbkdf2State(t, "key_provider.pbkdf2.new", "passphrase"),
+		},
+		{
+			name: "TF_ENCRYPTION with metadata alias",
+			env: `key_provider "pbkdf2" "state" {
+  passphrase               = "passphrase"
+  encrypted_metadata_alias = "states"
+}`,
+			content: pbkdf2State(t, "states", "passphrase"),
+		},
+		{
+			name:    "unsupported version",
+			content: []byte(`{"meta": {}, "encrypted_data": "", "encryption_version": "v9"}`),
+			err:     "unsupported state encryption version v9",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(encryptionConfigEnv, tt.env)
+			if tt.config != "" {
+				tt.opts.ConfigFile = filepath.Join(t.TempDir(), "encryption.tf")
+				if err := os.WriteFile(tt.opts.ConfigFile, []byte(tt.config), 0600); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			decrypter, err := NewDecrypter(tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := decrypter.Decrypt(tt.content)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testState, string(got))
+		})
+	}
+}
+
+func TestDecrypter_DecryptAWSKMS(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	meta, _ := json.Marshal(awsKMSMetadata{CiphertextBlob: []byte("encrypted data key")})
+	content := encryptState(t, key, map[string][]byte{"key_provider.aws_kms.state": meta})
+
+	client := &awstest.MockFakeKMS{}
+	client.On("Decrypt", &kms.DecryptInput{
+		CiphertextBlob: []byte("encrypted data key"),
+		KeyId:          aws.String("alias/states"),
+	}).Return(&kms.DecryptOutput{Plaintext: key}, nil)
+
+	decrypter := &Decrypter{keyProviders: map[string]keyProvider{
+		"key_provider.aws_kms.state": &awsKMSKeyProvider{keyID: "alias/states", client: client},
+	}}
+	got, err := decrypter.Decrypt(content)
+	assert.NoError(t, err)
+	assert.Equal(t, testState, string(got))
+	client.AssertExpectations(t)
+}
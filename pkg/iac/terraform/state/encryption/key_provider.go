@@ -0,0 +1,104 @@
+package encryption
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"hash"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	pbkdf2KeyProviderType = "pbkdf2"
+	awsKMSKeyProviderType = "aws_kms"
+)
+
+// keyProvider returns the key used to encrypt a state from the metadata OpenTofu stored for it
+type keyProvider interface {
+	decryptionKey(meta []byte) ([]byte, error)
+}
+
+// pbkdf2KeyProvider derives keys from a passphrase with the salt and parameters stored in the state metadata
+type pbkdf2KeyProvider struct {
+	passphrase string
+}
+
+type pbkdf2Metadata struct {
+	Salt         []byte `json:"salt"`
+	Iterations   int    `json:"iterations"`
+	HashFunction string `json:"hash_function"`
+	KeyLength    int    `json:"key_length"`
+}
+
+func (p *pbkdf2KeyProvider) decryptionKey(meta []byte) ([]byte, error) {
+	var metadata pbkdf2Metadata
+	if err := json.Unmarshal(meta, &metadata); err != nil {
+		return nil, errors.Wrap(err, "invalid pbkdf2 metadata")
+	}
+	if len(metadata.Salt) == 0 || metadata.Iterations <= 0 || metadata.KeyLength <= 0 {
+		return nil, errors.New("invalid pbkdf2 metadata, salt, iterations and key length are required")
+	}
+
+	var hashFunc func() hash.Hash
+	switch metadata.HashFunction {
+	case "sha256":
+		hashFunc = sha256.New
+	case "sha512":
+		hashFunc = sha512.New
+	default:
+		return nil, errors.Errorf("unsupported pbkdf2 hash function %s", metadata.HashFunction)
+	}
+
+	return pbkdf2.Key([]byte(p.passphrase), metadata.Salt, metadata.Iterations, metadata.KeyLength, hashFunc), nil
+}
+
+// awsKMSKeyProvider decrypts the data key OpenTofu generated with AWS KMS and stored in the state metadata
+type awsKMSKeyProvider struct {
+	keyID  string
+	region string
+	client kmsiface.KMSAPI
+}
+
+type awsKMSMetadata struct {
+	CiphertextBlob []byte `json:"ciphertext_blob"`
+}
+
+func (p *awsKMSKeyProvider) decryptionKey(meta []byte) ([]byte, error) {
+	var metadata awsKMSMetadata
+	if err := json.Unmarshal(meta, &metadata); err != nil {
+		return nil, errors.Wrap(err, "invalid aws_kms metadata")
+	}
+	if len(metadata.CiphertextBlob) == 0 {
+		return nil, errors.New("invalid aws_kms metadata, the encrypted data key is missing")
+	}
+
+	if p.client == nil {
+		sess, err := session.NewSessionWithOptions(session.Options{
+			SharedConfigState: session.SharedConfigEnable,
+		})
+		if err != nil {
+			return nil, err
+		}
+		config := aws.NewConfig()
+		if p.region != "" {
+			config = config.WithRegion(p.region)
+		}
+		p.client = kms.New(sess, config)
+	}
+
+	input := &kms.DecryptInput{CiphertextBlob: metadata.CiphertextBlob}
+	if p.keyID != "" {
+		input.KeyId = aws.String(p.keyID)
+	}
+	output, err := p.client.Decrypt(input)
+	if err != nil {
+		return nil, err
+	}
+	return output.Plaintext, nil
+}
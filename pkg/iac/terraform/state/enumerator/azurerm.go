@@ -14,18 +14,31 @@ import (
 
 type AzureRMEnumerator struct {
 	containerName, objectPath string
+	workspacePattern          string
 	containerClient           azblob.ContainerClient
 	origin                    string
+	options                   options.AzureRMSourceOptions
+	workspaces
 }
 
 func NewAzureRMEnumerator(config config.SupplierConfig, opts options.AzureRMBackendOptions) (*AzureRMEnumerator, error) {
-	splitPath := strings.Split(config.Path, "/")
+	path, query := options.SplitQuery(config.Path, append([]string{workspacesQueryParam}, options.AzureRMOptionKeys...)...)
+	splitPath := strings.Split(path, "/")
 	if len(splitPath) < 2 || splitPath[1] == "" {
 		return nil, errors.Errorf("Unable to parse azurerm backend storage splitPath: %s. Must be CONTAINER/PATH/TO/OBJECT", config.Path)
 	}
 	containerName := splitPath[0]
 	objectPath := strings.Join(splitPath[1:], "/")
+	workspacePattern, err := workspacePattern(objectPath, query)
+	if err != nil {
+		return nil, err
+	}
 
+	sourceOptions := options.ParseAzureRMSourceOptions(query)
+	opts, err = sourceOptions.Apply(opts)
+	if err != nil {
+		return nil, err
+	}
 	if opts.StorageKey == "" || opts.StorageAccount == "" {
 		return nil, errors.New("AZURE_STORAGE_ACCOUNT and AZURE_STORAGE_KEY should be defined to be able to read state from azure backend")
 	}
@@ -46,10 +59,12 @@ func NewAzureRMEnumerator(config config.SupplierConfig, opts options.AzureRMBack
 		return nil, err
 	}
 	return &AzureRMEnumerator{
-		containerName:   containerName,
-		objectPath:      objectPath,
-		containerClient: container,
-		origin:          config.String(),
+		containerName:    containerName,
+		objectPath:       objectPath,
+		workspacePattern: workspacePattern,
+		containerClient:  container,
+		origin:           config.String(),
+		options:          sourceOptions,
 	}, nil
 }
 
@@ -57,13 +72,37 @@ func (s *AzureRMEnumerator) Origin() string {
 	return s.origin
 }
 
+// Enumerate returns the paths of the states, followed by the azurerm options of the source so they are read with them
 func (s *AzureRMEnumerator) Enumerate() ([]string, error) {
+	files, err := s.enumerate()
+	query := s.options.Query().Encode()
+	if err != nil || query == "" {
+		return files, err
+	}
+
+	for i, file := range files {
+		files[i] = fmt.Sprintf("%s?%s", file, query)
+		if s.workspaces != nil {
+			s.workspaces[files[i]] = s.workspaces[file]
+			delete(s.workspaces, file)
+		}
+	}
+	return files, nil
+}
+
+func (s *AzureRMEnumerator) enumerate() ([]string, error) {
 
 	// prefix should contain everything that does not have a glob pattern should be the glob matcher string
 	prefix, pattern := GlobS3(s.objectPath)
 	fullPattern := strings.Join([]string{prefix, pattern}, "/")
 	fullPattern = strings.Trim(fullPattern, "/")
 
+	// The azurerm backend stores the default workspace state at the key and other workspace states at <key>env:<workspace>
+	if s.workspacePattern != "" {
+		s.workspaces = make(workspaces)
+		prefix = s.objectPath
+	}
+
 	pager := s.containerClient.ListBlobsFlat(&azblob.ContainerListBlobFlatSegmentOptions{
 		Prefix: &prefix,
 	})
@@ -75,6 +114,14 @@ func (s *AzureRMEnumerator) Enumerate() ([]string, error) {
 			if *v.Properties.ContentLength == 0 {
 				continue
 			}
+			if s.workspacePattern != "" {
+				if workspace, ok := s.workspaceOf(*v.Name); ok {
+					file := strings.Join([]string{s.containerName, *v.Name}, "/")
+					files = append(files, file)
+					s.workspaces[file] = workspace
+				}
+				continue
+			}
 			if match, _ := doublestar.Match(fullPattern, *v.Name); match {
 				files = append(files, strings.Join([]string{s.containerName, *v.Name}, "/"))
 			}
@@ -94,3 +141,15 @@ func (s *AzureRMEnumerator) Enumerate() ([]string, error) {
 
 	return files, nil
 }
+
+// workspaceOf returns the workspace of a blob when it is the state of a workspace matching the pattern
+func (s *AzureRMEnumerator) workspaceOf(name string) (string, bool) {
+	workspace := DefaultWorkspace
+	if name != s.objectPath {
+		if !strings.HasPrefix(name, s.objectPath+azureRMWorkspaceKeySuffix) {
+			return "", false
+		}
+		workspace = strings.TrimPrefix(name, s.objectPath+azureRMWorkspaceKeySuffix)
+	}
+	return workspace, matchWorkspace(s.workspacePattern, workspace)
+}
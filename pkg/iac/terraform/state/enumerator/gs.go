@@ -0,0 +1,135 @@
+package enumerator
+
+import (
+	"context"
+	"strings"
+
+	"cloud.google.com/go/storage"
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+	"google.golang.org/api/iterator"
+)
+
+type GSEnumerator struct {
+	config        config.SupplierConfig
+	storageClient *storage.Client
+	workspaces
+}
+
+func NewGSEnumerator(config config.SupplierConfig) *GSEnumerator {
+	return &GSEnumerator{
+		config: config,
+	}
+}
+
+func (s *GSEnumerator) Origin() string {
+	return s.config.String()
+}
+
+func (s *GSEnumerator) Enumerate() ([]string, error) {
+	path, query := options.SplitQuery(s.config.Path, workspacesQueryParam)
+	bucketPath := strings.Split(path, "/")
+	if len(bucketPath) < 2 {
+		return nil, errors.Errorf("Unable to parse Google Storage path: %s. Must be BUCKET_NAME/PREFIX", s.config.Path)
+	}
+
+	bucket := bucketPath[0]
+	workspacePattern, err := workspacePattern(strings.Join(bucketPath[1:], "/"), query)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single object is read as is, so listing permissions are only needed when globbing
+	if workspacePattern == "" && !HasMeta(path) {
+		return []string{path}, nil
+	}
+
+	// prefix should contains everything that does not have a glob pattern
+	// Pattern should be the glob matcher string
+	prefix, pattern := GlobS3(strings.Join(bucketPath[1:], "/"))
+
+	fullPattern := strings.Join([]string{prefix, pattern}, "/")
+	fullPattern = strings.Trim(fullPattern, "/")
+
+	ctx := context.Background()
+	if s.storageClient == nil {
+		client, err := storage.NewClient(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.storageClient = client
+		// The client is only needed to list objects, states are read by their own backend
+		defer func() {
+			if err := client.Close(); err != nil {
+				logrus.WithField("error", err).Debug("Unable to close Google Storage client")
+			}
+			s.storageClient = nil
+		}()
+	}
+
+	if workspacePattern != "" {
+		return s.enumerateWorkspaces(ctx, bucket, strings.Trim(strings.Join(bucketPath[1:], "/"), "/"), workspacePattern)
+	}
+
+	files := make([]string, 0)
+	it := s.storageClient.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if attrs.Size == 0 {
+			continue
+		}
+		if match, _ := doublestar.Match(fullPattern, attrs.Name); match {
+			files = append(files, strings.Join([]string{bucket, attrs.Name}, "/"))
+		}
+	}
+
+	if len(files) == 0 {
+		return files, errors.Errorf("no Terraform state was found in %s, exiting", s.config.Path)
+	}
+
+	return files, nil
+}
+
+// enumerateWorkspaces lists the states of every workspace matching the pattern,
+// the gcs backend stores them at <prefix>/<workspace>.tfstate
+func (s *GSEnumerator) enumerateWorkspaces(ctx context.Context, bucket, prefix, pattern string) ([]string, error) {
+	s.workspaces = make(workspaces)
+	files := make([]string, 0)
+
+	objectPrefix := prefix + "/"
+	it := s.storageClient.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: objectPrefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if attrs.Size == 0 || !strings.HasSuffix(attrs.Name, ".tfstate") {
+			continue
+		}
+		workspace := strings.TrimSuffix(strings.TrimPrefix(attrs.Name, objectPrefix), ".tfstate")
+		if !matchWorkspace(pattern, workspace) {
+			continue
+		}
+		file := strings.Join([]string{bucket, attrs.Name}, "/")
+		files = append(files, file)
+		s.workspaces[file] = workspace
+	}
+
+	if len(files) == 0 {
+		return files, errors.Errorf("no Terraform state was found in %s, exiting", s.config.Path)
+	}
+
+	return files, nil
+}
@@ -0,0 +1,107 @@
+package enumerator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/api/option"
+)
+
+func TestGSEnumerator_Enumerate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   config.SupplierConfig
+		response string
+		want     []string
+		err      string
+	}{
+		{
+			name:   "single object is not listed",
+			config: config.SupplierConfig{Path: "bucket-name/a/nested/prefix/terraform.tfstate"},
+			want:   []string{"bucket-name/a/nested/prefix/terraform.tfstate"},
+		},
+		{
+			name:   "glob with double star",
+			config: config.SupplierConfig{Path: "bucket-name/a/nested/prefix/**/*.tfstate"},
+			response: `{"items": [
+				{"name": "a/nested/prefix/state1.tfstate", "size": "5"},
+				{"name": "a/nested/prefix/empty.tfstate", "size": "0"},
+				{"name": "a/nested/prefix/folder1/state2.tfstate", "size": "5"},
+				{"name": "a/nested/prefix/folder1/state2.tfstate.backup", "size": "5"},
+				{"name": "a/nested/prefix/folder2/subfolder1/state3.tfstate", "size": "5"}
+			]}`,
+			want: []string{
+				"bucket-name/a/nested/prefix/state1.tfstate",
+				"bucket-name/a/nested/prefix/folder1/state2.tfstate",
+				"bucket-name/a/nested/prefix/folder2/subfolder1/state3.tfstate",
+			},
+		},
+		{
+			name:     "no state found",
+			config:   config.SupplierConfig{Path: "bucket-name/a/nested/prefix/*.tfstate"},
+			response: `{"items": [{"name": "a/nested/prefix/folder1/state2.tfstate", "size": "5"}]}`,
+			err:      "no Terraform state was found in bucket-name/a/nested/prefix/*.tfstate, exiting",
+		},
+		{
+			name:   "invalid path",
+			config: config.SupplierConfig{Path: "bucket-name"},
+			err:    "Unable to parse Google Storage path: bucket-name. Must be BUCKET_NAME/PREFIX",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/b/bucket-name/o", r.URL.Path)
+				assert.Equal(t, "a/nested/prefix", r.URL.Query().Get("prefix"))
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+			client, err := storage.NewClient(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			enumerator := NewGSEnumerator(tt.config)
+			enumerator.storageClient = client
+			got, err := enumerator.Enumerate()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGSEnumerator_EnumerateWorkspaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/b/bucket-name/o", r.URL.Path)
+		assert.Equal(t, "network/", r.URL.Query().Get("prefix"))
+		_, _ = w.Write([]byte(`{"items": [
+			{"name": "network/default.tfstate", "size": "5"},
+			{"name": "network/prod.tfstate", "size": "5"},
+			{"name": "network/staging.tflock", "size": "5"},
+			{"name": "network/empty.tfstate", "size": "0"},
+			{"name": "network/nested/dev.tfstate", "size": "5"}
+		]}`))
+	}))
+	defer server.Close()
+	client, err := storage.NewClient(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enumerator := NewGSEnumerator(config.SupplierConfig{Path: "bucket-name/network?workspaces=*"})
+	enumerator.storageClient = client
+	got, err := enumerator.Enumerate()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bucket-name/network/default.tfstate", "bucket-name/network/prod.tfstate"}, got)
+	assert.Equal(t, "default", enumerator.Workspace("bucket-name/network/default.tfstate"))
+	assert.Equal(t, "prod", enumerator.Workspace("bucket-name/network/prod.tfstate"))
+}
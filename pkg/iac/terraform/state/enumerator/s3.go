@@ -5,45 +5,82 @@ import (
 	"strings"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/pkg/errors"
-	"github.com/snyk/driftctl/pkg/envproxy"
 
 	"github.com/bmatcuk/doublestar/v4"
 	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
 )
 
 type S3Enumerator struct {
-	config config.SupplierConfig
-	client s3iface.S3API
+	config  config.SupplierConfig
+	client  s3iface.S3API
+	options options.S3BackendOptions
+	workspaces
 }
 
-func NewS3Enumerator(config config.SupplierConfig) *S3Enumerator {
-	envProxy := envproxy.NewEnvProxy("DCTL_S3_", "AWS_")
-	envProxy.Apply()
-	sess := session.Must(session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-	}))
-	envProxy.Restore()
-	return &S3Enumerator{
-		config,
-		s3.New(sess),
+func NewS3Enumerator(config config.SupplierConfig) (*S3Enumerator, error) {
+	_, query := options.SplitQuery(config.Path, s3QueryKeys()...)
+	opts, err := options.ParseS3Options(query)
+	if err != nil {
+		return nil, err
 	}
+	return &S3Enumerator{
+		config:  config,
+		client:  backend.NewS3Client(opts),
+		options: opts,
+	}, nil
+}
+
+func s3QueryKeys() []string {
+	return append([]string{workspacesQueryParam, "workspace_key_prefix"}, options.S3OptionKeys...)
 }
 
 func (s *S3Enumerator) Origin() string {
 	return s.config.String()
 }
 
+// Enumerate returns the keys of the states, followed by the S3 options of the source so they are read with them
 func (s *S3Enumerator) Enumerate() ([]string, error) {
-	bucketPath := strings.Split(s.config.Path, "/")
+	files, err := s.enumerate()
+	query := s.options.Query().Encode()
+	if err != nil || query == "" {
+		return files, err
+	}
+
+	for i, file := range files {
+		files[i] = fmt.Sprintf("%s?%s", file, query)
+		if s.workspaces != nil {
+			s.workspaces[files[i]] = s.workspaces[file]
+			delete(s.workspaces, file)
+		}
+	}
+	return files, nil
+}
+
+func (s *S3Enumerator) enumerate() ([]string, error) {
+	path, query := options.SplitQuery(s.config.Path, s3QueryKeys()...)
+	bucketPath := strings.Split(path, "/")
 	if len(bucketPath) < 2 {
 		return nil, errors.Errorf("Unable to parse S3 path: %s. Must be BUCKET_NAME/PREFIX", s.config.Path)
 	}
 
 	bucket := bucketPath[0]
+	workspacePattern, err := workspacePattern(strings.Join(bucketPath[1:], "/"), query)
+	if err != nil {
+		return nil, err
+	}
+	if workspacePattern != "" {
+		keyPrefix := defaultS3WorkspaceKeyPrefix
+		if _, exist := query["workspace_key_prefix"]; exist {
+			keyPrefix = query.Get("workspace_key_prefix")
+		}
+		return s.enumerateWorkspaces(bucket, strings.Join(bucketPath[1:], "/"), keyPrefix, workspacePattern)
+	}
+
 	// prefix should contains everything that does not have a glob pattern
 	// Pattern should be the glob matcher string
 	prefix, pattern := GlobS3(strings.Join(bucketPath[1:], "/"))
@@ -56,7 +93,7 @@ func (s *S3Enumerator) Enumerate() ([]string, error) {
 		Bucket: &bucket,
 		Prefix: &prefix,
 	}
-	err := s.client.ListObjectsV2Pages(input, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+	err = s.client.ListObjectsV2Pages(input, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
 		for _, metadata := range output.Contents {
 			if aws.Int64Value(metadata.Size) > 0 {
 				key := *metadata.Key
@@ -77,3 +114,61 @@ func (s *S3Enumerator) Enumerate() ([]string, error) {
 
 	return files, nil
 }
+
+// enumerateWorkspaces lists the states of a key in every workspace matching the pattern, the S3 backend stores
+// the default workspace state at the key and other workspace states at <workspace_key_prefix>/<workspace>/<key>
+func (s *S3Enumerator) enumerateWorkspaces(bucket, key, keyPrefix, pattern string) ([]string, error) {
+	s.workspaces = make(workspaces)
+	files := make([]string, 0)
+
+	if matchWorkspace(pattern, DefaultWorkspace) {
+		err := s.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+			Bucket: &bucket,
+			Prefix: &key,
+		}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, metadata := range output.Contents {
+				if aws.Int64Value(metadata.Size) > 0 && *metadata.Key == key {
+					file := strings.Join([]string{bucket, key}, "/")
+					files = append(files, file)
+					s.workspaces[file] = DefaultWorkspace
+				}
+			}
+			return !lastPage
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prefix := strings.Trim(keyPrefix, "/") + "/"
+	if keyPrefix == "" {
+		prefix = ""
+	}
+	err := s.client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: &bucket,
+		Prefix: &prefix,
+	}, func(output *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, metadata := range output.Contents {
+			if aws.Int64Value(metadata.Size) == 0 {
+				continue
+			}
+			workspaceKey := strings.SplitN(strings.TrimPrefix(*metadata.Key, prefix), "/", 2)
+			if len(workspaceKey) != 2 || workspaceKey[1] != key || !matchWorkspace(pattern, workspaceKey[0]) {
+				continue
+			}
+			file := strings.Join([]string{bucket, *metadata.Key}, "/")
+			files = append(files, file)
+			s.workspaces[file] = workspaceKey[0]
+		}
+		return !lastPage
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return files, fmt.Errorf("no Terraform state was found in %s, exiting", s.config.Path)
+	}
+
+	return files, nil
+}
@@ -9,7 +9,9 @@ import (
 	awssdk "github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
 	awstest "github.com/snyk/driftctl/test/aws"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -45,13 +47,30 @@ func TestS3Enumerator_NewS3Enumerator(t *testing.T) {
 			},
 			want: "eu-west-3",
 		},
+		{
+			name: "test with region option",
+			config: config.SupplierConfig{
+				Key:     "tfstate",
+				Backend: "s3",
+				Path:    "bucket/terraform.tfstate?region=ap-south-1",
+			},
+			setEnv: map[string]string{
+				"AWS_DEFAULT_REGION":     "us-east-1",
+				"DCTL_S3_DEFAULT_REGION": "eu-west-3",
+			},
+			want: "ap-south-1",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			for key, value := range tt.setEnv {
 				os.Setenv(key, value)
 			}
-			got := NewS3Enumerator(tt.config).client.(*s3.S3).Config.Region
+			enumerator, err := NewS3Enumerator(tt.config)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := enumerator.client.(*s3.S3).Config.Region
 			if awssdk.StringValue(got) != tt.want {
 				t.Errorf("NewS3Enumerator().client.Config.Region got = %v, want %v", got, tt.want)
 			}
@@ -59,6 +78,25 @@ func TestS3Enumerator_NewS3Enumerator(t *testing.T) {
 	}
 }
 
+func TestS3Enumerator_NewS3EnumeratorWithOptions(t *testing.T) {
+	t.Setenv("AWS_DEFAULT_REGION", "us-east-1")
+
+	enumerator, err := NewS3Enumerator(config.SupplierConfig{
+		Path: "bucket/terraform.tfstate?endpoint=https://minio.example.com&force_path_style=true",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := enumerator.client.(*s3.S3)
+	assert.Equal(t, "https://minio.example.com", client.Endpoint)
+	assert.True(t, awssdk.BoolValue(client.Config.S3ForcePathStyle))
+
+	_, err = NewS3Enumerator(config.SupplierConfig{
+		Path: "bucket/terraform.tfstate?external_id=123",
+	})
+	assert.EqualError(t, err, "external_id option requires the role_arn option")
+}
+
 func TestS3Enumerator_Enumerate(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -554,3 +592,133 @@ func TestS3Enumerator_Enumerate(t *testing.T) {
 		})
 	}
 }
+
+func TestS3Enumerator_EnumerateWorkspaces(t *testing.T) {
+	listObjects := func(client *awstest.MockFakeS3, prefix string, keys ...string) {
+		objects := make([]*s3.Object, 0, len(keys))
+		for _, key := range keys {
+			objects = append(objects, &s3.Object{Key: awssdk.String(key), Size: awssdk.Int64(5)})
+		}
+		client.On(
+			"ListObjectsV2Pages",
+			&s3.ListObjectsV2Input{
+				Bucket: awssdk.String("bucket-name"),
+				Prefix: awssdk.String(prefix),
+			},
+			mock.MatchedBy(func(callback func(res *s3.ListObjectsV2Output, lastPage bool) bool) bool {
+				callback(&s3.ListObjectsV2Output{Contents: objects}, true)
+				return true
+			}),
+		).Return(nil)
+	}
+
+	tests := []struct {
+		name           string
+		config         config.SupplierConfig
+		mocks          func(client *awstest.MockFakeS3)
+		want           []string
+		wantWorkspaces map[string]string
+		err            string
+	}{
+		{
+			name:   "every workspace",
+			config: config.SupplierConfig{Path: "bucket-name/network/terraform.tfstate?workspaces=*"},
+			mocks: func(client *awstest.MockFakeS3) {
+				listObjects(client, "network/terraform.tfstate",
+					"network/terraform.tfstate",
+					"network/terraform.tfstate.backup",
+				)
+				listObjects(client, "env:/",
+					"env:/staging/network/terraform.tfstate",
+					"env:/prod/network/terraform.tfstate",
+					"env:/prod/database/terraform.tfstate",
+				)
+			},
+			want: []string{
+				"bucket-name/network/terraform.tfstate",
+				"bucket-name/env:/staging/network/terraform.tfstate",
+				"bucket-name/env:/prod/network/terraform.tfstate",
+			},
+			wantWorkspaces: map[string]string{
+				"bucket-name/network/terraform.tfstate":              "default",
+				"bucket-name/env:/staging/network/terraform.tfstate": "staging",
+				"bucket-name/env:/prod/network/terraform.tfstate":    "prod",
+			},
+		},
+		{
+			name:   "workspaces matching a pattern with a custom key prefix",
+			config: config.SupplierConfig{Path: "bucket-name/terraform.tfstate?workspaces=prod-*&workspace_key_prefix=workspaces"},
+			mocks: func(client *awstest.MockFakeS3) {
+				listObjects(client, "workspaces/",
+					"workspaces/prod-eu/terraform.tfstate",
+					"workspaces/staging-eu/terraform.tfstate",
+				)
+			},
+			want: []string{"bucket-name/workspaces/prod-eu/terraform.tfstate"},
+			wantWorkspaces: map[string]string{
+				"bucket-name/workspaces/prod-eu/terraform.tfstate": "prod-eu",
+			},
+		},
+		{
+			name:   "glob in the key",
+			config: config.SupplierConfig{Path: "bucket-name/**/terraform.tfstate?workspaces=*"},
+			mocks:  func(client *awstest.MockFakeS3) {},
+			err:    "Unable to enumerate workspaces of **/terraform.tfstate, glob patterns are not supported in the state key",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeS3 := awstest.MockFakeS3{}
+			tt.mocks(&fakeS3)
+			s := &S3Enumerator{
+				config: tt.config,
+				client: &fakeS3,
+			}
+			got, err := s.Enumerate()
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			for key, workspace := range tt.wantWorkspaces {
+				assert.Equal(t, workspace, s.Workspace(key))
+			}
+		})
+	}
+}
+
+func TestS3Enumerator_EnumerateWithOptions(t *testing.T) {
+	fakeS3 := awstest.MockFakeS3{}
+	fakeS3.On(
+		"ListObjectsV2Pages",
+		&s3.ListObjectsV2Input{
+			Bucket: awssdk.String("bucket-name"),
+			Prefix: awssdk.String("states"),
+		},
+		mock.MatchedBy(func(callback func(res *s3.ListObjectsV2Output, lastPage bool) bool) bool {
+			callback(&s3.ListObjectsV2Output{
+				Contents: []*s3.Object{
+					{Key: awssdk.String("states/app.tfstate"), Size: awssdk.Int64(5)},
+				},
+			}, true)
+			return true
+		}),
+	).Return(nil)
+
+	s := &S3Enumerator{
+		config: config.SupplierConfig{
+			Path: "bucket-name/states/*.tfstate?role_arn=arn:aws:iam::123456789012:role/states&sse_customer_key_env=STATES_KEY",
+		},
+		client: &fakeS3,
+		options: options.S3BackendOptions{
+			RoleARN:           "arn:aws:iam::123456789012:role/states",
+			SSECustomerKeyEnv: "STATES_KEY",
+		},
+	}
+	got, err := s.Enumerate()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"bucket-name/states/app.tfstate?role_arn=arn%3Aaws%3Aiam%3A%3A123456789012%3Arole%2Fstates&sse_customer_key_env=STATES_KEY",
+	}, got)
+}
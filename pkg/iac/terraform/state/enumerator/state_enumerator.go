@@ -17,9 +17,13 @@ func GetEnumerator(config config.SupplierConfig, opts *backend.Options) (StateEn
 	case backend.BackendKeyFile:
 		return NewFileEnumerator(config), nil
 	case backend.BackendKeyS3:
-		return NewS3Enumerator(config), nil
+		return NewS3Enumerator(config)
 	case backend.BackendKeyAzureRM:
 		return NewAzureRMEnumerator(config, opts.AzureRMBackendOptions)
+	case backend.BackendKeyGS:
+		return NewGSEnumerator(config), nil
+	case backend.BackendKeyTFCloud:
+		return NewTFCloudEnumerator(config, opts), nil
 	}
 
 	logrus.WithFields(logrus.Fields{
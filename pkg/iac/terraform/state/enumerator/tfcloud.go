@@ -0,0 +1,99 @@
+package enumerator
+
+import (
+	"context"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+)
+
+const tfCloudPageSize = 100
+
+// TFCloudEnumerator lists the workspaces of a terraform cloud organization.
+// Workspaces are selected with a glob pattern on their name, e.g. my-org/prod-*,
+// and can be filtered by tags, e.g. my-org/*?tags=prod,network
+type TFCloudEnumerator struct {
+	config config.SupplierConfig
+	opts   *backend.Options
+	client *tfe.Client
+}
+
+func NewTFCloudEnumerator(config config.SupplierConfig, opts *backend.Options) *TFCloudEnumerator {
+	return &TFCloudEnumerator{
+		config: config,
+		opts:   opts,
+	}
+}
+
+func (e *TFCloudEnumerator) Origin() string {
+	return e.config.String()
+}
+
+func (e *TFCloudEnumerator) Enumerate() ([]string, error) {
+	path, query := options.SplitQuery(e.config.Path, "tags")
+	tags := query.Get("tags")
+
+	organization, pattern := path, "*"
+	if i := strings.Index(path, "/"); i >= 0 {
+		organization, pattern = path[:i], path[i+1:]
+	}
+	// A single workspace is read as is, by its ID or its {org}/{workspaceName}
+	if tags == "" && (!strings.Contains(path, "/") || !HasMeta(pattern)) {
+		return []string{path}, nil
+	}
+
+	if e.client == nil {
+		client, err := backend.NewTFCloudClient(e.opts)
+		if err != nil {
+			return nil, err
+		}
+		e.client = client
+	}
+
+	options := tfe.WorkspaceListOptions{
+		ListOptions: tfe.ListOptions{PageSize: tfCloudPageSize},
+	}
+	// The API search is a partial name match, so the part of the pattern before any glob is a safe filter
+	if prefix := pattern[:strings.IndexAny(pattern+"*", `?*[]`)]; prefix != "" {
+		options.Search = &prefix
+	}
+	if tags != "" {
+		options.Tags = &tags
+	}
+
+	workspaces := make([]string, 0)
+	for {
+		list, err := e.client.Workspaces.List(context.Background(), organization, options)
+		if err != nil {
+			return nil, errors.Errorf("unable to list terraform cloud workspaces: %s", err.Error())
+		}
+		for _, workspace := range list.Items {
+			if match, _ := doublestar.Match(pattern, workspace.Name); !match {
+				continue
+			}
+			if workspace.ResourceCount == 0 {
+				logrus.WithFields(logrus.Fields{
+					"workspace": workspace.Name,
+				}).Warn("Terraform cloud workspace has no resources, its state will not be read")
+				continue
+			}
+			workspaces = append(workspaces, strings.Join([]string{organization, workspace.Name}, "/"))
+		}
+		if list.Pagination == nil || list.NextPage == 0 {
+			break
+		}
+		options.PageNumber = list.NextPage
+	}
+
+	if len(workspaces) == 0 {
+		return nil, errors.Errorf("no Terraform state was found for %s, exiting", e.Origin())
+	}
+
+	return workspaces, nil
+}
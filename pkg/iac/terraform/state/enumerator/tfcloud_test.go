@@ -0,0 +1,132 @@
+package enumerator
+
+import (
+	"testing"
+
+	tfe "github.com/hashicorp/go-tfe"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/snyk/driftctl/test/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTFCloudEnumerator_Enumerate(t *testing.T) {
+	tests := []struct {
+		name   string
+		config config.SupplierConfig
+		mocks  func(workspaces *mocks.Workspaces)
+		want   []string
+		// warnings lists the workspace field of the expected warning logs
+		warnings []string
+		err      string
+	}{
+		{
+			name:   "single workspace by id",
+			config: config.SupplierConfig{Path: "ws-ABCDEFG12345678"},
+			want:   []string{"ws-ABCDEFG12345678"},
+		},
+		{
+			name:   "single workspace by name",
+			config: config.SupplierConfig{Path: "my-org/my-workspace"},
+			want:   []string{"my-org/my-workspace"},
+		},
+		{
+			name:   "workspaces matching a pattern on several pages",
+			config: config.SupplierConfig{Path: "my-org/prod-*"},
+			mocks: func(workspaces *mocks.Workspaces) {
+				prefix := "prod-"
+				workspaces.On("List", mock.Anything, "my-org", tfe.WorkspaceListOptions{
+					ListOptions: tfe.ListOptions{PageSize: 100},
+					Search:      &prefix,
+				}).Return(&tfe.WorkspaceList{
+					Pagination: &tfe.Pagination{CurrentPage: 1, NextPage: 2},
+					Items: []*tfe.Workspace{
+						{Name: "prod-network", ResourceCount: 12},
+						{Name: "prod-empty", ResourceCount: 0},
+						{Name: "preprod-network", ResourceCount: 12},
+					},
+				}, nil).Once()
+				workspaces.On("List", mock.Anything, "my-org", tfe.WorkspaceListOptions{
+					ListOptions: tfe.ListOptions{PageSize: 100, PageNumber: 2},
+					Search:      &prefix,
+				}).Return(&tfe.WorkspaceList{
+					Pagination: &tfe.Pagination{CurrentPage: 2},
+					Items: []*tfe.Workspace{
+						{Name: "prod-database", ResourceCount: 3},
+					},
+				}, nil).Once()
+			},
+			want:     []string{"my-org/prod-network", "my-org/prod-database"},
+			warnings: []string{"prod-empty"},
+		},
+		{
+			name:   "workspaces filtered by tags",
+			config: config.SupplierConfig{Path: "my-org?tags=prod,network"},
+			mocks: func(workspaces *mocks.Workspaces) {
+				tags := "prod,network"
+				workspaces.On("List", mock.Anything, "my-org", tfe.WorkspaceListOptions{
+					ListOptions: tfe.ListOptions{PageSize: 100},
+					Tags:        &tags,
+				}).Return(&tfe.WorkspaceList{
+					Pagination: &tfe.Pagination{CurrentPage: 1},
+					Items: []*tfe.Workspace{
+						{Name: "prod-network", ResourceCount: 12},
+						{Name: "staging-network", ResourceCount: 12},
+					},
+				}, nil).Once()
+			},
+			want: []string{"my-org/prod-network", "my-org/staging-network"},
+		},
+		{
+			name:   "no workspace found",
+			config: config.SupplierConfig{Key: "tfstate", Backend: "tfcloud", Path: "my-org/*?tags=unknown"},
+			mocks: func(workspaces *mocks.Workspaces) {
+				workspaces.On("List", mock.Anything, "my-org", mock.Anything).Return(&tfe.WorkspaceList{
+					Pagination: &tfe.Pagination{CurrentPage: 1},
+					Items:      []*tfe.Workspace{},
+				}, nil).Once()
+			},
+			err: "no Terraform state was found for tfstate+tfcloud://my-org/*?tags=unknown, exiting",
+		},
+		{
+			name:   "list error",
+			config: config.SupplierConfig{Path: "my-org/*"},
+			mocks: func(workspaces *mocks.Workspaces) {
+				workspaces.On("List", mock.Anything, "my-org", mock.Anything).Return(nil, errors.New("unauthorized")).Once()
+			},
+			err: "unable to list terraform cloud workspaces: unauthorized",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := logtest.NewGlobal()
+			defer hook.Reset()
+			fakeWorkspaces := &mocks.Workspaces{}
+			if tt.mocks != nil {
+				tt.mocks(fakeWorkspaces)
+			}
+
+			enumerator := NewTFCloudEnumerator(tt.config, nil)
+			enumerator.client = &tfe.Client{Workspaces: fakeWorkspaces}
+			got, err := enumerator.Enumerate()
+			fakeWorkspaces.AssertExpectations(t)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+
+			var warnings []string
+			for _, entry := range hook.AllEntries() {
+				if entry.Level == logrus.WarnLevel {
+					warnings = append(warnings, entry.Data["workspace"].(string))
+				}
+			}
+			assert.Equal(t, tt.warnings, warnings)
+		})
+	}
+}
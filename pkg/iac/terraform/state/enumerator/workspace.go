@@ -0,0 +1,59 @@
+package enumerator
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultWorkspace is the name of the workspace terraform uses when none is selected
+	DefaultWorkspace = "default"
+
+	workspacesQueryParam = "workspaces"
+	// defaultS3WorkspaceKeyPrefix is the prefix of non-default workspace states of the S3 backend
+	defaultS3WorkspaceKeyPrefix = "env:"
+	// azureRMWorkspaceKeySuffix is appended to the key, followed by the workspace name, by the azurerm backend
+	azureRMWorkspaceKeySuffix = "env:"
+)
+
+// WorkspaceEnumerator is implemented by enumerators able to tell which terraform workspace a state belongs to
+type WorkspaceEnumerator interface {
+	Workspace(key string) string
+}
+
+// workspaces records the terraform workspace of every enumerated state
+type workspaces map[string]string
+
+func (w workspaces) Workspace(key string) string {
+	return w[key]
+}
+
+// workspacePattern returns the glob pattern matching workspace names, or an empty string when workspaces are not
+// enumerated. Globs are not supported in the state key when enumerating workspaces.
+func workspacePattern(key string, query url.Values) (string, error) {
+	if _, exist := query[workspacesQueryParam]; !exist {
+		return "", nil
+	}
+	if HasMeta(key) {
+		return "", errors.Errorf("Unable to enumerate workspaces of %s, glob patterns are not supported in the state key", key)
+	}
+	pattern := query.Get(workspacesQueryParam)
+	if pattern == "" {
+		pattern = "*"
+	}
+	if !doublestar.ValidatePattern(pattern) {
+		return "", errors.Errorf("Invalid workspaces pattern: %s", pattern)
+	}
+	return pattern, nil
+}
+
+func matchWorkspace(pattern, workspace string) bool {
+	if workspace == "" || strings.Contains(workspace, "/") {
+		return false
+	}
+	match, _ := doublestar.Match(pattern, workspace)
+	return match
+}
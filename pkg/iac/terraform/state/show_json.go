@@ -0,0 +1,152 @@
+package state
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// showJSON is the subset of the `terraform show -json` output needed to rebuild a state.
+// Plan outputs hold the state they were computed from in prior_state.
+type showJSON struct {
+	FormatVersion    string           `json:"format_version"`
+	TerraformVersion string           `json:"terraform_version"`
+	Values           *showJSONValues  `json:"values"`
+	PlannedValues    *json.RawMessage `json:"planned_values"`
+	PriorState       *struct {
+		Values *showJSONValues `json:"values"`
+	} `json:"prior_state"`
+}
+
+type showJSONValues struct {
+	RootModule showJSONModule `json:"root_module"`
+}
+
+type showJSONModule struct {
+	Address      string             `json:"address"`
+	Resources    []showJSONResource `json:"resources"`
+	ChildModules []showJSONModule   `json:"child_modules"`
+}
+
+type showJSONResource struct {
+	Address       string          `json:"address"`
+	Mode          string          `json:"mode"`
+	Type          string          `json:"type"`
+	Name          string          `json:"name"`
+	Index         json.RawMessage `json:"index,omitempty"`
+	ProviderName  string          `json:"provider_name"`
+	SchemaVersion uint64          `json:"schema_version"`
+	Values        json.RawMessage `json:"values"`
+}
+
+// stateV4 is the subset of the v4 statefile format written from a `terraform show -json` output
+type stateV4 struct {
+	Version          int                        `json:"version"`
+	TerraformVersion string                     `json:"terraform_version"`
+	Serial           uint64                     `json:"serial"`
+	Lineage          string                     `json:"lineage"`
+	Outputs          map[string]json.RawMessage `json:"outputs"`
+	Resources        []*resourceV4              `json:"resources"`
+}
+
+type resourceV4 struct {
+	Module    string       `json:"module,omitempty"`
+	Mode      string       `json:"mode"`
+	Type      string       `json:"type"`
+	Name      string       `json:"name"`
+	Provider  string       `json:"provider"`
+	Instances []instanceV4 `json:"instances"`
+}
+
+type instanceV4 struct {
+	IndexKey      json.RawMessage `json:"index_key,omitempty"`
+	SchemaVersion uint64          `json:"schema_version"`
+	Attributes    json.RawMessage `json:"attributes"`
+}
+
+// isShowJSON tells whether the content is a `terraform show -json` output rather than a raw statefile
+func isShowJSON(content []byte) bool {
+	var doc struct {
+		FormatVersion *string          `json:"format_version"`
+		Version       *json.RawMessage `json:"version"`
+	}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return false
+	}
+	return doc.FormatVersion != nil && doc.Version == nil
+}
+
+// convertShowJSON rewrites a `terraform show -json` output as a v4 statefile, keeping module addresses,
+// instance keys and provider names, so it can be read like any other state
+func convertShowJSON(content []byte) ([]byte, error) {
+	var show showJSON
+	if err := json.Unmarshal(content, &show); err != nil {
+		return nil, errors.Wrap(err, "unable to read terraform show output")
+	}
+	if !strings.HasPrefix(show.FormatVersion, "0.") && !strings.HasPrefix(show.FormatVersion, "1.") {
+		return nil, errors.Errorf("unsupported terraform show output format version %s", show.FormatVersion)
+	}
+
+	state := stateV4{
+		Version:          4,
+		TerraformVersion: show.TerraformVersion,
+		Outputs:          map[string]json.RawMessage{},
+		Resources:        []*resourceV4{},
+	}
+	values := show.Values
+	if show.PlannedValues != nil {
+		if show.PriorState == nil {
+			return nil, errors.New("terraform plan output has no prior state to read resources from")
+		}
+		values = show.PriorState.Values
+	}
+	// Terraform omits values when the state holds no resource, e.g. {"format_version":"1.0"}
+	if values != nil {
+		resources := make(map[string]*resourceV4)
+		appendModuleResources(&state, resources, values.RootModule)
+	}
+
+	return json.Marshal(state)
+}
+
+func appendModuleResources(state *stateV4, resources map[string]*resourceV4, module showJSONModule) {
+	for _, res := range module.Resources {
+		key := fmt.Sprintf("%s|%s|%s|%s", module.Address, res.Mode, res.Type, res.Name)
+		stateRes, exists := resources[key]
+		if !exists {
+			stateRes = &resourceV4{
+				Module:    module.Address,
+				Mode:      res.Mode,
+				Type:      res.Type,
+				Name:      res.Name,
+				Provider:  providerConfigAddress(res.ProviderName),
+				Instances: []instanceV4{},
+			}
+			resources[key] = stateRes
+			state.Resources = append(state.Resources, stateRes)
+		}
+		values := res.Values
+		if len(values) == 0 {
+			values = json.RawMessage("{}")
+		}
+		stateRes.Instances = append(stateRes.Instances, instanceV4{
+			IndexKey:      res.Index,
+			SchemaVersion: res.SchemaVersion,
+			Attributes:    values,
+		})
+	}
+	for _, child := range module.ChildModules {
+		appendModuleResources(state, resources, child)
+	}
+}
+
+// providerConfigAddress returns the provider address written in statefiles for a provider name of the show output.
+// Terraform 0.12 only outputs the provider type, e.g. aws, instead of registry.terraform.io/hashicorp/aws.
+func providerConfigAddress(providerName string) string {
+	if !strings.Contains(providerName, "/") {
+		return fmt.Sprintf("provider.%s", providerName)
+	}
+	return fmt.Sprintf("provider[%q]", providerName)
+}
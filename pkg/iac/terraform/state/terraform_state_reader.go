@@ -1,7 +1,9 @@
 package state
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"strings"
 
 	"github.com/hashicorp/terraform/addrs"
@@ -19,6 +21,7 @@ import (
 
 	"github.com/snyk/driftctl/pkg/iac/config"
 	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/encryption"
 	"github.com/snyk/driftctl/pkg/iac/terraform/state/enumerator"
 	"github.com/snyk/driftctl/pkg/resource"
 	"github.com/snyk/driftctl/pkg/terraform"
@@ -42,6 +45,8 @@ type TerraformStateReader struct {
 	filter         filter.Filter
 	alerter        *alerter.Alerter
 	sourceCount    uint
+	workspace      string
+	decrypter      *encryption.Decrypter
 }
 
 func (r *TerraformStateReader) initReader() error {
@@ -68,6 +73,12 @@ func NewReader(config config.SupplierConfig, library *terraform.ProviderLibrary,
 	if err != nil {
 		return nil, err
 	}
+	if backendOpts != nil {
+		reader.decrypter, err = encryption.NewDecrypter(backendOpts.StateEncryptionOptions)
+		if err != nil {
+			return nil, err
+		}
+	}
 	return &reader, nil
 }
 
@@ -78,7 +89,7 @@ func (r *TerraformStateReader) retrieve() (map[string][]decodedRes, error) {
 	}
 	r.backend = b
 
-	state, err := read(r.config.Path, r.backend)
+	state, err := read(r.config.Path, r.backend, r.decrypter)
 	defer r.backend.Close()
 	if err != nil {
 		return nil, err
@@ -155,7 +166,7 @@ func (r *TerraformStateReader) retrieve() (map[string][]decodedRes, error) {
 				}
 				_, exists := resMap[stateRes.Addr.Resource.Type]
 				val := decodedRes{
-					source: resource.NewTerraformStateSource(r.config.String(), moduleName, resName),
+					source: r.newSource(moduleName, resName),
 					val:    decodedVal.Value,
 				}
 				if !exists {
@@ -220,9 +231,22 @@ func (r *TerraformStateReader) decode(valFromState map[string][]decodedRes) ([]*
 	return results, nil
 }
 
+// newSource returns the source of a resource read from the current state, tagged with its terraform workspace
+// when states are enumerated by workspace
+func (r *TerraformStateReader) newSource(moduleName, resName string) *resource.TerraformStateSource {
+	source := resource.NewTerraformStateSource(r.config.String(), moduleName, resName)
+	source.Workspace = r.workspace
+	return source
+}
+
 func (r *TerraformStateReader) Resources() ([]*resource.Resource, error) {
 	if r.enumerator == nil {
-		return r.retrieveForState(r.config.Path)
+		resources, err := r.retrieveForState(r.config.Path)
+		if err != nil {
+			r.sendReadingAlert(r.config.Path, err)
+			return nil, err
+		}
+		return resources, nil
 	}
 
 	return r.retrieveMultiplesStates()
@@ -263,11 +287,15 @@ func (r *TerraformStateReader) retrieveMultiplesStates() ([]*resource.Resource,
 	isSuccess := false
 	readingError := iac.NewStateReadingError()
 
+	workspaceEnumerator, _ := r.enumerator.(enumerator.WorkspaceEnumerator)
 	for _, key := range keys {
+		if workspaceEnumerator != nil {
+			r.workspace = workspaceEnumerator.Workspace(key)
+		}
 		resources, err := r.retrieveForState(key)
 		if err != nil {
 			readingError.Add(err)
-			r.alerter.SendAlert("", NewStateReadingAlert(key, err))
+			r.sendReadingAlert(key, err)
 			continue
 		}
 		isSuccess = true
@@ -282,8 +310,18 @@ func (r *TerraformStateReader) retrieveMultiplesStates() ([]*resource.Resource,
 	return results, nil
 }
 
-func read(path string, reader backend.Backend) (*states.State, error) {
-	state, err := readState(path, reader)
+// sendReadingAlert reports a state that could not be read, telling apart states written by unsupported terraform versions
+func (r *TerraformStateReader) sendReadingAlert(key string, err error) {
+	var unsupportedVersionErr *UnsupportedVersionError
+	if errors.As(err, &unsupportedVersionErr) {
+		r.alerter.SendAlert("", NewUnsupportedStateVersionAlert(key, err))
+		return
+	}
+	r.alerter.SendAlert("", NewStateReadingAlert(key, err))
+}
+
+func read(path string, reader backend.Backend, decrypter *encryption.Decrypter) (*states.State, error) {
+	state, err := readState(path, reader, decrypter)
 	if err != nil {
 		if _, ok := reader.(*backend.HTTPBackend); ok && strings.Contains(err.Error(), "The state file could not be parsed as JSON") {
 			return nil, errors.Errorf("given url is not a valid state file")
@@ -293,12 +331,39 @@ func read(path string, reader backend.Backend) (*states.State, error) {
 	return state, nil
 }
 
-func readState(path string, reader backend.Backend) (*states.State, error) {
-	state, err := statefile.Read(reader)
+func readState(path string, reader backend.Backend, decrypter *encryption.Decrypter) (*states.State, error) {
+	content, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if encryption.IsEncrypted(content) {
+		if decrypter == nil {
+			return nil, errors.New("state is encrypted, no key material was given to decrypt it")
+		}
+		logrus.WithField("path", path).Debug("Decrypting OpenTofu encrypted state")
+		content, err = decrypter.Decrypt(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if isShowJSON(content) {
+		logrus.WithField("path", path).Debug("Reading state from terraform show output")
+		content, err = convertShowJSON(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	state, err := statefile.Read(bytes.NewReader(content))
 	if err != nil {
 		return nil, err
 	}
 
+	// Show outputs of empty states don't tell the terraform version, there is nothing to read anyway
+	if state.TerraformVersion == nil {
+		return state.State, nil
+	}
+
 	supported, err := IsVersionSupported(state.TerraformVersion.String())
 	if err != nil {
 		return nil, err
@@ -2,12 +2,15 @@ package state
 
 import (
 	"encoding/json"
+	"io"
 	"os"
 	"path"
 	"strings"
 	"testing"
 
+	"github.com/hashicorp/terraform/addrs"
 	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/pkg/alerter"
 	"github.com/snyk/driftctl/pkg/filter"
 	"github.com/snyk/driftctl/pkg/output"
 	"github.com/snyk/driftctl/pkg/remote/azurerm"
@@ -21,7 +24,12 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/snyk/driftctl/pkg/iac/config"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/backend/options"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/encryption"
+	"github.com/snyk/driftctl/pkg/iac/terraform/state/enumerator"
 	"github.com/snyk/driftctl/pkg/remote/aws"
+	"github.com/snyk/driftctl/pkg/remote/aws/client"
 	"github.com/snyk/driftctl/pkg/remote/github"
 	"github.com/snyk/driftctl/pkg/resource"
 	"github.com/snyk/driftctl/pkg/terraform"
@@ -33,7 +41,7 @@ import (
 
 func TestReadStateValid(t *testing.T) {
 	reader, _ := os.Open("testdata/v4/valid.tfstate")
-	_, err := readState("terraform.tfstate", reader)
+	_, err := readState("terraform.tfstate", reader, nil)
 	if err != nil {
 		t.Errorf("Unable to read state, %s", err)
 		return
@@ -42,12 +50,111 @@ func TestReadStateValid(t *testing.T) {
 
 func TestReadStateInvalid(t *testing.T) {
 	reader, _ := os.Open("testdata/v4/invalid.tfstate")
-	state, err := readState("terraform.tfstate", reader)
+	state, err := readState("terraform.tfstate", reader, nil)
 	if err == nil || state != nil {
 		t.Errorf("ReadFile invalid state should return error")
 	}
 }
 
+func TestReadStateEncrypted(t *testing.T) {
+	reader, _ := os.Open("testdata/v4/encrypted.tfstate")
+	_, err := readState("terraform.tfstate", reader, nil)
+	assert.EqualError(t, err, "state is encrypted, no key material was given to decrypt it")
+
+	decrypter, err := encryption.NewDecrypter(options.StateEncryptionOptions{Passphrase: "driftctl-encrypted-state"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	reader, _ = os.Open("testdata/v4/encrypted.tfstate")
+	state, err := readState("terraform.tfstate", reader, decrypter)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotEmpty(t, state.Modules)
+}
+
+func TestReadStateShowJSON(t *testing.T) {
+	reader, err := os.Open("testdata/v4/show.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+	state, err := readState("terraform.json", reader, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	bucket := state.RootModule().Resources["aws_s3_bucket.bucket"]
+	if assert.NotNil(t, bucket) {
+		assert.Equal(t, `provider["registry.terraform.io/hashicorp/aws"]`, bucket.ProviderConfig.String())
+		assert.JSONEq(t, `{"bucket":"driftctl-show-bucket","id":"driftctl-show-bucket"}`, string(bucket.Instances[addrs.NoKey].Current.AttrsJSON))
+	}
+	assert.NotNil(t, state.RootModule().Resources["data.aws_caller_identity.current"])
+
+	module := state.Module(addrs.RootModuleInstance.Child("users", addrs.StringKey("prod")))
+	if assert.NotNil(t, module) {
+		users := module.Resources["aws_iam_user.user"]
+		assert.Len(t, users.Instances, 2)
+		assert.JSONEq(t, `{"id":"bob","name":"bob"}`, string(users.Instances[addrs.IntKey(1)].Current.AttrsJSON))
+		keys := module.Resources["aws_iam_access_key.key"]
+		assert.JSONEq(t, `{"id":"AKIAEXAMPLE","user":"alice"}`, string(keys.Instances[addrs.StringKey("alice")].Current.AttrsJSON))
+	}
+}
+
+func TestReadStateShowJSONPlan(t *testing.T) {
+	plan := `{
+  "format_version": "1.0",
+  "terraform_version": "1.0.11",
+  "planned_values": {"root_module": {}},
+  "prior_state": {
+    "format_version": "1.0",
+    "terraform_version": "1.0.11",
+    "values": {
+      "root_module": {
+        "resources": [
+          {
+            "address": "aws_s3_bucket.bucket",
+            "mode": "managed",
+            "type": "aws_s3_bucket",
+            "name": "bucket",
+            "provider_name": "registry.terraform.io/hashicorp/aws",
+            "schema_version": 0,
+            "values": {"bucket": "driftctl-plan-bucket", "id": "driftctl-plan-bucket"}
+          }
+        ]
+      }
+    }
+  }
+}`
+	state, err := readState("plan.json", io.NopCloser(strings.NewReader(plan)), nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+	bucket := state.RootModule().Resources["aws_s3_bucket.bucket"]
+	if assert.NotNil(t, bucket) {
+		assert.JSONEq(t, `{"bucket":"driftctl-plan-bucket","id":"driftctl-plan-bucket"}`, string(bucket.Instances[addrs.NoKey].Current.AttrsJSON))
+	}
+}
+
+func TestReadStateShowJSONWithoutValues(t *testing.T) {
+	_, err := readState("plan.json", io.NopCloser(strings.NewReader(`{"format_version":"1.0","terraform_version":"1.0.11","planned_values":{"root_module":{}}}`)), nil)
+	assert.EqualError(t, err, "terraform plan output has no prior state to read resources from")
+
+	state, err := readState("terraform.json", io.NopCloser(strings.NewReader(`{"format_version":"1.0"}`)), nil)
+	if assert.NoError(t, err) {
+		assert.Empty(t, state.RootModule().Resources)
+	}
+
+	state, err = readState("plan.json", io.NopCloser(strings.NewReader(`{"format_version":"1.0","planned_values":{"root_module":{}},"prior_state":{"format_version":"1.0"}}`)), nil)
+	if assert.NoError(t, err) {
+		assert.Empty(t, state.RootModule().Resources)
+	}
+}
+
+func TestReadStateShowJSONUnsupportedFormat(t *testing.T) {
+	_, err := readState("terraform.json", io.NopCloser(strings.NewReader(`{"format_version":"2.0","terraform_version":"2.0.0"}`)), nil)
+	assert.EqualError(t, err, "unsupported terraform show output format version 2.0")
+}
+
 // Check that resource sources are properly set
 func TestTerraformStateReader_Source(t *testing.T) {
 	progress := &output.MockProgress{}
@@ -220,7 +327,7 @@ func TestTerraformStateReader_AWS_Resources(t *testing.T) {
 
 			if shouldUpdate {
 				var err error
-				realProvider, err = aws.NewAWSTerraformProvider(tt.providerVersion, progress, os.TempDir())
+				realProvider, err = aws.NewAWSTerraformProvider(tt.providerVersion, progress, terraform.ProviderInstallOptions{ConfigDir: os.TempDir()}, client.EndpointOptions{}, nil)
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -305,7 +412,7 @@ func TestTerraformStateReader_Github_Resources(t *testing.T) {
 
 			if shouldUpdate {
 				var err error
-				realProvider, err = github.NewGithubTerraformProvider("", progress, os.TempDir())
+				realProvider, err = github.NewGithubTerraformProvider("", progress, terraform.ProviderInstallOptions{ConfigDir: os.TempDir()}, nil)
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -407,7 +514,7 @@ func TestTerraformStateReader_Google_Resources(t *testing.T) {
 			var realProvider *google.GCPTerraformProvider
 			providerVersion := "3.78.0"
 			var err error
-			realProvider, err = google.NewGCPTerraformProvider(providerVersion, progress, os.TempDir())
+			realProvider, err = google.NewGCPTerraformProvider(providerVersion, progress, terraform.ProviderInstallOptions{ConfigDir: os.TempDir()}, nil)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -501,7 +608,7 @@ func TestTerraformStateReader_Azure_Resources(t *testing.T) {
 			var realProvider *azurerm.AzureTerraformProvider
 			providerVersion := "2.71.0"
 			var err error
-			realProvider, err = azurerm.NewAzureTerraformProvider(providerVersion, progress, os.TempDir())
+			realProvider, err = azurerm.NewAzureTerraformProvider(providerVersion, progress, terraform.ProviderInstallOptions{ConfigDir: os.TempDir()}, nil)
 			if err != nil {
 				t.Fatal(err)
 			}
@@ -604,7 +711,7 @@ func TestTerraformStateReader_VersionSupported(t *testing.T) {
 			reader, err := os.Open(test.statePath)
 			assert.NoError(t, err)
 
-			_, err = readState("terraform.tfstate", reader)
+			_, err = readState("terraform.tfstate", reader, nil)
 			if test.err != nil {
 				assert.EqualError(t, err, test.err.Error())
 			} else {
@@ -640,3 +747,48 @@ func TestTerraformStateReader_WithIgnoredResource(t *testing.T) {
 	assert.Nil(t, err)
 	assert.Len(t, got, 0)
 }
+
+func TestTerraformStateReader_UnsupportedVersionAlert(t *testing.T) {
+	statePath := "testdata/v4/unsupported_version.tfstate"
+
+	tests := []struct {
+		name       string
+		enumerator enumerator.StateEnumerator
+	}{
+		{
+			name: "single state",
+		},
+		{
+			name:       "multiple states",
+			enumerator: enumerator.NewFileEnumerator(config.SupplierConfig{Backend: backend.BackendKeyFile, Path: statePath}),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			progress := &output.MockProgress{}
+			progress.On("Inc").Return()
+
+			alerts := alerter.NewAlerter()
+			r := &TerraformStateReader{
+				config: config.SupplierConfig{
+					Key:     "tfstate",
+					Backend: backend.BackendKeyFile,
+					Path:    statePath,
+				},
+				enumerator: test.enumerator,
+				library:    terraform.NewProviderLibrary(),
+				progress:   progress,
+				alerter:    alerts,
+			}
+
+			_, err := r.Resources()
+			assert.Error(t, err)
+
+			sent := alerts.Retrieve()[""]
+			if assert.Len(t, sent, 1) {
+				assert.IsType(t, &UnsupportedStateVersionAlert{}, sent[0])
+			}
+		})
+	}
+}
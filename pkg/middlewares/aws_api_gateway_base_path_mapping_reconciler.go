@@ -14,6 +14,10 @@ func NewAwsApiGatewayBasePathMappingReconciler() AwsApiGatewayBasePathMappingRec
 	return AwsApiGatewayBasePathMappingReconciler{}
 }
 
+func (m AwsApiGatewayBasePathMappingReconciler) ResourceTypes() []string {
+	return []string{aws.AwsApiGatewayBasePathMappingResourceType, aws.AwsApiGatewayV2MappingResourceType}
+}
+
 func (m AwsApiGatewayBasePathMappingReconciler) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 	newRemoteResources := make([]*resource.Resource, 0)
 	managedApiMapping := make([]*resource.Resource, 0)
@@ -14,6 +14,10 @@ func NewAwsApiGatewayDomainNamesReconciler() AwsApiGatewayDomainNamesReconciler
 	return AwsApiGatewayDomainNamesReconciler{}
 }
 
+func (m AwsApiGatewayDomainNamesReconciler) ResourceTypes() []string {
+	return []string{aws.AwsApiGatewayDomainNameResourceType, aws.AwsApiGatewayV2DomainNameResourceType}
+}
+
 func (m AwsApiGatewayDomainNamesReconciler) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 	newRemoteResources := make([]*resource.Resource, 0)
 	managedDomainNames := make([]*resource.Resource, 0)
@@ -15,6 +15,10 @@ func NewAwsConsoleApiGatewayGatewayResponse() AwsConsoleApiGatewayGatewayRespons
 	return AwsConsoleApiGatewayGatewayResponse{}
 }
 
+func (m AwsConsoleApiGatewayGatewayResponse) ResourceTypes() []string {
+	return []string{aws.AwsApiGatewayGatewayResponseResourceType}
+}
+
 func (m AwsConsoleApiGatewayGatewayResponse) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 	newRemoteResources := make([]*resource.Resource, 0)
 
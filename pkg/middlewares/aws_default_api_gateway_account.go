@@ -13,6 +13,10 @@ func NewAwsDefaultApiGatewayAccount() AwsDefaultApiGatewayAccount {
 	return AwsDefaultApiGatewayAccount{}
 }
 
+func (m AwsDefaultApiGatewayAccount) ResourceTypes() []string {
+	return []string{aws.AwsApiGatewayAccountResourceType}
+}
+
 func (m AwsDefaultApiGatewayAccount) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 
 	newRemoteResources := make([]*resource.Resource, 0)
@@ -13,6 +13,10 @@ func NewAwsDefaultSecurityGroupRule() AwsDefaultSecurityGroupRule {
 	return AwsDefaultSecurityGroupRule{}
 }
 
+func (m AwsDefaultSecurityGroupRule) ResourceTypes() []string {
+	return []string{aws.AwsSecurityGroupRuleResourceType, aws.AwsDefaultSecurityGroupResourceType}
+}
+
 func (m AwsDefaultSecurityGroupRule) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 	newRemoteResources := make([]*resource.Resource, 0)
 
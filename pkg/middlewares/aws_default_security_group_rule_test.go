@@ -313,3 +313,40 @@ func TestAwsDefaultSecurityGroupRule_Execute(t *testing.T) {
 		})
 	}
 }
+
+func TestAwsDefaultSecurityGroupRule_ExecuteInChain(t *testing.T) {
+	defaultSecurityGroup := &resource.Resource{
+		Id:    "default-sg",
+		Type:  aws.AwsDefaultSecurityGroupResourceType,
+		Attrs: &resource.Attributes{},
+	}
+	bucket := &resource.Resource{
+		Id:    "bucket",
+		Type:  aws.AwsS3BucketResourceType,
+		Attrs: &resource.Attributes{},
+	}
+	defaultIngress := &resource.Resource{
+		Id:   "default-ingress",
+		Type: aws.AwsSecurityGroupRuleResourceType,
+		Attrs: &resource.Attributes{
+			"type":              "ingress",
+			"from_port":         float64(0),
+			"to_port":           float64(0),
+			"protocol":          "-1",
+			"security_group_id": "default-sg",
+			"self":              true,
+		},
+	}
+
+	remoteResources := []*resource.Resource{bucket, defaultSecurityGroup, defaultIngress}
+	resourcesFromState := []*resource.Resource{}
+
+	if err := NewChain(NewAwsDefaultSecurityGroupRule()).Execute(&remoteResources, &resourcesFromState); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	expected := []*resource.Resource{bucket, defaultSecurityGroup}
+	if !reflect.DeepEqual(remoteResources, expected) {
+		t.Fatalf("Expected results mismatch")
+	}
+}
@@ -18,6 +18,10 @@ func NewAwsDefaultSQSQueuePolicy() AwsDefaultSQSQueuePolicy {
 	return AwsDefaultSQSQueuePolicy{}
 }
 
+func (m AwsDefaultSQSQueuePolicy) ResourceTypes() []string {
+	return []string{aws.AwsSqsQueuePolicyResourceType}
+}
+
 func (m AwsDefaultSQSQueuePolicy) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 	newRemoteResources := make([]*resource.Resource, 0)
 	for _, res := range *remoteResources {
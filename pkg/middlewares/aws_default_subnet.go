@@ -14,6 +14,10 @@ func NewAwsDefaultSubnet() AwsDefaultSubnet {
 	return AwsDefaultSubnet{}
 }
 
+func (m AwsDefaultSubnet) ResourceTypes() []string {
+	return []string{aws.AwsDefaultSubnetResourceType}
+}
+
 func (m AwsDefaultSubnet) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 
 	newRemoteResources := make([]*resource.Resource, 0)
@@ -14,6 +14,10 @@ func NewAwsDefaultVPC() AwsDefaultVPC {
 	return AwsDefaultVPC{}
 }
 
+func (m AwsDefaultVPC) ResourceTypes() []string {
+	return []string{aws.AwsDefaultVpcResourceType}
+}
+
 func (m AwsDefaultVPC) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 
 	newRemoteResources := make([]*resource.Resource, 0)
@@ -36,6 +36,10 @@ func NewAwsS3BucketPublicAccessBlockReconciler() *AwsS3BucketPublicAccessBlockRe
 	return &AwsS3BucketPublicAccessBlockReconciler{}
 }
 
+func (r AwsS3BucketPublicAccessBlockReconciler) ResourceTypes() []string {
+	return []string{aws.AwsS3BucketPublicAccessBlockResourceType}
+}
+
 func (r AwsS3BucketPublicAccessBlockReconciler) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 
 	newRemoteResources := make([]*resource.Resource, 0)
@@ -13,15 +13,107 @@ func NewChain(middlewares ...Middleware) Chain {
 	return middlewares
 }
 
+// Execute runs every middleware of the chain. Resources are partitioned by type so that a TypedMiddleware
+// only receives the resources of its types, other middlewares still receive every resource.
+// Every resource stays in memory for the whole chain, the index only avoids walking through unrelated types.
 func (c Chain) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
+	remoteIndex := newTypeIndex(*remoteResources)
+	stateIndex := newTypeIndex(*resourcesFromState)
+
 	for _, middleware := range c {
-		logrus.WithFields(logrus.Fields{
-			"middleware": fmt.Sprintf("%T", middleware),
-		}).Debug("Starting middleware")
-		err := middleware.Execute(remoteResources, resourcesFromState)
+		logrus.WithFields(logrus.Fields{"middleware": fmt.Sprintf("%T", middleware)}).Debug("Starting middleware")
+
+		var types []string
+		if typed, ok := middleware.(TypedMiddleware); ok {
+			types = typed.ResourceTypes()
+		}
+
+		remote := remoteIndex.take(types)
+		state := stateIndex.take(types)
+		err := middleware.Execute(&remote, &state)
+		remoteIndex.add(remote)
+		stateIndex.add(state)
 		if err != nil {
 			return err
 		}
 	}
+
+	*remoteResources = remoteIndex.take(nil)
+	*resourcesFromState = stateIndex.take(nil)
+
 	return nil
 }
+
+// typeIndex partitions resources by type, keeping types in their order of first appearance.
+// Resources are only partitioned once a typed middleware needs them, so that a chain of untyped
+// middlewares keeps the order in which they returned resources.
+type typeIndex struct {
+	flat      []*resource.Resource
+	indexed   bool
+	types     []string
+	resources map[string][]*resource.Resource
+}
+
+func newTypeIndex(resources []*resource.Resource) *typeIndex {
+	return &typeIndex{flat: resources}
+}
+
+func (i *typeIndex) add(resources []*resource.Resource) {
+	if !i.indexed {
+		i.flat = append(i.flat, resources...)
+		return
+	}
+	for _, res := range resources {
+		ty := res.ResourceType()
+		if _, exist := i.resources[ty]; !exist {
+			i.types = append(i.types, ty)
+		}
+		i.resources[ty] = append(i.resources[ty], res)
+	}
+}
+
+// take removes resources of the given types from the index and returns them, every resource is taken when types is nil
+func (i *typeIndex) take(types []string) []*resource.Resource {
+	if types == nil {
+		return i.takeAll()
+	}
+
+	if !i.indexed {
+		flat := i.flat
+		i.flat = nil
+		i.indexed = true
+		i.resources = make(map[string][]*resource.Resource)
+		i.add(flat)
+	}
+
+	result := make([]*resource.Resource, 0)
+	for _, ty := range types {
+		result = append(result, i.resources[ty]...)
+		delete(i.resources, ty)
+	}
+	remainingTypes := make([]string, 0, len(i.resources))
+	for _, ty := range i.types {
+		if _, exist := i.resources[ty]; exist {
+			remainingTypes = append(remainingTypes, ty)
+		}
+	}
+	i.types = remainingTypes
+	return result
+}
+
+func (i *typeIndex) takeAll() []*resource.Resource {
+	if !i.indexed {
+		flat := i.flat
+		i.flat = nil
+		return flat
+	}
+
+	result := make([]*resource.Resource, 0)
+	for _, ty := range i.types {
+		result = append(result, i.resources[ty]...)
+	}
+	i.indexed = false
+	i.types = nil
+	i.resources = nil
+	return result
+}
@@ -83,3 +83,57 @@ func TestChainMiddlewareErrorShouldStopExecution(t *testing.T) {
 	}
 
 }
+
+type FakeTypedMiddleware struct {
+	Types    []string
+	Received []*resource.Resource
+}
+
+func (m *FakeTypedMiddleware) ResourceTypes() []string {
+	return m.Types
+}
+
+func (m *FakeTypedMiddleware) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
+	m.Received = append(m.Received, *remoteResources...)
+	m.Received = append(m.Received, *resourcesFromState...)
+	// Drop every remote resource to check that other types are left untouched
+	*remoteResources = []*resource.Resource{}
+	return nil
+}
+
+func TestChainMiddlewareTypedMiddlewareOnlyReceivesItsTypes(t *testing.T) {
+	bucket := &resource.Resource{Id: "bucket", Type: "aws_s3_bucket"}
+	policy := &resource.Resource{Id: "policy", Type: "aws_s3_bucket_policy"}
+	role := &resource.Resource{Id: "role", Type: "aws_iam_role"}
+	stateBucket := &resource.Resource{Id: "bucket", Type: "aws_s3_bucket"}
+	stateRole := &resource.Resource{Id: "role", Type: "aws_iam_role"}
+
+	typedMiddleware := &FakeTypedMiddleware{Types: []string{"aws_s3_bucket", "aws_s3_bucket_policy"}}
+
+	middleware := NewChain(typedMiddleware)
+	remoteResources := []*resource.Resource{bucket, role, policy}
+	stateResources := []*resource.Resource{stateRole, stateBucket}
+	err := middleware.Execute(&remoteResources, &stateResources)
+
+	if err != nil {
+		t.Error("A middleware returned an error")
+	}
+
+	expectedReceived := []*resource.Resource{bucket, policy, stateBucket}
+	if len(typedMiddleware.Received) != len(expectedReceived) {
+		t.Fatalf("Typed middleware received %d resources, expected %d", len(typedMiddleware.Received), len(expectedReceived))
+	}
+	for i, res := range expectedReceived {
+		if typedMiddleware.Received[i] != res {
+			t.Errorf("Typed middleware received %s at index %d, expected %s", typedMiddleware.Received[i].ResourceId(), i, res.ResourceId())
+		}
+	}
+
+	if len(remoteResources) != 1 || remoteResources[0] != role {
+		t.Error("Remote resources of other types should be left untouched")
+	}
+
+	if len(stateResources) != 2 || stateResources[0] != stateRole || stateResources[1] != stateBucket {
+		t.Error("State resources should be kept in their order")
+	}
+}
@@ -14,6 +14,10 @@ func NewGoogleComputeInstanceGroupManagerReconciler() *GoogleComputeInstanceGrou
 	return &GoogleComputeInstanceGroupManagerReconciler{}
 }
 
+func (a GoogleComputeInstanceGroupManagerReconciler) ResourceTypes() []string {
+	return []string{google.GoogleComputeInstanceGroupResourceType, google.GoogleComputeInstanceGroupManagerResourceType}
+}
+
 func (a GoogleComputeInstanceGroupManagerReconciler) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 	var newStateResources []*resource.Resource
 
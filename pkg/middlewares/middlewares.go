@@ -5,3 +5,10 @@ import "github.com/snyk/driftctl/pkg/resource"
 type Middleware interface {
 	Execute(remoteResources, resourcesFromState *[]*resource.Resource) error
 }
+
+// TypedMiddleware is a middleware that only reads and modifies resources of the returned types.
+// The chain runs it on those resources only instead of the whole remote and state slices.
+type TypedMiddleware interface {
+	Middleware
+	ResourceTypes() []string
+}
@@ -13,6 +13,10 @@ func NewRoute53DefaultZoneRecordSanitizer() Route53DefaultZoneRecordSanitizer {
 	return Route53DefaultZoneRecordSanitizer{}
 }
 
+func (m Route53DefaultZoneRecordSanitizer) ResourceTypes() []string {
+	return []string{aws.AwsRoute53RecordResourceType}
+}
+
 func (m Route53DefaultZoneRecordSanitizer) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 
 	newRemoteResources := make([]*resource.Resource, 0)
@@ -19,6 +19,10 @@ func NewRoute53RecordIDReconcilier() Route53RecordIDReconcilier {
 	return Route53RecordIDReconcilier{}
 }
 
+func (m Route53RecordIDReconcilier) ResourceTypes() []string {
+	return []string{aws.AwsRoute53RecordResourceType}
+}
+
 func (m Route53RecordIDReconcilier) Execute(_, resourcesFromState *[]*resource.Resource) error {
 
 	for _, stateResource := range *resourcesFromState {
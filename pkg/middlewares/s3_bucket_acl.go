@@ -14,6 +14,10 @@ func NewS3BucketAcl() S3BucketAcl {
 	return S3BucketAcl{}
 }
 
+func (m S3BucketAcl) ResourceTypes() []string {
+	return []string{aws.AwsS3BucketResourceType}
+}
+
 func (m S3BucketAcl) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
 
 	for _, iacResource := range *resourcesFromState {
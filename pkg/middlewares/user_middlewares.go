@@ -0,0 +1,347 @@
+package middlewares
+
+import (
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/resource"
+	"github.com/snyk/driftctl/pkg/rules"
+)
+
+type UserMiddlewareKind string
+
+const (
+	// UserMiddlewareKindDefaults drops remote resources matching the expression when they are not found in IaC,
+	// the same way we ignore resources created by default by cloud providers.
+	UserMiddlewareKindDefaults UserMiddlewareKind = "defaults"
+	// UserMiddlewareKindEquivalent gives the ID of a state resource to a remote resource of the same type
+	// when the expression matches, so both are considered as the same resource.
+	UserMiddlewareKindEquivalent UserMiddlewareKind = "equivalent"
+	// UserMiddlewareKindExpand creates child resources from an attribute embedded in state and remote resources.
+	UserMiddlewareKindExpand UserMiddlewareKind = "expand"
+)
+
+// UserMiddlewareDefinition is a middleware as written by users in a middlewares file
+type UserMiddlewareDefinition struct {
+	Name         string             `json:"name"`
+	Kind         UserMiddlewareKind `json:"kind"`
+	ResourceType string             `json:"resource_type"`
+	// Expression is a CEL predicate, it can use the remote variable for defaults middlewares
+	// and both state and remote variables for equivalent middlewares
+	Expression string `json:"expression"`
+	// Attribute, ChildType and ChildId are only used by expand middlewares.
+	// ChildId is a CEL expression returning a string, it can use the parent and child variables.
+	Attribute string `json:"attribute"`
+	ChildType string `json:"child_type"`
+	ChildId   string `json:"child_id"`
+}
+
+type userMiddlewaresFile struct {
+	Middlewares []UserMiddlewareDefinition `json:"middlewares"`
+}
+
+// ReadUserMiddlewares builds a chain from every middleware found in the given YAML file
+func ReadUserMiddlewares(path string, resourceFactory resource.ResourceFactory) (Chain, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read middlewares file %s", path)
+	}
+
+	var f userMiddlewaresFile
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse middlewares file %s", path)
+	}
+
+	return NewUserMiddlewares(resourceFactory, f.Middlewares...)
+}
+
+// NewUserMiddlewares builds a chain of middlewares from user definitions, keeping their order
+func NewUserMiddlewares(resourceFactory resource.ResourceFactory, definitions ...UserMiddlewareDefinition) (Chain, error) {
+	chain := make(Chain, 0, len(definitions))
+	for _, def := range definitions {
+		if def.ResourceType == "" {
+			return nil, errors.Errorf("missing resource_type for middleware '%s'", def.Name)
+		}
+
+		switch def.Kind {
+		case UserMiddlewareKindDefaults:
+			expr, err := rules.CompileExpression(def.Name, def.Expression, "remote")
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, userDefaults{def.Name, def.ResourceType, expr})
+		case UserMiddlewareKindEquivalent:
+			expr, err := rules.CompileExpression(def.Name, def.Expression, "state", "remote")
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, userEquivalent{def.Name, def.ResourceType, expr})
+		case UserMiddlewareKindExpand:
+			if def.Attribute == "" || def.ChildType == "" || def.ChildId == "" {
+				return nil, errors.Errorf("attribute, child_type and child_id are required for expand middleware '%s'", def.Name)
+			}
+			childId, err := rules.CompileExpression(def.Name, def.ChildId, "parent", "child")
+			if err != nil {
+				return nil, err
+			}
+			chain = append(chain, userExpander{
+				name:            def.Name,
+				resourceType:    def.ResourceType,
+				attribute:       def.Attribute,
+				childType:       def.ChildType,
+				childId:         childId,
+				resourceFactory: resourceFactory,
+			})
+		default:
+			return nil, errors.Errorf(
+				"unsupported kind '%s' for middleware '%s', valid kinds are: %s, %s, %s",
+				def.Kind, def.Name, UserMiddlewareKindDefaults, UserMiddlewareKindEquivalent, UserMiddlewareKindExpand,
+			)
+		}
+	}
+	return chain, nil
+}
+
+// userDefaults ignores remote resources matching a user expression if they are not managed by IaC
+type userDefaults struct {
+	name         string
+	resourceType string
+	expression   *rules.Expression
+}
+
+func (m userDefaults) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
+	stateResources := indexResourcesByID(*resourcesFromState, m.resourceType)
+	newRemoteResources := make([]*resource.Resource, 0, len(*remoteResources))
+
+	for _, remoteResource := range *remoteResources {
+		if remoteResource.ResourceType() != m.resourceType ||
+			!m.expression.Matches(map[string]interface{}{"remote": rules.ResourceToMap(remoteResource)}) {
+			newRemoteResources = append(newRemoteResources, remoteResource)
+			continue
+		}
+
+		existInState := false
+		for _, stateResource := range stateResources[remoteResource.ResourceId()] {
+			if remoteResource.Equal(stateResource) {
+				existInState = true
+				break
+			}
+		}
+
+		if existInState {
+			newRemoteResources = append(newRemoteResources, remoteResource)
+			continue
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"id":         remoteResource.ResourceId(),
+			"type":       remoteResource.ResourceType(),
+			"middleware": m.name,
+		}).Debug("Ignoring default resource as it is not managed by IaC")
+	}
+
+	*remoteResources = newRemoteResources
+
+	return nil
+}
+
+// indexResourcesByID returns resources of the given type grouped by ID
+func indexResourcesByID(resources []*resource.Resource, resourceType string) map[string][]*resource.Resource {
+	index := make(map[string][]*resource.Resource)
+	for _, res := range resources {
+		if res.ResourceType() != resourceType {
+			continue
+		}
+		index[res.ResourceId()] = append(index[res.ResourceId()], res)
+	}
+	return index
+}
+
+// filterResourcesByType returns resources of the given type, keeping their order
+func filterResourcesByType(resources []*resource.Resource, resourceType string) []*resource.Resource {
+	filtered := make([]*resource.Resource, 0)
+	for _, res := range resources {
+		if res.ResourceType() == resourceType {
+			filtered = append(filtered, res)
+		}
+	}
+	return filtered
+}
+
+// userEquivalent reconciles remote resources with state resources having a different ID
+type userEquivalent struct {
+	name         string
+	resourceType string
+	expression   *rules.Expression
+}
+
+func (m userEquivalent) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
+	stateResources := filterResourcesByType(*resourcesFromState, m.resourceType)
+	remoteResourcesOfType := filterResourcesByType(*remoteResources, m.resourceType)
+
+	// Resources already matching each other by ID must not be reconciled
+	remoteByID := indexResourcesByID(remoteResourcesOfType, m.resourceType)
+	matched := make(map[*resource.Resource]struct{})
+	for _, stateResource := range stateResources {
+		for _, remoteResource := range remoteByID[stateResource.ResourceId()] {
+			if _, ok := matched[remoteResource]; !ok && stateResource.Equal(remoteResource) {
+				matched[stateResource] = struct{}{}
+				matched[remoteResource] = struct{}{}
+				break
+			}
+		}
+	}
+
+	// State resources are only converted once for the expression
+	stateMaps := make(map[*resource.Resource]map[string]interface{}, len(stateResources))
+	for _, remoteResource := range remoteResourcesOfType {
+		if _, ok := matched[remoteResource]; ok {
+			continue
+		}
+
+		remoteMap := rules.ResourceToMap(remoteResource)
+		for _, stateResource := range stateResources {
+			if _, ok := matched[stateResource]; ok {
+				continue
+			}
+
+			stateMap, exist := stateMaps[stateResource]
+			if !exist {
+				stateMap = rules.ResourceToMap(stateResource)
+				stateMaps[stateResource] = stateMap
+			}
+			vars := map[string]interface{}{
+				"state":  stateMap,
+				"remote": remoteMap,
+			}
+			if !m.expression.Matches(vars) {
+				continue
+			}
+
+			logrus.WithFields(logrus.Fields{
+				"old_id":     remoteResource.ResourceId(),
+				"new_id":     stateResource.ResourceId(),
+				"type":       remoteResource.ResourceType(),
+				"middleware": m.name,
+			}).Debug("Reconciled remote resource with an equivalent state resource")
+
+			remoteResource.Id = stateResource.ResourceId()
+			if remoteResource.Attrs != nil {
+				if _, exist := remoteResource.Attrs.Get("id"); exist {
+					_ = remoteResource.Attrs.SafeSet([]string{"id"}, stateResource.ResourceId())
+				}
+			}
+			matched[stateResource] = struct{}{}
+			break
+		}
+	}
+
+	return nil
+}
+
+// userExpander explodes an attribute embedded in state resources to dedicated child resources.
+// The attribute can either be an object or a list of objects, each object becoming a child resource.
+type userExpander struct {
+	name            string
+	resourceType    string
+	attribute       string
+	childType       string
+	childId         *rules.Expression
+	resourceFactory resource.ResourceFactory
+}
+
+func (m userExpander) Execute(remoteResources, resourcesFromState *[]*resource.Resource) error {
+	newStateResources, err := m.expand(*resourcesFromState)
+	if err != nil {
+		return err
+	}
+	// Remote parents are expanded the same way, otherwise they would keep the attribute removed from state parents
+	// and be reported as drifted in deep mode
+	newRemoteResources, err := m.expand(*remoteResources)
+	if err != nil {
+		return err
+	}
+	*resourcesFromState = newStateResources
+	*remoteResources = newRemoteResources
+	return nil
+}
+
+// expand returns the given resources followed by children created from their embedded attribute.
+// Children already in the list, e.g. enumerated from the remote, are not created twice.
+func (m userExpander) expand(resources []*resource.Resource) ([]*resource.Resource, error) {
+	existingChildren := make(map[string]struct{})
+	for _, res := range resources {
+		if res.ResourceType() == m.childType {
+			existingChildren[res.ResourceId()] = struct{}{}
+		}
+	}
+
+	newList := make([]*resource.Resource, 0, len(resources))
+	for _, res := range resources {
+		newList = append(newList, res)
+
+		if res.ResourceType() != m.resourceType || res.Attrs == nil {
+			continue
+		}
+
+		value, exist := res.Attrs.Get(m.attribute)
+		if !exist || value == nil {
+			continue
+		}
+
+		var children []interface{}
+		switch v := value.(type) {
+		case []interface{}:
+			children = v
+		default:
+			children = []interface{}{v}
+		}
+
+		for _, child := range children {
+			attrs, ok := child.(map[string]interface{})
+			if !ok {
+				logrus.WithFields(logrus.Fields{
+					"id":         res.ResourceId(),
+					"type":       res.ResourceType(),
+					"middleware": m.name,
+				}).Debug("Skipping embedded attribute that is not an object")
+				continue
+			}
+
+			out, err := m.childId.Eval(map[string]interface{}{
+				"parent": rules.ResourceToMap(res),
+				"child":  attrs,
+			})
+			if err != nil {
+				return nil, err
+			}
+			id, ok := out.(string)
+			if !ok {
+				return nil, errors.Errorf("child_id expression for middleware '%s' must return a string, got %T", m.name, out)
+			}
+			if _, exist := existingChildren[id]; exist {
+				continue
+			}
+			existingChildren[id] = struct{}{}
+
+			data := make(map[string]interface{}, len(attrs)+1)
+			for k, v := range attrs {
+				data[k] = v
+			}
+			data["id"] = id
+
+			newChild := m.resourceFactory.CreateAbstractResource(m.childType, id, data)
+			newList = append(newList, newChild)
+			logrus.WithFields(logrus.Fields{
+				"id":         newChild.ResourceId(),
+				"type":       newChild.ResourceType(),
+				"middleware": m.name,
+			}).Debug("Created new resource from embedded attribute")
+		}
+
+		res.Attrs.SafeDelete([]string{m.attribute})
+	}
+	return newList, nil
+}
@@ -0,0 +1,158 @@
+package middlewares
+
+import (
+	"testing"
+
+	"github.com/snyk/driftctl/pkg/resource"
+	"github.com/snyk/driftctl/pkg/resource/aws"
+	"github.com/snyk/driftctl/pkg/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadUserMiddlewares(t *testing.T) {
+	chain, err := ReadUserMiddlewares("testdata/user_middlewares/middlewares.yml", &terraform.MockResourceFactory{})
+	require.NoError(t, err)
+	require.Len(t, chain, 3)
+	assert.IsType(t, userDefaults{}, chain[0])
+	assert.IsType(t, userEquivalent{}, chain[1])
+	assert.IsType(t, userExpander{}, chain[2])
+
+	_, err = ReadUserMiddlewares("testdata/user_middlewares/invalid_kind.yml", &terraform.MockResourceFactory{})
+	assert.EqualError(t, err, "unsupported kind 'foobar' for middleware 'unknown', valid kinds are: defaults, equivalent, expand")
+
+	_, err = NewUserMiddlewares(nil, UserMiddlewareDefinition{Name: "no-child", Kind: UserMiddlewareKindExpand, ResourceType: aws.AwsIamRoleResourceType})
+	assert.EqualError(t, err, "attribute, child_type and child_id are required for expand middleware 'no-child'")
+}
+
+func TestUserDefaults_Execute(t *testing.T) {
+	chain, err := NewUserMiddlewares(nil, UserMiddlewareDefinition{
+		Name:         "service-linked-roles",
+		Kind:         UserMiddlewareKindDefaults,
+		ResourceType: aws.AwsIamRoleResourceType,
+		Expression:   "remote.attributes.path.startsWith('/aws-service-role/')",
+	})
+	require.NoError(t, err)
+
+	remoteResources := []*resource.Resource{
+		{Id: "default", Type: aws.AwsIamRoleResourceType, Attrs: &resource.Attributes{"path": "/aws-service-role/foo"}},
+		{Id: "managed-default", Type: aws.AwsIamRoleResourceType, Attrs: &resource.Attributes{"path": "/aws-service-role/bar"}},
+		{Id: "custom", Type: aws.AwsIamRoleResourceType, Attrs: &resource.Attributes{"path": "/"}},
+		{Id: "no-path", Type: aws.AwsIamRoleResourceType, Attrs: &resource.Attributes{}},
+		{Id: "default", Type: aws.AwsIamPolicyResourceType, Attrs: &resource.Attributes{"path": "/aws-service-role/foo"}},
+	}
+	resourcesFromState := []*resource.Resource{
+		{Id: "managed-default", Type: aws.AwsIamRoleResourceType, Attrs: &resource.Attributes{}},
+	}
+
+	require.NoError(t, chain.Execute(&remoteResources, &resourcesFromState))
+
+	ids := make([]string, 0, len(remoteResources))
+	for _, res := range remoteResources {
+		ids = append(ids, res.ResourceType()+"."+res.ResourceId())
+	}
+	assert.Equal(t, []string{
+		"aws_iam_role.managed-default",
+		"aws_iam_role.custom",
+		"aws_iam_role.no-path",
+		"aws_iam_policy.default",
+	}, ids)
+}
+
+func TestUserEquivalent_Execute(t *testing.T) {
+	chain, err := NewUserMiddlewares(nil, UserMiddlewareDefinition{
+		Name:         "bucket-by-name",
+		Kind:         UserMiddlewareKindEquivalent,
+		ResourceType: aws.AwsS3BucketResourceType,
+		Expression:   "state.attributes.bucket == remote.attributes.bucket",
+	})
+	require.NoError(t, err)
+
+	remoteResources := []*resource.Resource{
+		{Id: "arn:aws:s3:::foo", Type: aws.AwsS3BucketResourceType, Attrs: &resource.Attributes{"id": "arn:aws:s3:::foo", "bucket": "foo"}},
+		{Id: "bar", Type: aws.AwsS3BucketResourceType, Attrs: &resource.Attributes{"bucket": "bar"}},
+		{Id: "arn:aws:s3:::bar", Type: aws.AwsS3BucketResourceType, Attrs: &resource.Attributes{"bucket": "bar"}},
+		{Id: "arn:aws:s3:::baz", Type: aws.AwsS3BucketResourceType, Attrs: &resource.Attributes{"bucket": "baz"}},
+	}
+	resourcesFromState := []*resource.Resource{
+		{Id: "foo", Type: aws.AwsS3BucketResourceType, Attrs: &resource.Attributes{"bucket": "foo"}},
+		{Id: "bar", Type: aws.AwsS3BucketResourceType, Attrs: &resource.Attributes{"bucket": "bar"}},
+	}
+
+	require.NoError(t, chain.Execute(&remoteResources, &resourcesFromState))
+
+	assert.Equal(t, "foo", remoteResources[0].ResourceId())
+	assert.Equal(t, "foo", (*remoteResources[0].Attrs)["id"])
+	assert.Equal(t, "bar", remoteResources[1].ResourceId())
+	// bar is already matched by ID, so the second remote bucket stays unmanaged
+	assert.Equal(t, "arn:aws:s3:::bar", remoteResources[2].ResourceId())
+	assert.Equal(t, "arn:aws:s3:::baz", remoteResources[3].ResourceId())
+}
+
+func TestUserExpander_Execute(t *testing.T) {
+	factory := &terraform.MockResourceFactory{}
+	factory.On("CreateAbstractResource", aws.AwsIamRolePolicyResourceType, "role:first", map[string]interface{}{
+		"id":     "role:first",
+		"name":   "first",
+		"policy": "{}",
+	}).Twice().Return(&resource.Resource{Id: "role:first", Type: aws.AwsIamRolePolicyResourceType})
+	factory.On("CreateAbstractResource", aws.AwsIamRolePolicyResourceType, "role:second", map[string]interface{}{
+		"id":   "role:second",
+		"name": "second",
+	}).Once().Return(&resource.Resource{Id: "role:second", Type: aws.AwsIamRolePolicyResourceType})
+
+	chain, err := NewUserMiddlewares(factory, UserMiddlewareDefinition{
+		Name:         "inline-policies",
+		Kind:         UserMiddlewareKindExpand,
+		ResourceType: aws.AwsIamRoleResourceType,
+		Attribute:    "inline_policy",
+		ChildType:    aws.AwsIamRolePolicyResourceType,
+		ChildId:      "parent.id + ':' + child.name",
+	})
+	require.NoError(t, err)
+
+	// Remote parents are expanded too, children already enumerated are kept as is
+	remoteResources := []*resource.Resource{
+		{
+			Id:   "role",
+			Type: aws.AwsIamRoleResourceType,
+			Attrs: &resource.Attributes{
+				"name": "role",
+				"inline_policy": []interface{}{
+					map[string]interface{}{"name": "first", "policy": "{}"},
+					map[string]interface{}{"name": "second"},
+				},
+			},
+		},
+		{Id: "role:second", Type: aws.AwsIamRolePolicyResourceType, Attrs: &resource.Attributes{"name": "second"}},
+	}
+	resourcesFromState := []*resource.Resource{
+		{
+			Id:   "role",
+			Type: aws.AwsIamRoleResourceType,
+			Attrs: &resource.Attributes{
+				"name": "role",
+				"inline_policy": []interface{}{
+					map[string]interface{}{"name": "first", "policy": "{}"},
+					map[string]interface{}{"name": "second"},
+				},
+			},
+		},
+		{Id: "other", Type: aws.AwsIamRoleResourceType, Attrs: &resource.Attributes{"name": "other"}},
+	}
+
+	require.NoError(t, chain.Execute(&remoteResources, &resourcesFromState))
+	factory.AssertExpectations(t)
+
+	assert.Equal(t, []*resource.Resource{
+		{Id: "role", Type: aws.AwsIamRoleResourceType, Attrs: &resource.Attributes{"name": "role"}},
+		{Id: "role:first", Type: aws.AwsIamRolePolicyResourceType},
+		{Id: "role:second", Type: aws.AwsIamRolePolicyResourceType},
+		{Id: "other", Type: aws.AwsIamRoleResourceType, Attrs: &resource.Attributes{"name": "other"}},
+	}, resourcesFromState)
+	assert.Equal(t, []*resource.Resource{
+		{Id: "role", Type: aws.AwsIamRoleResourceType, Attrs: &resource.Attributes{"name": "role"}},
+		{Id: "role:first", Type: aws.AwsIamRolePolicyResourceType},
+		{Id: "role:second", Type: aws.AwsIamRolePolicyResourceType, Attrs: &resource.Attributes{"name": "second"}},
+	}, remoteResources)
+}
@@ -75,11 +75,32 @@ func (p *ParallelRunner) wait() {
 }
 
 func (p *ParallelRunner) Run(runnable func() (interface{}, error)) {
+	p.run(runnable, true)
+}
+
+// RunUnbounded runs a routine that does not take one of the runner slots, e.g. a routine scheduling other ones
+func (p *ParallelRunner) RunUnbounded(runnable func() (interface{}, error)) {
+	p.run(runnable, false)
+}
+
+// Drain discards remaining results in background, so routines still running when the runner is stopped
+// are not blocked forever on sending their result
+func (p *ParallelRunner) Drain() {
+	resChan := p.Read()
+	go func() {
+		for range resChan {
+		}
+	}()
+}
+
+func (p *ParallelRunner) run(runnable func() (interface{}, error), bounded bool) {
 	p.wg.Add(1)
 	go func() {
-		if err := p.sem.Acquire(p.ctx, 1); err == nil {
-			// only release if sem was acquired
-			defer p.sem.Release(1)
+		if bounded {
+			if err := p.sem.Acquire(p.ctx, 1); err == nil {
+				// only release if sem was acquired
+				defer p.sem.Release(1)
+			}
 		}
 		defer p.wg.Done()
 		// Prevent new routines executions if we already got an error from another routine
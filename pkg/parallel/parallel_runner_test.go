@@ -72,3 +72,43 @@ Loop:
 	assert.Equal(err, runner.Err())
 	assert.Less(val, 100)
 }
+
+func TestParallelRunner_RunUnbounded(t *testing.T) {
+	assert := assert.New(t)
+
+	runner := NewParallelRunner(context.TODO(), 1)
+
+	// The unbounded routine waits for a bounded one, it would deadlock if it took the only slot
+	done := make(chan struct{})
+	runner.RunUnbounded(func() (interface{}, error) {
+		<-done
+		return 1, nil
+	})
+	runner.Run(func() (interface{}, error) {
+		close(done)
+		return 1, nil
+	})
+
+	val := 0
+	for res := range runner.Read() {
+		val += res.(int)
+	}
+
+	assert.Nil(runner.Err())
+	assert.Equal(2, val)
+}
+
+func TestParallelRunner_Drain(t *testing.T) {
+	runner := NewParallelRunner(context.TODO(), 10)
+
+	for i := 0; i < 100; i++ {
+		runner.Run(func() (interface{}, error) {
+			return 1, nil
+		})
+	}
+	runner.Drain()
+
+	// Read is closed once every routine sent its result
+	for range runner.Read() {
+	}
+}
@@ -16,6 +16,17 @@ const (
 	DetailsFetchingPhase
 )
 
+var scanningPhaseNames = map[ScanningPhase]string{
+	EnumerationPhase:     "enumeration",
+	DetailsFetchingPhase: "details_fetching",
+}
+
+const RemoteAccessDeniedAlertKind = "remote_access_denied"
+
+func init() {
+	alerter.RegisterKind(RemoteAccessDeniedAlertKind, fromSerializedAlert)
+}
+
 type RemoteAccessDeniedAlert struct {
 	message       string
 	provider      string
@@ -49,6 +60,10 @@ func NewRemoteAccessDeniedAlert(provider string, scanErr *remoteerror.ResourceSc
 	return &RemoteAccessDeniedAlert{message, provider, scanningPhase}
 }
 
+func (e *RemoteAccessDeniedAlert) Kind() string {
+	return RemoteAccessDeniedAlertKind
+}
+
 func (e *RemoteAccessDeniedAlert) Message() string {
 	return e.message
 }
@@ -57,6 +72,27 @@ func (e *RemoteAccessDeniedAlert) ShouldIgnoreResource() bool {
 	return true
 }
 
+func (e *RemoteAccessDeniedAlert) Details() map[string]string {
+	return map[string]string{
+		"provider":       e.provider,
+		"scanning_phase": scanningPhaseNames[e.scanningPhase],
+	}
+}
+
+func fromSerializedAlert(alert *alerter.SerializedAlert) alerter.Alert {
+	denied := &RemoteAccessDeniedAlert{
+		message:       alert.Message(),
+		provider:      alert.Details()["provider"],
+		scanningPhase: -1,
+	}
+	for phase, name := range scanningPhaseNames {
+		if name == alert.Details()["scanning_phase"] {
+			denied.scanningPhase = phase
+		}
+	}
+	return denied
+}
+
 func (e *RemoteAccessDeniedAlert) GetProviderMessage() string {
 	var message string
 	if e.scanningPhase == DetailsFetchingPhase {
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/pkg/errors"
+)
+
+// terraformEndpointNames maps the endpoint IDs of the AWS SDK to the names of the endpoints block of the terraform
+// AWS provider, when they differ
+var terraformEndpointNames = map[string][]string{
+	"api.ecr":                 {"ecr"},
+	"apigateway":              {"apigateway", "apigatewayv2"},
+	"application-autoscaling": {"applicationautoscaling"},
+	"elasticloadbalancing":    {"elb", "elbv2"},
+}
+
+// scannedServices lists the endpoint names of the services driftctl calls, a default endpoint is set for each of them
+var scannedServices = []string{
+	"apigateway",
+	"apigatewayv2",
+	"applicationautoscaling",
+	"autoscaling",
+	"cloudformation",
+	"cloudfront",
+	"dynamodb",
+	"ec2",
+	"ecr",
+	"elasticache",
+	"elb",
+	"elbv2",
+	"iam",
+	"kms",
+	"lambda",
+	"rds",
+	"route53",
+	"s3",
+	"sns",
+	"sqs",
+	"sts",
+}
+
+// EndpointOptions overrides the endpoints of AWS services, e.g. to scan LocalStack
+type EndpointOptions struct {
+	// URL is the endpoint of every service without a specific endpoint
+	URL string
+	// Services maps the endpoint names of the terraform AWS provider, e.g. s3 or elbv2, to endpoints
+	Services map[string]string
+}
+
+func (o EndpointOptions) IsEmpty() bool {
+	return o.URL == "" && len(o.Services) == 0
+}
+
+func (o EndpointOptions) Validate() error {
+	if o.URL != "" {
+		if err := validateEndpoint(o.URL); err != nil {
+			return errors.Wrap(err, "invalid AWS endpoint")
+		}
+	}
+	names := make([]string, 0, len(o.Services))
+	for name := range o.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !isScannedService(name) {
+			return errors.Errorf(
+				"unknown AWS service '%s' in endpoints, valid services are: %s",
+				name, strings.Join(scannedServices, ", "),
+			)
+		}
+		if err := validateEndpoint(o.Services[name]); err != nil {
+			return errors.Wrapf(err, "invalid AWS endpoint for %s", name)
+		}
+	}
+	return nil
+}
+
+// isScannedService tells whether a name is one of scannedServices, endpoints of other services would be ignored
+// and the service would silently be called on AWS
+func isScannedService(name string) bool {
+	for _, service := range scannedServices {
+		if service == name {
+			return true
+		}
+	}
+	return false
+}
+
+func validateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return errors.Errorf("%s is not a URL, e.g. http://localhost:4566", endpoint)
+	}
+	return nil
+}
+
+// Endpoint returns the endpoint of a service, given by its terraform endpoint name, or an empty string to use the
+// default AWS endpoint
+func (o EndpointOptions) Endpoint(name string) string {
+	if endpoint, exist := o.Services[name]; exist {
+		return endpoint
+	}
+	return o.URL
+}
+
+// EndpointFor resolves the endpoints of AWS SDK clients, the default AWS endpoints are used for services that are not
+// overridden
+func (o EndpointOptions) EndpointFor(service, region string, opts ...func(*endpoints.Options)) (endpoints.ResolvedEndpoint, error) {
+	resolved, err := endpoints.DefaultResolver().EndpointFor(service, region, opts...)
+
+	names, exist := terraformEndpointNames[service]
+	if !exist {
+		names = []string{service}
+	}
+	for _, name := range names {
+		if endpoint := o.Endpoint(name); endpoint != "" {
+			if err != nil {
+				// Custom endpoints may be given for regions unknown to the SDK
+				return endpoints.ResolvedEndpoint{URL: endpoint, SigningRegion: region}, nil
+			}
+			resolved.URL = endpoint
+			return resolved, nil
+		}
+	}
+	return resolved, err
+}
+
+// TerraformEndpoints returns the endpoints block of the terraform AWS provider configuration, every scanned service
+// uses the default endpoint unless it has a specific one
+func (o EndpointOptions) TerraformEndpoints() []map[string]string {
+	if o.IsEmpty() {
+		return nil
+	}
+	block := make(map[string]string)
+	if o.URL != "" {
+		for _, name := range scannedServices {
+			block[name] = o.URL
+		}
+	}
+	for name, endpoint := range o.Services {
+		block[name] = endpoint
+	}
+	return []map[string]string{block}
+}
@@ -0,0 +1,55 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEndpointOptions_EndpointFor(t *testing.T) {
+	opts := EndpointOptions{
+		URL: "http://localhost:4566",
+		Services: map[string]string{
+			"s3":  "http://localhost:4572",
+			"ecr": "http://localhost:4510",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		opts    EndpointOptions
+		service string
+		region  string
+		wantURL string
+	}{
+		{name: "specific endpoint", opts: opts, service: "s3", region: "us-east-1", wantURL: "http://localhost:4572"},
+		{name: "endpoint named after the terraform provider", opts: opts, service: "api.ecr", region: "us-east-1", wantURL: "http://localhost:4510"},
+		{name: "default endpoint", opts: opts, service: "ec2", region: "us-east-1", wantURL: "http://localhost:4566"},
+		{name: "region unknown to the SDK", opts: opts, service: "sts", region: "local", wantURL: "http://localhost:4566"},
+		{name: "AWS endpoint", opts: EndpointOptions{Services: map[string]string{"s3": "http://localhost:4572"}}, service: "ec2", region: "us-east-1", wantURL: "https://ec2.us-east-1.amazonaws.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.opts.EndpointFor(tt.service, tt.region, func(o *endpoints.Options) {
+				o.ResolveUnknownService = true
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantURL, got.URL)
+			assert.Equal(t, tt.region, got.SigningRegion)
+		})
+	}
+}
+
+func TestEndpointOptions_TerraformEndpoints(t *testing.T) {
+	assert.Nil(t, EndpointOptions{}.TerraformEndpoints())
+
+	got := EndpointOptions{Services: map[string]string{"s3": "http://localhost:4572"}}.TerraformEndpoints()
+	assert.Equal(t, []map[string]string{{"s3": "http://localhost:4572"}}, got)
+
+	got = EndpointOptions{URL: "http://localhost:4566", Services: map[string]string{"s3": "http://localhost:4572"}}.TerraformEndpoints()
+	assert.Len(t, got, 1)
+	assert.Len(t, got[0], len(scannedServices))
+	assert.Equal(t, "http://localhost:4572", got[0]["s3"])
+	assert.Equal(t, "http://localhost:4566", got[0]["elbv2"])
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/client"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/snyk/driftctl/pkg/remote/retry"
+)
+
+// Retryer lets the AWS SDK decide which errors are retryable (throttling, 5xx, connection errors...)
+// while delays and limits come from the retry policy shared by every client of a scan
+type Retryer struct {
+	client.DefaultRetryer
+	policy *retry.Policy
+}
+
+func NewRetryer(policy *retry.Policy) *Retryer {
+	return &Retryer{
+		DefaultRetryer: client.DefaultRetryer{NumMaxRetries: policy.MaxAttempts() - 1},
+		policy:         policy,
+	}
+}
+
+func (r *Retryer) ShouldRetry(req *request.Request) bool {
+	return r.DefaultRetryer.ShouldRetry(req) && r.policy.ShouldRetry(req.RetryCount+1, req.Time, 0)
+}
+
+// RetryRules is only called by the SDK when a request is about to be retried
+func (r *Retryer) RetryRules(req *request.Request) time.Duration {
+	r.policy.CountRetry()
+	return r.policy.Backoff(req.RetryCount)
+}
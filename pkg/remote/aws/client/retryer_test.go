@@ -0,0 +1,50 @@
+package client
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/snyk/driftctl/pkg/remote/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetryer(t *testing.T) {
+	policy := retry.NewPolicy(retry.Options{MaxAttempts: 3, MaxElapsedTime: time.Minute, BaseDelay: time.Millisecond})
+	retryer := NewRetryer(policy)
+
+	assert.Equal(t, 2, retryer.MaxRetries())
+
+	serverError := &request.Request{
+		Time:         time.Now(),
+		Error:        awserr.New("InternalError", "internal error", nil),
+		HTTPResponse: &http.Response{StatusCode: http.StatusInternalServerError},
+	}
+	assert.True(t, retryer.ShouldRetry(serverError))
+
+	throttled := &request.Request{
+		Time:         time.Now(),
+		Error:        awserr.New("ThrottlingException", "rate exceeded", nil),
+		HTTPResponse: &http.Response{StatusCode: http.StatusBadRequest},
+	}
+	assert.True(t, retryer.ShouldRetry(throttled))
+
+	denied := &request.Request{
+		Time:         time.Now(),
+		Error:        awserr.New("AccessDenied", "access denied", nil),
+		HTTPResponse: &http.Response{StatusCode: http.StatusForbidden},
+	}
+	assert.False(t, retryer.ShouldRetry(denied))
+
+	tooLate := &request.Request{
+		Time:         time.Now().Add(-2 * time.Minute),
+		Error:        awserr.New("InternalError", "internal error", nil),
+		HTTPResponse: &http.Response{StatusCode: http.StatusInternalServerError},
+	}
+	assert.False(t, retryer.ShouldRetry(tooLate))
+
+	assert.LessOrEqual(t, int64(retryer.RetryRules(serverError)), int64(time.Millisecond))
+	assert.Equal(t, uint64(1), policy.Retries())
+}
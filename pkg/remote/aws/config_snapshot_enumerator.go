@@ -0,0 +1,271 @@
+package aws
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/snyk/driftctl/pkg/remote/aws/repository"
+	"github.com/snyk/driftctl/pkg/remote/common"
+	remoteerror "github.com/snyk/driftctl/pkg/remote/error"
+	"github.com/snyk/driftctl/pkg/resource"
+	"github.com/snyk/driftctl/pkg/resource/aws"
+)
+
+// configSnapshotMapping maps resources recorded by AWS Config to a driftctl resource type, ids are the ones
+// the live enumerators use, so snapshot resources match the IaC ones
+type configSnapshotMapping struct {
+	configType   string
+	resourceType resource.ResourceType
+	id           func(item *repository.ConfigurationItem) string
+	// filter tells whether a configuration item belongs to the resource type, e.g. to split default VPCs from VPCs
+	filter     func(item *repository.ConfigurationItem) bool
+	attributes func(item *repository.ConfigurationItem) map[string]interface{}
+}
+
+var configSnapshotMappings = []configSnapshotMapping{
+	{configType: "AWS::S3::Bucket", resourceType: aws.AwsS3BucketResourceType, id: configItemName, attributes: func(item *repository.ConfigurationItem) map[string]interface{} {
+		return map[string]interface{}{"region": item.Region}
+	}},
+	{configType: "AWS::EC2::Instance", resourceType: aws.AwsInstanceResourceType, id: configItemID, filter: func(item *repository.ConfigurationItem) bool {
+		// Terminated instances are not listed by the EC2 enumerator
+		state, _ := configValue(item, "state").(map[string]interface{})
+		return state == nil || state["name"] != "terminated"
+	}},
+	{configType: "AWS::EC2::VPC", resourceType: aws.AwsVpcResourceType, id: configItemID, filter: configFlag("isDefault", false)},
+	{configType: "AWS::EC2::VPC", resourceType: aws.AwsDefaultVpcResourceType, id: configItemID, filter: configFlag("isDefault", true)},
+	{configType: "AWS::EC2::Subnet", resourceType: aws.AwsSubnetResourceType, id: configItemID, filter: configFlag("defaultForAz", false)},
+	{configType: "AWS::EC2::Subnet", resourceType: aws.AwsDefaultSubnetResourceType, id: configItemID, filter: configFlag("defaultForAz", true)},
+	{configType: "AWS::EC2::SecurityGroup", resourceType: aws.AwsSecurityGroupResourceType, id: configItemID, filter: func(item *repository.ConfigurationItem) bool {
+		return configValue(item, "groupName") != "default"
+	}},
+	{configType: "AWS::EC2::SecurityGroup", resourceType: aws.AwsDefaultSecurityGroupResourceType, id: configItemID, filter: func(item *repository.ConfigurationItem) bool {
+		return configValue(item, "groupName") == "default"
+	}},
+	{configType: "AWS::EC2::NetworkAcl", resourceType: aws.AwsNetworkACLResourceType, id: configItemID, filter: configFlag("isDefault", false)},
+	{configType: "AWS::EC2::NetworkAcl", resourceType: aws.AwsDefaultNetworkACLResourceType, id: configItemID, filter: configFlag("isDefault", true)},
+	{configType: "AWS::EC2::RouteTable", resourceType: aws.AwsRouteTableResourceType, id: configItemID, filter: func(item *repository.ConfigurationItem) bool {
+		return !isMainRouteTableItem(item)
+	}},
+	{configType: "AWS::EC2::RouteTable", resourceType: aws.AwsDefaultRouteTableResourceType, id: configItemID, filter: isMainRouteTableItem},
+	{configType: "AWS::EC2::InternetGateway", resourceType: aws.AwsInternetGatewayResourceType, id: configItemID},
+	{configType: "AWS::EC2::NatGateway", resourceType: aws.AwsNatGatewayResourceType, id: configItemID, attributes: func(item *repository.ConfigurationItem) map[string]interface{} {
+		attrs := map[string]interface{}{}
+		if addresses, ok := configValue(item, "natGatewayAddresses").([]interface{}); ok && len(addresses) > 0 {
+			if address, ok := addresses[0].(map[string]interface{}); ok {
+				if allocationID, ok := address["allocationId"].(string); ok {
+					attrs["allocation_id"] = allocationID
+				}
+			}
+		}
+		return attrs
+	}},
+	{configType: "AWS::EC2::Volume", resourceType: aws.AwsEbsVolumeResourceType, id: configItemID},
+	{configType: "AWS::EC2::EIP", resourceType: aws.AwsEipResourceType, id: configItemID},
+	{configType: "AWS::EC2::LaunchTemplate", resourceType: aws.AwsLaunchTemplateResourceType, id: configItemID},
+	{configType: "AWS::AutoScaling::LaunchConfiguration", resourceType: aws.AwsLaunchConfigurationResourceType, id: configItemName},
+	{configType: "AWS::IAM::User", resourceType: aws.AwsIamUserResourceType, id: configItemName},
+	{configType: "AWS::IAM::Group", resourceType: aws.AwsIamGroupResourceType, id: configItemName},
+	{configType: "AWS::IAM::Role", resourceType: aws.AwsIamRoleResourceType, id: configItemName, filter: func(item *repository.ConfigurationItem) bool {
+		return !awsIamRoleShouldBeIgnored(configItemName(item))
+	}, attributes: func(item *repository.ConfigurationItem) map[string]interface{} {
+		attrs := map[string]interface{}{}
+		if path, ok := configValue(item, "path").(string); ok {
+			attrs["path"] = path
+		}
+		return attrs
+	}},
+	{configType: "AWS::IAM::Policy", resourceType: aws.AwsIamPolicyResourceType, id: configItemARN},
+	{configType: "AWS::KMS::Key", resourceType: aws.AwsKmsKeyResourceType, id: configItemID, filter: func(item *repository.ConfigurationItem) bool {
+		// AWS managed keys are not listed by the KMS enumerator
+		return configValue(item, "keyManager") != "AWS"
+	}},
+	{configType: "AWS::Lambda::Function", resourceType: aws.AwsLambdaFunctionResourceType, id: configItemName},
+	{configType: "AWS::DynamoDB::Table", resourceType: aws.AwsDynamodbTableResourceType, id: configItemName},
+	{configType: "AWS::SNS::Topic", resourceType: aws.AwsSnsTopicResourceType, id: configItemARN},
+	{configType: "AWS::SQS::Queue", resourceType: aws.AwsSqsQueueResourceType, id: sqsQueueURL},
+	{configType: "AWS::RDS::DBInstance", resourceType: aws.AwsDbInstanceResourceType, id: configItemName},
+	{configType: "AWS::RDS::DBSubnetGroup", resourceType: aws.AwsDbSubnetGroupResourceType, id: configItemName},
+	{configType: "AWS::RDS::DBCluster", resourceType: aws.AwsRDSClusterResourceType, id: configItemName, attributes: func(item *repository.ConfigurationItem) map[string]interface{} {
+		databaseName, _ := configValue(item, "databaseName").(string)
+		return map[string]interface{}{
+			"cluster_identifier": configItemName(item),
+			"database_name":      databaseName,
+		}
+	}},
+	{configType: "AWS::CloudFront::Distribution", resourceType: aws.AwsCloudfrontDistributionResourceType, id: configItemID},
+	{configType: "AWS::ECR::Repository", resourceType: aws.AwsEcrRepositoryResourceType, id: configItemName},
+	{configType: "AWS::ElastiCache::CacheCluster", resourceType: aws.AwsElastiCacheClusterResourceType, id: configItemName},
+	{configType: "AWS::ElasticLoadBalancing::LoadBalancer", resourceType: aws.AwsClassicLoadBalancerResourceType, id: configItemName},
+	{configType: "AWS::ElasticLoadBalancingV2::LoadBalancer", resourceType: aws.AwsLoadBalancerResourceType, id: configItemARN, attributes: func(item *repository.ConfigurationItem) map[string]interface{} {
+		return map[string]interface{}{"name": configItemName(item)}
+	}},
+	{configType: "AWS::CloudFormation::Stack", resourceType: aws.AwsCloudformationStackResourceType, id: configItemARN},
+	{configType: "AWS::ApiGateway::RestApi", resourceType: aws.AwsApiGatewayRestApiResourceType, id: configItemID},
+	{configType: "AWS::ApiGatewayV2::Api", resourceType: aws.AwsApiGatewayV2ApiResourceType, id: configItemID},
+	{configType: "AWS::Route53::HostedZone", resourceType: aws.AwsRoute53ZoneResourceType, id: func(item *repository.ConfigurationItem) string {
+		return strings.TrimPrefix(configItemID(item), "/hostedzone/")
+	}},
+}
+
+func configItemID(item *repository.ConfigurationItem) string {
+	return item.ResourceID
+}
+
+func configItemName(item *repository.ConfigurationItem) string {
+	if item.ResourceName != "" {
+		return item.ResourceName
+	}
+	return item.ResourceID
+}
+
+func configItemARN(item *repository.ConfigurationItem) string {
+	if item.ARN != "" {
+		return item.ARN
+	}
+	return item.ResourceID
+}
+
+func configValue(item *repository.ConfigurationItem, key string) interface{} {
+	return item.Configuration[key]
+}
+
+func configFlag(key string, value bool) func(item *repository.ConfigurationItem) bool {
+	return func(item *repository.ConfigurationItem) bool {
+		flag, _ := configValue(item, key).(bool)
+		return flag == value
+	}
+}
+
+func isMainRouteTableItem(item *repository.ConfigurationItem) bool {
+	associations, _ := configValue(item, "associations").([]interface{})
+	for _, association := range associations {
+		if association, ok := association.(map[string]interface{}); ok && association["main"] == true {
+			return true
+		}
+	}
+	return false
+}
+
+// sqsQueueURL builds the URL the SQS enumerator uses as id from the queue ARN
+func sqsQueueURL(item *repository.ConfigurationItem) string {
+	queueARN, err := arn.Parse(configItemARN(item))
+	if err != nil {
+		return configItemName(item)
+	}
+	return fmt.Sprintf("https://sqs.%s.amazonaws.com/%s/%s", queueARN.Region, queueARN.AccountID, queueARN.Resource)
+}
+
+type configSnapshotKey struct {
+	account string
+	id      string
+}
+
+type ConfigSnapshotEnumerator struct {
+	repository repository.ConfigSnapshotRepository
+	factory    resource.ResourceFactory
+	mapping    configSnapshotMapping
+}
+
+// NewConfigSnapshotEnumerators returns an enumerator for every resource type that can be read from AWS Config
+// snapshots
+func NewConfigSnapshotEnumerators(repo repository.ConfigSnapshotRepository, factory resource.ResourceFactory) []*ConfigSnapshotEnumerator {
+	enumerators := make([]*ConfigSnapshotEnumerator, 0, len(configSnapshotMappings))
+	for _, mapping := range configSnapshotMappings {
+		enumerators = append(enumerators, &ConfigSnapshotEnumerator{
+			repository: repo,
+			factory:    factory,
+			mapping:    mapping,
+		})
+	}
+	return enumerators
+}
+
+// ConfigSnapshotResourceTypes returns the resource types that can be read from AWS Config snapshots
+func ConfigSnapshotResourceTypes() []resource.ResourceType {
+	types := make([]resource.ResourceType, 0, len(configSnapshotMappings))
+	for _, mapping := range configSnapshotMappings {
+		types = append(types, mapping.resourceType)
+	}
+	return types
+}
+
+// ConfigSnapshotIdentity returns the accounts and regions resources were read from, as snapshots are not read with
+// the credentials of the scanned account. Several accounts or regions are separated by commas.
+func ConfigSnapshotIdentity(repo repository.ConfigSnapshotRepository) (common.Identity, error) {
+	items, err := repo.ListAllConfigurationItems()
+	if err != nil {
+		return common.Identity{}, err
+	}
+	accounts := make(map[string]struct{})
+	regions := make(map[string]struct{})
+	for _, item := range items {
+		if item.AccountID != "" {
+			accounts[item.AccountID] = struct{}{}
+		}
+		if item.Region != "" && item.Region != "global" {
+			regions[item.Region] = struct{}{}
+		}
+	}
+	return common.Identity{
+		Account: joinSorted(accounts),
+		Region:  joinSorted(regions),
+	}, nil
+}
+
+func joinSorted(set map[string]struct{}) string {
+	values := make([]string, 0, len(set))
+	for value := range set {
+		values = append(values, value)
+	}
+	sort.Strings(values)
+	return strings.Join(values, ",")
+}
+
+func (e *ConfigSnapshotEnumerator) SupportedType() resource.ResourceType {
+	return e.mapping.resourceType
+}
+
+func (e *ConfigSnapshotEnumerator) Enumerate() ([]*resource.Resource, error) {
+	items, err := e.repository.ListAllConfigurationItems()
+	if err != nil {
+		return nil, remoteerror.NewResourceListingError(err, string(e.SupportedType()))
+	}
+
+	results := make([]*resource.Resource, 0)
+	// Global resources, e.g. IAM roles, are recorded in every region, resources of different accounts are kept apart
+	seen := make(map[configSnapshotKey]struct{})
+	for _, item := range items {
+		if item.ResourceType != e.mapping.configType {
+			continue
+		}
+		if e.mapping.filter != nil && !e.mapping.filter(item) {
+			continue
+		}
+		id := e.mapping.id(item)
+		if id == "" {
+			continue
+		}
+		key := configSnapshotKey{item.AccountID, id}
+		if _, exist := seen[key]; exist {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		attrs := map[string]interface{}{}
+		if e.mapping.attributes != nil {
+			attrs = e.mapping.attributes(item)
+		}
+		results = append(
+			results,
+			e.factory.CreateAbstractResource(
+				string(e.SupportedType()),
+				id,
+				attrs,
+			),
+		)
+	}
+
+	return results, nil
+}
@@ -1,17 +1,27 @@
 package aws
 
 import (
+	"github.com/sirupsen/logrus"
 	"github.com/snyk/driftctl/pkg/alerter"
 	"github.com/snyk/driftctl/pkg/output"
 	"github.com/snyk/driftctl/pkg/remote/aws/client"
 	"github.com/snyk/driftctl/pkg/remote/aws/repository"
 	"github.com/snyk/driftctl/pkg/remote/cache"
 	"github.com/snyk/driftctl/pkg/remote/common"
+	remoteerror "github.com/snyk/driftctl/pkg/remote/error"
 	"github.com/snyk/driftctl/pkg/resource"
 	"github.com/snyk/driftctl/pkg/resource/aws"
 	"github.com/snyk/driftctl/pkg/terraform"
 )
 
+// Options tells how to scan AWS, on top of the options shared by every remote
+type Options struct {
+	// Endpoints overrides the endpoints of AWS services, e.g. to scan LocalStack
+	Endpoints client.EndpointOptions
+	// ConfigSnapshot tells which AWS Config snapshots remote resources are read from instead of enumerating the account
+	ConfigSnapshot repository.ConfigSnapshotOptions
+}
+
 /**
  * Initialize remote (configure credentials, launch tf providers and start gRPC clients)
  * Required to use Scanner
@@ -23,21 +33,31 @@ func Init(version string, alerter *alerter.Alerter,
 	progress output.Progress,
 	resourceSchemaRepository *resource.SchemaRepository,
 	factory resource.ResourceFactory,
-	configDir string) error {
+	opts common.Options,
+	awsOpts Options) error {
 
-	provider, err := NewAWSTerraformProvider(version, progress, configDir)
+	provider, err := NewAWSTerraformProvider(version, progress, opts.ProviderInstallOptions, awsOpts.Endpoints, opts.Recorder)
 	if err != nil {
 		return err
 	}
+	if len(awsOpts.ConfigSnapshot.Sources) > 0 {
+		return initConfigSnapshot(provider, providerLibrary, remoteLibrary, resourceSchemaRepository, factory, awsOpts.ConfigSnapshot)
+	}
 	err = provider.CheckCredentialsExist()
 	if err != nil {
-		return err
+		return remoteerror.NewCredentialsError(err)
 	}
 	err = provider.Init()
 	if err != nil {
-		return err
+		return remoteerror.NewProviderInitError(err)
 	}
 
+	// Every AWS client is created from this session, so they all share the scan retry policy
+	provider.session.Config.Retryer = client.NewRetryer(opts.RetryPolicy)
+	opts.PageLimiter.AWSHandlers(&provider.session.Handlers)
+
+	remoteLibrary.ResolveIdentity(provider)
+
 	repositoryCache := cache.New(100)
 
 	s3Repository := repository.NewS3Repository(client.NewAWSClientFactory(provider.session), repositoryCache)
@@ -256,3 +276,39 @@ func Init(version string, alerter *alerter.Alerter,
 
 	return nil
 }
+
+// initConfigSnapshot reads remote resources from AWS Config snapshots instead of enumerating them, the terraform
+// provider is only started to read the resource schemas so no credentials are needed unless snapshots are in S3
+func initConfigSnapshot(provider *AWSTerraformProvider,
+	providerLibrary *terraform.ProviderLibrary,
+	remoteLibrary *common.RemoteLibrary,
+	resourceSchemaRepository *resource.SchemaRepository,
+	factory resource.ResourceFactory,
+	configSnapshot repository.ConfigSnapshotOptions) error {
+
+	provider.Config.SchemaOnly = true
+	err := provider.Init()
+	if err != nil {
+		return remoteerror.NewProviderInitError(err)
+	}
+	providerLibrary.AddProvider(terraform.AWS, provider)
+
+	configSnapshotRepository := repository.NewConfigSnapshotRepository(provider.session, configSnapshot, cache.New(100))
+	// Snapshots are cached, so they are read once for both the identity and the enumerators
+	identity, err := ConfigSnapshotIdentity(configSnapshotRepository)
+	if err != nil {
+		logrus.WithField("error", err).Warn("Unable to resolve the accounts of AWS Config snapshots")
+	}
+	remoteLibrary.SetIdentity(identity)
+	for _, enumerator := range NewConfigSnapshotEnumerators(configSnapshotRepository, factory) {
+		remoteLibrary.AddEnumerator(enumerator)
+	}
+
+	err = resourceSchemaRepository.Init(terraform.AWS, provider.Version(), provider.Schema())
+	if err != nil {
+		return err
+	}
+	aws.InitResourcesMetadata(resourceSchemaRepository)
+
+	return nil
+}
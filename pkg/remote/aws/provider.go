@@ -1,10 +1,17 @@
 package aws
 
 import (
+	"net/http"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/pkg/errors"
 	"github.com/snyk/driftctl/pkg/output"
+	"github.com/snyk/driftctl/pkg/remote/aws/client"
+	"github.com/snyk/driftctl/pkg/remote/common"
+	"github.com/snyk/driftctl/pkg/remote/recording"
 	"github.com/snyk/driftctl/pkg/remote/terraform"
 	tf "github.com/snyk/driftctl/pkg/terraform"
 )
@@ -26,7 +33,7 @@ type awsConfig struct {
 	AllowedAccountIds   []string
 	ForbiddenAccountIds []string
 
-	Endpoints        map[string]string
+	Endpoints        []map[string]string `cty:"endpoints"`
 	IgnoreTagsConfig map[string]string
 	Insecure         bool
 
@@ -35,7 +42,7 @@ type awsConfig struct {
 	SkipRegionValidation    bool
 	SkipRequestingAccountId bool
 	SkipMetadataApiCheck    bool
-	S3ForcePathStyle        bool
+	S3ForcePathStyle        bool `cty:"s3_force_path_style"`
 }
 
 type AWSTerraformProvider struct {
@@ -45,34 +52,59 @@ type AWSTerraformProvider struct {
 	version string
 }
 
-func NewAWSTerraformProvider(version string, progress output.Progress, configDir string) (*AWSTerraformProvider, error) {
+func NewAWSTerraformProvider(version string, progress output.Progress, installOptions tf.ProviderInstallOptions, endpointOptions client.EndpointOptions, recorder *recording.Recorder) (*AWSTerraformProvider, error) {
 	if version == "" {
-		version = "3.19.0"
+		version = common.RemoteParameter(common.RemoteAWSTerraform).GetDefaultProviderVersion()
+	}
+	version, err := recorder.Value("aws_provider_version", version)
+	if err != nil {
+		return nil, err
 	}
 	p := &AWSTerraformProvider{
 		version: version,
 		name:    "aws",
 	}
 	installer, err := tf.NewProviderInstaller(tf.ProviderConfig{
-		Key:       p.name,
-		Version:   version,
-		ConfigDir: configDir,
+		Key:                    p.name,
+		Version:                version,
+		ProviderInstallOptions: installOptions,
 	})
 	if err != nil {
 		return nil, err
 	}
-	p.session = session.Must(session.NewSessionWithOptions(session.Options{
+	sessionOptions := session.Options{
 		SharedConfigState: session.SharedConfigEnable,
-	}))
+	}
+	if recorder.IsReplaying() {
+		// Responses come from the recording, credentials are only needed to sign requests
+		sessionOptions.Config.Credentials = credentials.NewStaticCredentials("replay", "replay", "")
+	}
+	if !endpointOptions.IsEmpty() {
+		sessionOptions.Config.EndpointResolver = endpointOptions
+		// Services emulating S3, like LocalStack, rarely support virtual hosted buckets
+		sessionOptions.Config.S3ForcePathStyle = aws.Bool(endpointOptions.Endpoint("s3") != "")
+	}
+	p.session = session.Must(session.NewSessionWithOptions(sessionOptions))
+	region, err := recorder.Value("aws_region", aws.StringValue(p.session.Config.Region))
+	if err != nil {
+		return nil, err
+	}
+	p.session.Config.Region = &region
+	if recorder != nil {
+		p.session.Config.HTTPClient = &http.Client{Transport: recorder.Transport(http.DefaultTransport)}
+	}
 	tfProvider, err := terraform.NewTerraformProvider(installer, terraform.TerraformProviderConfig{
 		Name:         p.name,
 		DefaultAlias: *p.session.Config.Region,
 		GetProviderConfig: func(alias string) interface{} {
 			return awsConfig{
-				Region:     alias,
-				MaxRetries: 10, // TODO make this configurable
+				Region:           alias,
+				MaxRetries:       10, // TODO make this configurable
+				Endpoints:        endpointOptions.TerraformEndpoints(),
+				S3ForcePathStyle: endpointOptions.Endpoint("s3") != "",
 			}
 		},
+		Recorder: recorder,
 	}, progress)
 	if err != nil {
 		return nil, err
@@ -101,3 +133,15 @@ func (p *AWSTerraformProvider) CheckCredentialsExist() error {
 	}
 	return nil
 }
+
+// Identity returns the scanned account and region, and the ARN of the caller the credentials belong to
+func (p *AWSTerraformProvider) Identity() (common.Identity, error) {
+	identity := common.Identity{Region: aws.StringValue(p.session.Config.Region)}
+	output, err := sts.New(p.session).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return identity, err
+	}
+	identity.Account = aws.StringValue(output.Account)
+	identity.Caller = aws.StringValue(output.Arn)
+	return identity, nil
+}
@@ -0,0 +1,440 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/remote/cache"
+)
+
+// ConfigurationItem is a resource recorded by AWS Config
+type ConfigurationItem struct {
+	AccountID     string
+	Region        string
+	ResourceType  string
+	ResourceID    string
+	ResourceName  string
+	ARN           string
+	Status        string
+	CaptureTime   time.Time
+	Configuration map[string]interface{}
+}
+
+// configurationItem reads the fields of configuration items shared by configuration snapshots, configuration
+// histories, aggregator batch exports and advanced query results
+type configurationItem struct {
+	AwsAccountID            string          `json:"awsAccountId"`
+	AccountID               string          `json:"accountId"`
+	AwsRegion               string          `json:"awsRegion"`
+	ResourceType            string          `json:"resourceType"`
+	ResourceID              string          `json:"resourceId"`
+	ResourceName            string          `json:"resourceName"`
+	ARN                     string          `json:"arn"`
+	ConfigurationItemStatus string          `json:"configurationItemStatus"`
+	CaptureTime             json.RawMessage `json:"configurationItemCaptureTime"`
+	Configuration           json.RawMessage `json:"configuration"`
+}
+
+func (i *ConfigurationItem) UnmarshalJSON(data []byte) error {
+	item := configurationItem{}
+	if err := json.Unmarshal(data, &item); err != nil {
+		return err
+	}
+	*i = ConfigurationItem{
+		AccountID:    item.AwsAccountID,
+		Region:       item.AwsRegion,
+		ResourceType: item.ResourceType,
+		ResourceID:   item.ResourceID,
+		ResourceName: item.ResourceName,
+		ARN:          item.ARN,
+		Status:       item.ConfigurationItemStatus,
+	}
+	if i.AccountID == "" {
+		i.AccountID = item.AccountID
+	}
+	captureTime, err := parseCaptureTime(item.CaptureTime)
+	if err != nil {
+		return err
+	}
+	i.CaptureTime = captureTime
+
+	configuration := bytes.TrimSpace(item.Configuration)
+	if len(configuration) == 0 || bytes.Equal(configuration, []byte("null")) {
+		return nil
+	}
+	// Aggregator exports hold the configuration as a JSON encoded string
+	if configuration[0] == '"' {
+		var encoded string
+		if err := json.Unmarshal(configuration, &encoded); err != nil {
+			return err
+		}
+		configuration = []byte(encoded)
+	}
+	return json.Unmarshal(configuration, &i.Configuration)
+}
+
+// parseCaptureTime reads the capture time of a configuration item, written as an RFC 3339 date in snapshots
+// and as an epoch timestamp in API outputs
+func parseCaptureTime(raw json.RawMessage) (time.Time, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return time.Time{}, nil
+	}
+	if raw[0] != '"' {
+		var epoch float64
+		if err := json.Unmarshal(raw, &epoch); err != nil {
+			return time.Time{}, err
+		}
+		return time.Unix(0, int64(epoch*float64(time.Second))).UTC(), nil
+	}
+	var date string
+	if err := json.Unmarshal(raw, &date); err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, date)
+}
+
+// configSnapshotFile is either a configuration snapshot delivered by AWS Config, the output of
+// get-resource-config-history, batch-get-aggregate-resource-config or select-aggregate-resource-config
+type configSnapshotFile struct {
+	ConfigurationItems     []*ConfigurationItem `json:"configurationItems"`
+	BaseConfigurationItems []*ConfigurationItem `json:"BaseConfigurationItems"`
+	Results                []string             `json:"Results"`
+}
+
+// deletedConfigurationItemStatuses are the statuses of resources that don't exist anymore
+var deletedConfigurationItemStatuses = map[string]struct{}{
+	"ResourceDeleted":            {},
+	"ResourceDeletedNotRecorded": {},
+	"ResourceNotRecorded":        {},
+}
+
+// configGlobalRegion is the region of resources that don't belong to a region, e.g. IAM ones
+const configGlobalRegion = "global"
+
+// ConfigSnapshotOptions tells where to read AWS Config snapshots from and which resources to keep
+type ConfigSnapshotOptions struct {
+	// Sources are local files, directories, S3 objects or S3 prefixes, e.g. s3://bucket/AWSLogs/
+	Sources []string
+	// Accounts and Regions only keep resources of the given accounts and regions, every one is kept when empty.
+	// Global resources are kept whatever the regions.
+	Accounts []string
+	Regions  []string
+}
+
+type ConfigSnapshotRepository interface {
+	ListAllConfigurationItems() ([]*ConfigurationItem, error)
+}
+
+type configSnapshotRepository struct {
+	sources  []string
+	accounts map[string]struct{}
+	regions  map[string]struct{}
+	client   s3iface.S3API
+	cache    cache.Cache
+}
+
+// NewConfigSnapshotRepository reads configuration items from snapshot files
+func NewConfigSnapshotRepository(session *session.Session, options ConfigSnapshotOptions, c cache.Cache) *configSnapshotRepository {
+	return &configSnapshotRepository{
+		options.Sources,
+		toSet(options.Accounts),
+		toSet(options.Regions),
+		s3.New(session),
+		c,
+	}
+}
+
+func toSet(values []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		set[value] = struct{}{}
+	}
+	return set
+}
+
+func (r *configSnapshotRepository) ListAllConfigurationItems() ([]*ConfigurationItem, error) {
+	cacheKey := "configSnapshotListAllConfigurationItems"
+	v := r.cache.GetAndLock(cacheKey)
+	defer r.cache.Unlock(cacheKey)
+	if v != nil {
+		return v.([]*ConfigurationItem), nil
+	}
+
+	items := make([]*ConfigurationItem, 0)
+	for _, source := range r.sources {
+		var sourceItems []*ConfigurationItem
+		var err error
+		if strings.HasPrefix(source, "s3://") {
+			sourceItems, err = r.readS3(strings.TrimPrefix(source, "s3://"))
+		} else {
+			sourceItems, err = r.readPath(source)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to read AWS Config snapshot %s", source)
+		}
+		items = append(items, r.filter(sourceItems)...)
+	}
+	items = latestConfigurationItems(items)
+
+	if accounts := configItemAccounts(items); len(r.accounts) == 0 && len(accounts) > 1 {
+		logrus.WithField("accounts", strings.Join(accounts, ", ")).
+			Warn("AWS Config snapshots hold resources of several accounts, they are all scanned, use --aws-config-account to select one")
+	}
+
+	r.cache.Put(cacheKey, items)
+	return items, nil
+}
+
+// filter keeps configuration items of the selected accounts and regions
+func (r *configSnapshotRepository) filter(items []*ConfigurationItem) []*ConfigurationItem {
+	if len(r.accounts) == 0 && len(r.regions) == 0 {
+		return items
+	}
+	filtered := make([]*ConfigurationItem, 0, len(items))
+	for _, item := range items {
+		if _, ok := r.accounts[item.AccountID]; len(r.accounts) > 0 && !ok {
+			continue
+		}
+		if _, ok := r.regions[item.Region]; len(r.regions) > 0 && !ok && item.Region != configGlobalRegion {
+			continue
+		}
+		filtered = append(filtered, item)
+	}
+	return filtered
+}
+
+type configurationItemKey struct {
+	account      string
+	region       string
+	resourceType string
+	resourceID   string
+}
+
+// latestConfigurationItems keeps the latest item captured for every resource, as several snapshots may record
+// the same resource, and drops resources whose latest item tells they were deleted
+func latestConfigurationItems(items []*ConfigurationItem) []*ConfigurationItem {
+	latest := make([]*ConfigurationItem, 0, len(items))
+	indexes := make(map[configurationItemKey]int, len(items))
+	for _, item := range items {
+		key := configurationItemKey{item.AccountID, item.Region, item.ResourceType, item.ResourceID}
+		if i, exist := indexes[key]; exist {
+			if !item.CaptureTime.Before(latest[i].CaptureTime) {
+				latest[i] = item
+			}
+			continue
+		}
+		indexes[key] = len(latest)
+		latest = append(latest, item)
+	}
+
+	result := make([]*ConfigurationItem, 0, len(latest))
+	for _, item := range latest {
+		if _, deleted := deletedConfigurationItemStatuses[item.Status]; deleted {
+			continue
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+func configItemAccounts(items []*ConfigurationItem) []string {
+	accounts := make([]string, 0)
+	seen := make(map[string]struct{})
+	for _, item := range items {
+		if _, exist := seen[item.AccountID]; exist || item.AccountID == "" {
+			continue
+		}
+		seen[item.AccountID] = struct{}{}
+		accounts = append(accounts, item.AccountID)
+	}
+	return accounts
+}
+
+func (r *configSnapshotRepository) readPath(path string) ([]*ConfigurationItem, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		content, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return parseConfigSnapshot(content)
+	}
+
+	files := make([]string, 0)
+	err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && isConfigSnapshotFile(file) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]*ConfigurationItem, 0)
+	for _, file := range selectConfigSnapshotFiles(files) {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileItems, err := parseConfigSnapshot(content)
+		if err != nil {
+			return nil, errors.Wrap(err, file)
+		}
+		items = append(items, fileItems...)
+	}
+	return items, nil
+}
+
+func (r *configSnapshotRepository) readS3(path string) ([]*ConfigurationItem, error) {
+	bucket, key := path, ""
+	if i := strings.Index(path, "/"); i >= 0 {
+		bucket, key = path[:i], path[i+1:]
+	}
+
+	keys := []string{key}
+	if key == "" || strings.HasSuffix(key, "/") {
+		keys = []string{}
+		input := &s3.ListObjectsV2Input{
+			Bucket: aws.String(bucket),
+			Prefix: aws.String(key),
+		}
+		err := r.client.ListObjectsV2Pages(input, func(res *s3.ListObjectsV2Output, lastPage bool) bool {
+			for _, object := range res.Contents {
+				if isConfigSnapshotFile(aws.StringValue(object.Key)) {
+					keys = append(keys, aws.StringValue(object.Key))
+				}
+			}
+			return !lastPage
+		})
+		if err != nil {
+			return nil, err
+		}
+		keys = selectConfigSnapshotFiles(keys)
+	}
+
+	items := make([]*ConfigurationItem, 0)
+	for _, key := range keys {
+		logrus.WithFields(logrus.Fields{
+			"bucket": bucket,
+			"key":    key,
+		}).Debug("Reading AWS Config snapshot")
+		output, err := r.client.GetObject(&s3.GetObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(key),
+		})
+		if err != nil {
+			return nil, err
+		}
+		content, err := ioutil.ReadAll(output.Body)
+		output.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		objectItems, err := parseConfigSnapshot(content)
+		if err != nil {
+			return nil, errors.Wrap(err, key)
+		}
+		items = append(items, objectItems...)
+	}
+	return items, nil
+}
+
+// isConfigSnapshotFile tells whether a file found in a directory or under an S3 prefix holds configuration items,
+// AWS Config delivers gzipped snapshots. Configuration histories are skipped: they hold every past item of
+// resources, including the ones recorded before their deletion.
+func isConfigSnapshotFile(name string) bool {
+	if strings.Contains(name, "ConfigHistory") {
+		return false
+	}
+	return strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".json.gz")
+}
+
+// configSnapshotFileName matches the name of snapshots delivered by AWS Config,
+// e.g. 123456789012_Config_us-east-1_ConfigSnapshot_20211006T123456Z_b2c5d7a4.json.gz
+var configSnapshotFileName = regexp.MustCompile(`(\d{12})_Config_([a-z0-9-]+)_ConfigSnapshot_(\d{8}T\d{6}Z)_`)
+
+// selectConfigSnapshotFiles only keeps the latest snapshot delivered for every account and region,
+// as a prefix holds every past delivery. Files that are not named like delivered snapshots are all kept.
+func selectConfigSnapshotFiles(names []string) []string {
+	type snapshot struct {
+		name string
+		date string
+	}
+	latest := make(map[string]snapshot)
+	selected := make([]string, 0, len(names))
+	for _, name := range names {
+		match := configSnapshotFileName.FindStringSubmatch(filepath.Base(name))
+		if match == nil {
+			selected = append(selected, name)
+			continue
+		}
+		key := match[1] + "/" + match[2]
+		if current, exist := latest[key]; !exist || match[3] > current.date {
+			latest[key] = snapshot{name, match[3]}
+		}
+	}
+	for _, name := range names {
+		match := configSnapshotFileName.FindStringSubmatch(filepath.Base(name))
+		if match != nil && latest[match[1]+"/"+match[2]].name == name {
+			selected = append(selected, name)
+		}
+	}
+	return selected
+}
+
+func parseConfigSnapshot(content []byte) ([]*ConfigurationItem, error) {
+	if bytes.HasPrefix(content, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		content, err = ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var parsed []*ConfigurationItem
+	content = bytes.TrimSpace(content)
+	if bytes.HasPrefix(content, []byte("[")) {
+		if err := json.Unmarshal(content, &parsed); err != nil {
+			return nil, err
+		}
+	} else {
+		file := configSnapshotFile{}
+		if err := json.Unmarshal(content, &file); err != nil {
+			return nil, err
+		}
+		parsed = append(file.ConfigurationItems, file.BaseConfigurationItems...)
+		for _, result := range file.Results {
+			item := &ConfigurationItem{}
+			if err := json.Unmarshal([]byte(result), item); err != nil {
+				return nil, err
+			}
+			parsed = append(parsed, item)
+		}
+	}
+
+	return parsed, nil
+}
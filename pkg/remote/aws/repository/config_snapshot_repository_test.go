@@ -0,0 +1,460 @@
+package repository
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/pkg/remote/cache"
+	awstest "github.com/snyk/driftctl/test/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	// configSnapshot is delivered by AWS Config, deleted resources are still part of it
+	configSnapshot = `{
+  "fileVersion": "1.0",
+  "configSnapshotId": "b2c5d7a4-5d3e-4c6f-9f4e-7a1b2c3d4e5f",
+  "configurationItems": [
+    {
+      "configurationItemStatus": "OK",
+      "awsAccountId": "123456789012",
+      "awsRegion": "us-east-1",
+      "resourceType": "AWS::EC2::VPC",
+      "resourceId": "vpc-0a1b2c3d",
+      "ARN": "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0a1b2c3d",
+      "configuration": {"vpcId": "vpc-0a1b2c3d", "isDefault": true}
+    },
+    {
+      "configurationItemStatus": "ResourceDeleted",
+      "awsAccountId": "123456789012",
+      "awsRegion": "us-east-1",
+      "resourceType": "AWS::EC2::VPC",
+      "resourceId": "vpc-deleted",
+      "configuration": null
+    }
+  ]
+}`
+	// configGlobalSnapshot holds resources that don't belong to a region
+	configGlobalSnapshot = `{
+  "fileVersion": "1.0",
+  "configurationItems": [
+    {
+      "configurationItemStatus": "OK",
+      "awsAccountId": "123456789012",
+      "awsRegion": "global",
+      "resourceType": "AWS::IAM::User",
+      "resourceId": "AIDAEXAMPLE",
+      "resourceName": "driftctl",
+      "ARN": "arn:aws:iam::123456789012:user/driftctl",
+      "configuration": {"path": "/"}
+    }
+  ]
+}`
+	// configBatchExport is the output of batch-get-aggregate-resource-config
+	configBatchExport = `{
+  "BaseConfigurationItems": [
+    {
+      "accountId": "210987654321",
+      "awsRegion": "eu-west-3",
+      "resourceType": "AWS::S3::Bucket",
+      "resourceId": "driftctl-bucket",
+      "resourceName": "driftctl-bucket",
+      "arn": "arn:aws:s3:::driftctl-bucket",
+      "configurationItemStatus": "ResourceDiscovered",
+      "configurationItemCaptureTime": 1633507200.5,
+      "configuration": "{\"name\":\"driftctl-bucket\"}"
+    }
+  ]
+}`
+	// configPreviousSnapshot was delivered before configLatestSnapshot, vpc-removed was deleted in between
+	configPreviousSnapshot = `{
+  "fileVersion": "1.0",
+  "configurationItems": [
+    {
+      "configurationItemStatus": "OK",
+      "configurationItemCaptureTime": "2021-10-01T08:00:00.000Z",
+      "awsAccountId": "123456789012",
+      "awsRegion": "us-east-1",
+      "resourceType": "AWS::EC2::VPC",
+      "resourceId": "vpc-0a1b2c3d",
+      "configuration": {"vpcId": "vpc-0a1b2c3d", "isDefault": false}
+    },
+    {
+      "configurationItemStatus": "OK",
+      "configurationItemCaptureTime": "2021-09-01T08:00:00.000Z",
+      "awsAccountId": "123456789012",
+      "awsRegion": "us-east-1",
+      "resourceType": "AWS::EC2::VPC",
+      "resourceId": "vpc-removed",
+      "configuration": {"vpcId": "vpc-removed"}
+    }
+  ]
+}`
+	configLatestSnapshot = `{
+  "fileVersion": "1.0",
+  "configurationItems": [
+    {
+      "configurationItemStatus": "OK",
+      "configurationItemCaptureTime": "2021-10-06T08:00:00.000Z",
+      "awsAccountId": "123456789012",
+      "awsRegion": "us-east-1",
+      "resourceType": "AWS::EC2::VPC",
+      "resourceId": "vpc-0a1b2c3d",
+      "configuration": {"vpcId": "vpc-0a1b2c3d", "isDefault": true}
+    }
+  ]
+}`
+	// configHistory holds past items of a resource, history files use the same key as snapshots
+	configHistory = `{
+  "fileVersion": "1.0",
+  "configurationItems": [
+    {
+      "configurationItemStatus": "OK",
+      "configurationItemCaptureTime": "2021-09-01T08:00:00.000Z",
+      "awsAccountId": "123456789012",
+      "awsRegion": "us-east-1",
+      "resourceType": "AWS::EC2::VPC",
+      "resourceId": "vpc-removed",
+      "configuration": {"vpcId": "vpc-removed"}
+    },
+    {
+      "configurationItemStatus": "ResourceDeleted",
+      "configurationItemCaptureTime": "2021-10-03T08:00:00.000Z",
+      "awsAccountId": "123456789012",
+      "awsRegion": "us-east-1",
+      "resourceType": "AWS::EC2::VPC",
+      "resourceId": "vpc-removed",
+      "configuration": null
+    }
+  ]
+}`
+	// configQueryResults is the output of select-aggregate-resource-config
+	configQueryResults = `{
+  "Results": [
+    "{\"accountId\":\"210987654321\",\"awsRegion\":\"eu-west-3\",\"resourceType\":\"AWS::IAM::Role\",\"resourceId\":\"AROAEXAMPLE\",\"resourceName\":\"driftctl\",\"arn\":\"arn:aws:iam::210987654321:role/driftctl\",\"configuration\":{\"path\":\"/\"}}"
+  ]
+}`
+)
+
+var (
+	configSnapshotItems = []*ConfigurationItem{
+		{
+			AccountID:     "123456789012",
+			Region:        "us-east-1",
+			ResourceType:  "AWS::EC2::VPC",
+			ResourceID:    "vpc-0a1b2c3d",
+			ARN:           "arn:aws:ec2:us-east-1:123456789012:vpc/vpc-0a1b2c3d",
+			Status:        "OK",
+			Configuration: map[string]interface{}{"vpcId": "vpc-0a1b2c3d", "isDefault": true},
+		},
+	}
+	configLatestSnapshotItems = []*ConfigurationItem{
+		{
+			AccountID:     "123456789012",
+			Region:        "us-east-1",
+			ResourceType:  "AWS::EC2::VPC",
+			ResourceID:    "vpc-0a1b2c3d",
+			Status:        "OK",
+			CaptureTime:   time.Date(2021, 10, 6, 8, 0, 0, 0, time.UTC),
+			Configuration: map[string]interface{}{"vpcId": "vpc-0a1b2c3d", "isDefault": true},
+		},
+	}
+	configGlobalSnapshotItems = []*ConfigurationItem{
+		{
+			AccountID:     "123456789012",
+			Region:        "global",
+			ResourceType:  "AWS::IAM::User",
+			ResourceID:    "AIDAEXAMPLE",
+			ResourceName:  "driftctl",
+			ARN:           "arn:aws:iam::123456789012:user/driftctl",
+			Status:        "OK",
+			Configuration: map[string]interface{}{"path": "/"},
+		},
+	}
+	configBatchExportItems = []*ConfigurationItem{
+		{
+			AccountID:     "210987654321",
+			Region:        "eu-west-3",
+			ResourceType:  "AWS::S3::Bucket",
+			ResourceID:    "driftctl-bucket",
+			ResourceName:  "driftctl-bucket",
+			ARN:           "arn:aws:s3:::driftctl-bucket",
+			Status:        "ResourceDiscovered",
+			CaptureTime:   time.Date(2021, 10, 6, 8, 0, 0, 500000000, time.UTC),
+			Configuration: map[string]interface{}{"name": "driftctl-bucket"},
+		},
+	}
+	configQueryResultsItems = []*ConfigurationItem{
+		{
+			AccountID:     "210987654321",
+			Region:        "eu-west-3",
+			ResourceType:  "AWS::IAM::Role",
+			ResourceID:    "AROAEXAMPLE",
+			ResourceName:  "driftctl",
+			ARN:           "arn:aws:iam::210987654321:role/driftctl",
+			Configuration: map[string]interface{}{"path": "/"},
+		},
+	}
+)
+
+func gzipContent(t *testing.T, content string) []byte {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func Test_configSnapshotRepository_ListAllConfigurationItems(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"snapshot.json":                     []byte(configSnapshot),
+		"global.json":                       []byte(configGlobalSnapshot),
+		"export/batch.json":                 []byte(configBatchExport),
+		"export/query/results.json.gz":      gzipContent(t, configQueryResults),
+		"export/query/README.md":            []byte("not a snapshot"),
+		"AWSLogs/123456789012/snapshot.txt": []byte("not a snapshot"),
+		"history/previous.json":             []byte(configPreviousSnapshot),
+		"history/history.json":              []byte(configHistory),
+		"Config/us-east-1/2021/10/1/ConfigSnapshot/123456789012_Config_us-east-1_ConfigSnapshot_20211001T080000Z_a1b2.json.gz":                           gzipContent(t, configPreviousSnapshot),
+		"Config/us-east-1/2021/10/6/ConfigSnapshot/123456789012_Config_us-east-1_ConfigSnapshot_20211006T080000Z_c3d4.json.gz":                           gzipContent(t, configLatestSnapshot),
+		"Config/us-east-1/2021/10/3/ConfigHistory/123456789012_Config_us-east-1_ConfigHistory_AWS::EC2::VPC_20211003T080000Z_20211003T090000Z_1.json.gz": gzipContent(t, configHistory),
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		sources  []string
+		accounts []string
+		regions  []string
+		mocks    func(client *awstest.MockFakeS3, store *cache.MockCache)
+		want     []*ConfigurationItem
+		wantErr  string
+	}{
+		{
+			name:    "read snapshot file",
+			sources: []string{filepath.Join(dir, "snapshot.json")},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+				store.On("Put", "configSnapshotListAllConfigurationItems", configSnapshotItems).Return(false).Once()
+			},
+			want: configSnapshotItems,
+		},
+		{
+			name:    "read aggregator exports in directory",
+			sources: []string{filepath.Join(dir, "export")},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+				store.On("Put", "configSnapshotListAllConfigurationItems", mock.Anything).Return(false).Once()
+			},
+			want: append(append([]*ConfigurationItem{}, configBatchExportItems...), configQueryResultsItems...),
+		},
+		{
+			name:    "read S3 object and prefix",
+			sources: []string{"s3://config-bucket/snapshot.json", "s3://config-bucket/AWSLogs/"},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				client.On("GetObject", &s3.GetObjectInput{
+					Bucket: aws.String("config-bucket"),
+					Key:    aws.String("snapshot.json"),
+				}).Return(&s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader([]byte(configSnapshot)))}, nil).Once()
+				client.On("ListObjectsV2Pages",
+					&s3.ListObjectsV2Input{Bucket: aws.String("config-bucket"), Prefix: aws.String("AWSLogs/")},
+					mock.MatchedBy(func(callback func(res *s3.ListObjectsV2Output, lastPage bool) bool) bool {
+						callback(&s3.ListObjectsV2Output{
+							Contents: []*s3.Object{
+								{Key: aws.String("AWSLogs/210987654321/Config/ConfigWritabilityCheckFile")},
+								{Key: aws.String("AWSLogs/210987654321/Config/eu-west-3/ConfigSnapshot/snapshot.json.gz")},
+							},
+						}, true)
+						return true
+					})).Return(nil).Once()
+				client.On("GetObject", &s3.GetObjectInput{
+					Bucket: aws.String("config-bucket"),
+					Key:    aws.String("AWSLogs/210987654321/Config/eu-west-3/ConfigSnapshot/snapshot.json.gz"),
+				}).Return(&s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(gzipContent(t, configBatchExport)))}, nil).Once()
+
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+				store.On("Put", "configSnapshotListAllConfigurationItems", mock.Anything).Return(false).Once()
+			},
+			want: append(append([]*ConfigurationItem{}, configSnapshotItems...), configBatchExportItems...),
+		},
+		{
+			name:    "read latest snapshot in directory and skip histories",
+			sources: []string{filepath.Join(dir, "Config")},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+				store.On("Put", "configSnapshotListAllConfigurationItems", configLatestSnapshotItems).Return(false).Once()
+			},
+			want: configLatestSnapshotItems,
+		},
+		{
+			name:    "read latest snapshot under S3 prefix and skip histories",
+			sources: []string{"s3://config-bucket/AWSLogs/"},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				client.On("ListObjectsV2Pages",
+					&s3.ListObjectsV2Input{Bucket: aws.String("config-bucket"), Prefix: aws.String("AWSLogs/")},
+					mock.MatchedBy(func(callback func(res *s3.ListObjectsV2Output, lastPage bool) bool) bool {
+						callback(&s3.ListObjectsV2Output{
+							Contents: []*s3.Object{
+								{Key: aws.String("AWSLogs/123456789012/Config/us-east-1/2021/10/1/ConfigSnapshot/123456789012_Config_us-east-1_ConfigSnapshot_20211001T080000Z_a1b2.json.gz")},
+								{Key: aws.String("AWSLogs/123456789012/Config/us-east-1/2021/10/3/ConfigHistory/123456789012_Config_us-east-1_ConfigHistory_AWS::EC2::VPC_20211003T080000Z_20211003T090000Z_1.json.gz")},
+								{Key: aws.String("AWSLogs/123456789012/Config/us-east-1/2021/10/6/ConfigSnapshot/123456789012_Config_us-east-1_ConfigSnapshot_20211006T080000Z_c3d4.json.gz")},
+							},
+						}, true)
+						return true
+					})).Return(nil).Once()
+				client.On("GetObject", &s3.GetObjectInput{
+					Bucket: aws.String("config-bucket"),
+					Key:    aws.String("AWSLogs/123456789012/Config/us-east-1/2021/10/6/ConfigSnapshot/123456789012_Config_us-east-1_ConfigSnapshot_20211006T080000Z_c3d4.json.gz"),
+				}).Return(&s3.GetObjectOutput{Body: ioutil.NopCloser(bytes.NewReader(gzipContent(t, configLatestSnapshot)))}, nil).Once()
+
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+				store.On("Put", "configSnapshotListAllConfigurationItems", configLatestSnapshotItems).Return(false).Once()
+			},
+			want: configLatestSnapshotItems,
+		},
+		{
+			name:    "keep latest item of every resource and drop deleted ones",
+			sources: []string{filepath.Join(dir, "history/history.json"), filepath.Join(dir, "history/previous.json")},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+				store.On("Put", "configSnapshotListAllConfigurationItems", mock.Anything).Return(false).Once()
+			},
+			want: []*ConfigurationItem{
+				{
+					AccountID:     "123456789012",
+					Region:        "us-east-1",
+					ResourceType:  "AWS::EC2::VPC",
+					ResourceID:    "vpc-0a1b2c3d",
+					Status:        "OK",
+					CaptureTime:   time.Date(2021, 10, 1, 8, 0, 0, 0, time.UTC),
+					Configuration: map[string]interface{}{"vpcId": "vpc-0a1b2c3d", "isDefault": false},
+				},
+			},
+		},
+		{
+			name:     "keep resources of selected accounts",
+			sources:  []string{filepath.Join(dir, "snapshot.json"), filepath.Join(dir, "export")},
+			accounts: []string{"210987654321"},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+				store.On("Put", "configSnapshotListAllConfigurationItems", mock.Anything).Return(false).Once()
+			},
+			want: append(append([]*ConfigurationItem{}, configBatchExportItems...), configQueryResultsItems...),
+		},
+		{
+			name:    "keep resources of selected regions",
+			sources: []string{filepath.Join(dir, "snapshot.json"), filepath.Join(dir, "export")},
+			regions: []string{"us-east-1"},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+				store.On("Put", "configSnapshotListAllConfigurationItems", configSnapshotItems).Return(false).Once()
+			},
+			want: configSnapshotItems,
+		},
+		{
+			name:    "keep global resources whatever the regions",
+			sources: []string{filepath.Join(dir, "snapshot.json"), filepath.Join(dir, "global.json")},
+			regions: []string{"eu-west-3"},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+				store.On("Put", "configSnapshotListAllConfigurationItems", configGlobalSnapshotItems).Return(false).Once()
+			},
+			want: configGlobalSnapshotItems,
+		},
+		{
+			name:     "keep global resources of selected accounts only",
+			sources:  []string{filepath.Join(dir, "global.json"), filepath.Join(dir, "export")},
+			accounts: []string{"210987654321"},
+			regions:  []string{"eu-west-3"},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+				store.On("Put", "configSnapshotListAllConfigurationItems", mock.Anything).Return(false).Once()
+			},
+			want: append(append([]*ConfigurationItem{}, configBatchExportItems...), configQueryResultsItems...),
+		},
+		{
+			name:    "should hit cache",
+			sources: []string{filepath.Join(dir, "snapshot.json")},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(configSnapshotItems).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+			},
+			want: configSnapshotItems,
+		},
+		{
+			name:    "missing file",
+			sources: []string{filepath.Join(dir, "missing.json")},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+			},
+			wantErr: "unable to read AWS Config snapshot " + filepath.Join(dir, "missing.json") + ": stat " + filepath.Join(dir, "missing.json") + ": no such file or directory",
+		},
+		{
+			name:    "S3 error",
+			sources: []string{"s3://config-bucket/snapshot.json"},
+			mocks: func(client *awstest.MockFakeS3, store *cache.MockCache) {
+				client.On("GetObject", mock.Anything).Return(nil, errors.New("access denied")).Once()
+
+				store.On("GetAndLock", "configSnapshotListAllConfigurationItems").Return(nil).Once()
+				store.On("Unlock", "configSnapshotListAllConfigurationItems").Return().Once()
+			},
+			wantErr: "unable to read AWS Config snapshot s3://config-bucket/snapshot.json: access denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &cache.MockCache{}
+			client := &awstest.MockFakeS3{}
+			tt.mocks(client, store)
+			r := &configSnapshotRepository{
+				sources:  tt.sources,
+				accounts: toSet(tt.accounts),
+				regions:  toSet(tt.regions),
+				client:   client,
+				cache:    store,
+			}
+			got, err := r.ListAllConfigurationItems()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+			client.AssertExpectations(t)
+			store.AssertExpectations(t)
+		})
+	}
+}
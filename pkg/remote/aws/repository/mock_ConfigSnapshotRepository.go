@@ -0,0 +1,35 @@
+// Code generated by mockery v1.0.0. DO NOT EDIT.
+
+package repository
+
+import (
+	"github.com/stretchr/testify/mock"
+)
+
+// MockConfigSnapshotRepository is an autogenerated mock type for the ConfigSnapshotRepository type
+type MockConfigSnapshotRepository struct {
+	mock.Mock
+}
+
+// ListAllConfigurationItems provides a mock function with given fields:
+func (_m *MockConfigSnapshotRepository) ListAllConfigurationItems() ([]*ConfigurationItem, error) {
+	ret := _m.Called()
+
+	var r0 []*ConfigurationItem
+	if rf, ok := ret.Get(0).(func() []*ConfigurationItem); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*ConfigurationItem)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
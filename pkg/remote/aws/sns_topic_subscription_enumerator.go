@@ -13,6 +13,14 @@ import (
 	"github.com/snyk/driftctl/pkg/resource/aws"
 )
 
+const WrongArnTopicAlertKind = "wrong_arn_topic"
+
+func init() {
+	alerter.RegisterKind(WrongArnTopicAlertKind, func(alert *alerter.SerializedAlert) alerter.Alert {
+		return NewWrongArnTopicAlert(alert.Details()["arn"], awssdk.String(alert.Details()["endpoint"]))
+	})
+}
+
 type wrongArnTopicAlert struct {
 	arn      string
 	endpoint *string
@@ -22,6 +30,10 @@ func NewWrongArnTopicAlert(arn string, endpoint *string) *wrongArnTopicAlert {
 	return &wrongArnTopicAlert{arn: arn, endpoint: endpoint}
 }
 
+func (p *wrongArnTopicAlert) Kind() string {
+	return WrongArnTopicAlertKind
+}
+
 func (p *wrongArnTopicAlert) Message() string {
 	return fmt.Sprintf("%s with incorrect subscription arn (%s) for endpoint \"%s\" will be ignored",
 		aws.AwsSnsTopicSubscriptionResourceType,
@@ -33,6 +45,13 @@ func (p *wrongArnTopicAlert) ShouldIgnoreResource() bool {
 	return false
 }
 
+func (p *wrongArnTopicAlert) Details() map[string]string {
+	return map[string]string{
+		"arn":      p.arn,
+		"endpoint": awssdk.StringValue(p.endpoint),
+	}
+}
+
 type SNSTopicSubscriptionEnumerator struct {
 	repository repository.SNSRepository
 	factory    resource.ResourceFactory
@@ -0,0 +1,245 @@
+package remote
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/mocks"
+	"github.com/snyk/driftctl/pkg/filter"
+	"github.com/snyk/driftctl/pkg/remote/aws"
+	"github.com/snyk/driftctl/pkg/remote/aws/repository"
+	"github.com/snyk/driftctl/pkg/remote/common"
+	remoteerr "github.com/snyk/driftctl/pkg/remote/error"
+	"github.com/snyk/driftctl/pkg/resource"
+	resourceaws "github.com/snyk/driftctl/pkg/resource/aws"
+	"github.com/snyk/driftctl/pkg/terraform"
+	testresource "github.com/snyk/driftctl/test/resource"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestConfigSnapshot(t *testing.T) {
+	dummyError := errors.New("this is an error")
+
+	tests := []struct {
+		test           string
+		mocks          func(*repository.MockConfigSnapshotRepository)
+		assertExpected func(t *testing.T, got []*resource.Resource)
+		wantErr        error
+	}{
+		{
+			test: "empty snapshot",
+			mocks: func(repo *repository.MockConfigSnapshotRepository) {
+				repo.On("ListAllConfigurationItems").Return([]*repository.ConfigurationItem{}, nil)
+			},
+			assertExpected: func(t *testing.T, got []*resource.Resource) {
+				assert.Len(t, got, 0)
+			},
+		},
+		{
+			test: "multiple accounts and regions",
+			mocks: func(repo *repository.MockConfigSnapshotRepository) {
+				repo.On("ListAllConfigurationItems").Return([]*repository.ConfigurationItem{
+					{
+						Region:        "us-east-1",
+						ResourceType:  "AWS::EC2::VPC",
+						ResourceID:    "vpc-default",
+						Configuration: map[string]interface{}{"isDefault": true},
+					},
+					{
+						Region:        "eu-west-3",
+						ResourceType:  "AWS::EC2::VPC",
+						ResourceID:    "vpc-0a1b2c3d",
+						Configuration: map[string]interface{}{"isDefault": false},
+					},
+					{
+						Region:        "eu-west-3",
+						ResourceType:  "AWS::EC2::RouteTable",
+						ResourceID:    "rtb-main",
+						Configuration: map[string]interface{}{"associations": []interface{}{map[string]interface{}{"main": true}}},
+					},
+					{
+						Region:        "eu-west-3",
+						ResourceType:  "AWS::EC2::Instance",
+						ResourceID:    "i-terminated",
+						Configuration: map[string]interface{}{"state": map[string]interface{}{"name": "terminated"}},
+					},
+					{
+						Region:       "eu-west-3",
+						ResourceType: "AWS::S3::Bucket",
+						ResourceID:   "driftctl-bucket",
+						ResourceName: "driftctl-bucket",
+					},
+					{
+						Region:        "us-east-1",
+						ResourceType:  "AWS::IAM::Role",
+						ResourceID:    "AROAEXAMPLE",
+						ResourceName:  "driftctl",
+						Configuration: map[string]interface{}{"path": "/"},
+					},
+					{
+						Region:        "eu-west-3",
+						ResourceType:  "AWS::IAM::Role",
+						ResourceID:    "AROAEXAMPLE",
+						ResourceName:  "driftctl",
+						Configuration: map[string]interface{}{"path": "/"},
+					},
+					{
+						Region:       "us-east-1",
+						ResourceType: "AWS::IAM::Role",
+						ResourceID:   "AROASUPPORT",
+						ResourceName: "AWSServiceRoleForSupport",
+					},
+					{
+						Region:        "eu-west-3",
+						ResourceType:  "AWS::KMS::Key",
+						ResourceID:    "aws-managed-key",
+						Configuration: map[string]interface{}{"keyManager": "AWS"},
+					},
+					{
+						Region:       "eu-west-3",
+						ResourceType: "AWS::SQS::Queue",
+						ResourceID:   "https://sqs.eu-west-3.amazonaws.com/210987654321/driftctl",
+						ResourceName: "driftctl",
+						ARN:          "arn:aws:sqs:eu-west-3:210987654321:driftctl",
+					},
+					{
+						Region:       "eu-west-3",
+						ResourceType: "AWS::ElasticLoadBalancingV2::LoadBalancer",
+						ResourceID:   "arn:aws:elasticloadbalancing:eu-west-3:210987654321:loadbalancer/app/driftctl/0123456789abcdef",
+						ResourceName: "driftctl",
+						ARN:          "arn:aws:elasticloadbalancing:eu-west-3:210987654321:loadbalancer/app/driftctl/0123456789abcdef",
+					},
+					{
+						Region:       "eu-west-3",
+						ResourceType: "AWS::Config::ConfigurationRecorder",
+						ResourceID:   "default",
+					},
+				}, nil)
+			},
+			assertExpected: func(t *testing.T, got []*resource.Resource) {
+				resources := make(map[string]*resource.Resource, len(got))
+				for _, res := range got {
+					resources[res.ResourceType()+"."+res.ResourceId()] = res
+				}
+				assert.Len(t, resources, 7)
+				assert.Contains(t, resources, resourceaws.AwsDefaultVpcResourceType+".vpc-default")
+				assert.Contains(t, resources, resourceaws.AwsVpcResourceType+".vpc-0a1b2c3d")
+				assert.Contains(t, resources, resourceaws.AwsDefaultRouteTableResourceType+".rtb-main")
+				assert.Contains(t, resources, resourceaws.AwsSqsQueueResourceType+".https://sqs.eu-west-3.amazonaws.com/210987654321/driftctl")
+
+				bucket := resources[resourceaws.AwsS3BucketResourceType+".driftctl-bucket"]
+				if assert.NotNil(t, bucket) {
+					assert.Equal(t, "eu-west-3", *bucket.Attributes().GetString("region"))
+				}
+				role := resources[resourceaws.AwsIamRoleResourceType+".driftctl"]
+				if assert.NotNil(t, role) {
+					assert.Equal(t, "/", *role.Attributes().GetString("path"))
+				}
+				lb := resources[resourceaws.AwsLoadBalancerResourceType+".arn:aws:elasticloadbalancing:eu-west-3:210987654321:loadbalancer/app/driftctl/0123456789abcdef"]
+				if assert.NotNil(t, lb) {
+					assert.Equal(t, "driftctl", *lb.Attributes().GetString("name"))
+				}
+			},
+		},
+		{
+			test: "same resource in several accounts",
+			mocks: func(repo *repository.MockConfigSnapshotRepository) {
+				repo.On("ListAllConfigurationItems").Return([]*repository.ConfigurationItem{
+					{
+						AccountID:     "123456789012",
+						Region:        "global",
+						ResourceType:  "AWS::IAM::Role",
+						ResourceID:    "AROAFIRST",
+						ResourceName:  "driftctl",
+						Configuration: map[string]interface{}{"path": "/"},
+					},
+					{
+						AccountID:     "210987654321",
+						Region:        "global",
+						ResourceType:  "AWS::IAM::Role",
+						ResourceID:    "AROASECOND",
+						ResourceName:  "driftctl",
+						Configuration: map[string]interface{}{"path": "/ci/"},
+					},
+				}, nil)
+			},
+			assertExpected: func(t *testing.T, got []*resource.Resource) {
+				assert.Len(t, got, 2)
+				paths := make([]string, 0, len(got))
+				for _, res := range got {
+					assert.Equal(t, "driftctl", res.ResourceId())
+					paths = append(paths, *res.Attributes().GetString("path"))
+				}
+				assert.ElementsMatch(t, []string{"/", "/ci/"}, paths)
+			},
+		},
+		{
+			test: "cannot read snapshot",
+			mocks: func(repo *repository.MockConfigSnapshotRepository) {
+				repo.On("ListAllConfigurationItems").Return(nil, dummyError)
+			},
+			// Enumerators run in parallel, the error of any resource type may be returned
+			wantErr: dummyError,
+		},
+	}
+
+	providerVersion := "3.19.0"
+	schemaRepository := testresource.InitFakeSchemaRepository("aws", providerVersion)
+	resourceaws.InitResourcesMetadata(schemaRepository)
+	factory := terraform.NewTerraformResourceFactory(schemaRepository)
+
+	for _, c := range tests {
+		t.Run(c.test, func(tt *testing.T) {
+			scanOptions := ScannerOptions{}
+			remoteLibrary := common.NewRemoteLibrary()
+
+			// Initialize mocks
+			alerter := &mocks.AlerterInterface{}
+			fakeRepo := &repository.MockConfigSnapshotRepository{}
+			c.mocks(fakeRepo)
+
+			var repo repository.ConfigSnapshotRepository = fakeRepo
+
+			for _, enumerator := range aws.NewConfigSnapshotEnumerators(repo, factory) {
+				remoteLibrary.AddEnumerator(enumerator)
+			}
+
+			testFilter := &filter.MockFilter{}
+			testFilter.On("IsTypeIgnored", mock.Anything).Return(false)
+
+			s := NewScanner(remoteLibrary, alerter, scanOptions, testFilter)
+			got, err := s.Resources()
+			if c.wantErr != nil {
+				if assert.IsType(tt, &remoteerr.ResourceScanningError{}, err) {
+					assert.Equal(tt, c.wantErr, err.(*remoteerr.ResourceScanningError).RootCause())
+				}
+				return
+			}
+			assert.NoError(tt, err)
+
+			c.assertExpected(tt, got)
+			alerter.AssertExpectations(tt)
+			fakeRepo.AssertExpectations(tt)
+			testFilter.AssertExpectations(tt)
+		})
+	}
+}
+
+func TestConfigSnapshotIdentity(t *testing.T) {
+	repo := &repository.MockConfigSnapshotRepository{}
+	repo.On("ListAllConfigurationItems").Return([]*repository.ConfigurationItem{
+		{AccountID: "210987654321", Region: "eu-west-3", ResourceType: "AWS::EC2::VPC", ResourceID: "vpc-0a1b2c3d"},
+		{AccountID: "123456789012", Region: "us-east-1", ResourceType: "AWS::EC2::VPC", ResourceID: "vpc-default"},
+		{AccountID: "123456789012", Region: "global", ResourceType: "AWS::IAM::User", ResourceID: "AIDAEXAMPLE"},
+	}, nil).Once()
+	repo.On("ListAllConfigurationItems").Return(nil, errors.New("access denied")).Once()
+
+	identity, err := aws.ConfigSnapshotIdentity(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, common.Identity{Account: "123456789012,210987654321", Region: "eu-west-3,us-east-1"}, identity)
+
+	_, err = aws.ConfigSnapshotIdentity(repo)
+	assert.EqualError(t, err, "access denied")
+	repo.AssertExpectations(t)
+}
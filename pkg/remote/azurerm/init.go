@@ -1,18 +1,30 @@
 package azurerm
 
 import (
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
 	"github.com/snyk/driftctl/pkg/alerter"
 	"github.com/snyk/driftctl/pkg/output"
 	"github.com/snyk/driftctl/pkg/remote/azurerm/repository"
 	"github.com/snyk/driftctl/pkg/remote/cache"
 	"github.com/snyk/driftctl/pkg/remote/common"
+	remoteerror "github.com/snyk/driftctl/pkg/remote/error"
 	"github.com/snyk/driftctl/pkg/resource"
 	"github.com/snyk/driftctl/pkg/resource/azurerm"
 	"github.com/snyk/driftctl/pkg/terraform"
 )
 
+// Options tells how to scan Azure, on top of the options shared by every remote
+type Options struct {
+	// ResourceGraph lists top level resources with Resource Graph queries instead of ARM listing calls.
+	// Resource Graph silently leaves out resources the caller can't read and may lag behind ARM.
+	ResourceGraph bool
+}
+
 func Init(
 	version string,
 	alerter *alerter.Alerter,
@@ -21,37 +33,60 @@ func Init(
 	progress output.Progress,
 	resourceSchemaRepository *resource.SchemaRepository,
 	factory resource.ResourceFactory,
-	configDir string) error {
+	opts common.Options,
+	azureOpts Options) error {
 
-	provider, err := NewAzureTerraformProvider(version, progress, configDir)
+	provider, err := NewAzureTerraformProvider(version, progress, opts.ProviderInstallOptions, opts.Recorder)
 	if err != nil {
 		return err
 	}
 	err = provider.CheckCredentialsExist()
 	if err != nil {
-		return err
+		return remoteerror.NewCredentialsError(err)
 	}
 	err = provider.Init()
 	if err != nil {
-		return err
+		return remoteerror.NewProviderInitError(err)
 	}
+	remoteLibrary.ResolveIdentity(provider)
 
 	providerConfig := provider.GetConfig()
-	cred, err := azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{})
-	if err != nil {
-		return err
+	var cred azcore.TokenCredential = replayCredential{}
+	if !opts.Recorder.IsReplaying() {
+		cred, err = azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{})
+		if err != nil {
+			return err
+		}
+	}
+	clientOptions := &arm.ClientOptions{
+		ClientOptions: policy.ClientOptions{
+			// Retries are handled by our transport so they follow the scan retry policy
+			Retry:     policy.RetryOptions{MaxRetries: -1},
+			Transport: &http.Client{Transport: opts.PageLimiter.Transport(opts.RetryPolicy.Transport(opts.Recorder.Transport(http.DefaultTransport)))},
+		},
 	}
-	clientOptions := &arm.ClientOptions{}
 
 	c := cache.New(100)
 
-	storageAccountRepo := repository.NewStorageRepository(cred, clientOptions, providerConfig, c)
-	networkRepo := repository.NewNetworkRepository(cred, clientOptions, providerConfig, c)
-	resourcesRepo := repository.NewResourcesRepository(cred, clientOptions, providerConfig, c)
-	containerRegistryRepo := repository.NewContainerRegistryRepository(cred, clientOptions, providerConfig, c)
-	postgresqlRepo := repository.NewPostgresqlRepository(cred, clientOptions, providerConfig, c)
-	privateDNSRepo := repository.NewPrivateDNSRepository(cred, clientOptions, providerConfig, c)
-	computeRepo := repository.NewComputeRepository(cred, clientOptions, providerConfig, c)
+	var storageAccountRepo repository.StorageRespository = repository.NewStorageRepository(cred, clientOptions, providerConfig, c)
+	var networkRepo repository.NetworkRepository = repository.NewNetworkRepository(cred, clientOptions, providerConfig, c)
+	var resourcesRepo repository.ResourcesRepository = repository.NewResourcesRepository(cred, clientOptions, providerConfig, c)
+	var containerRegistryRepo repository.ContainerRegistryRepository = repository.NewContainerRegistryRepository(cred, clientOptions, providerConfig, c)
+	var postgresqlRepo repository.PostgresqlRespository = repository.NewPostgresqlRepository(cred, clientOptions, providerConfig, c)
+	var privateDNSRepo repository.PrivateDNSRepository = repository.NewPrivateDNSRepository(cred, clientOptions, providerConfig, c)
+	var computeRepo repository.ComputeRepository = repository.NewComputeRepository(cred, clientOptions, providerConfig, c)
+
+	if azureOpts.ResourceGraph {
+		// Top level resources are listed with Resource Graph, ARM clients list child resources
+		graphRepo := repository.NewResourceGraphRepository(cred, clientOptions, providerConfig, c)
+		storageAccountRepo = repository.NewResourceGraphStorageRepository(graphRepo, storageAccountRepo)
+		networkRepo = repository.NewResourceGraphNetworkRepository(graphRepo, networkRepo)
+		resourcesRepo = repository.NewResourceGraphResourcesRepository(graphRepo, resourcesRepo)
+		containerRegistryRepo = repository.NewResourceGraphContainerRegistryRepository(graphRepo, containerRegistryRepo)
+		postgresqlRepo = repository.NewResourceGraphPostgresqlRepository(graphRepo, postgresqlRepo)
+		privateDNSRepo = repository.NewResourceGraphPrivateDNSRepository(graphRepo, privateDNSRepo)
+		computeRepo = repository.NewResourceGraphComputeRepository(graphRepo, computeRepo)
+	}
 
 	providerLibrary.AddProvider(terraform.AZURE, provider)
 	deserializer := resource.NewDeserializer(factory)
@@ -0,0 +1,37 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package repository
+
+import (
+	json "encoding/json"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// MockResourceGraphRepository is an autogenerated mock type for the ResourceGraphRepository type
+type MockResourceGraphRepository struct {
+	mock.Mock
+}
+
+// ListAllResources provides a mock function with given fields:
+func (_m *MockResourceGraphRepository) ListAllResources() (map[string][]json.RawMessage, error) {
+	ret := _m.Called()
+
+	var r0 map[string][]json.RawMessage
+	if rf, ok := ret.Get(0).(func() map[string][]json.RawMessage); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string][]json.RawMessage)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,35 @@
+// Code generated by mockery v0.0.0-dev. DO NOT EDIT.
+
+package repository
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// mockResourceGraphClient is an autogenerated mock type for the resourceGraphClient type
+type mockResourceGraphClient struct {
+	mock.Mock
+}
+
+// Resources provides a mock function with given fields: ctx, request
+func (_m *mockResourceGraphClient) Resources(ctx context.Context, request resourceGraphQueryRequest) (resourceGraphQueryResponse, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 resourceGraphQueryResponse
+	if rf, ok := ret.Get(0).(func(context.Context, resourceGraphQueryRequest) resourceGraphQueryResponse); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Get(0).(resourceGraphQueryResponse)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, resourceGraphQueryRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
@@ -0,0 +1,421 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	armruntime "github.com/Azure/azure-sdk-for-go/sdk/azcore/arm/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/compute/armcompute"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/containerregistry/armcontainerregistry"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/postgresql/armpostgresql"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/privatedns/armprivatedns"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/storage/armstorage"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/remote/azurerm/common"
+	"github.com/snyk/driftctl/pkg/remote/cache"
+)
+
+const (
+	resourceGraphAPIVersion = "2021-03-01"
+	resourceGraphPageSize   = 1000
+
+	resourceGraphVirtualNetworkType       = "microsoft.network/virtualnetworks"
+	resourceGraphRouteTableType           = "microsoft.network/routetables"
+	resourceGraphFirewallType             = "microsoft.network/azurefirewalls"
+	resourceGraphPublicIPAddressType      = "microsoft.network/publicipaddresses"
+	resourceGraphNetworkSecurityGroupType = "microsoft.network/networksecuritygroups"
+	resourceGraphLoadBalancerType         = "microsoft.network/loadbalancers"
+	resourceGraphPrivateDNSZoneType       = "microsoft.network/privatednszones"
+	resourceGraphStorageAccountType       = "microsoft.storage/storageaccounts"
+	resourceGraphContainerRegistryType    = "microsoft.containerregistry/registries"
+	resourceGraphPostgresqlServerType     = "microsoft.dbforpostgresql/servers"
+	resourceGraphImageType                = "microsoft.compute/images"
+	resourceGraphSSHPublicKeyType         = "microsoft.compute/sshpublickeys"
+	resourceGraphResourceGroupType        = "microsoft.resources/subscriptions/resourcegroups"
+)
+
+// resourceGraphTypes lists the types fetched from Resource Graph, child resources like subnets or DNS records are not
+// exposed as resources by Resource Graph and are still listed with the ARM clients
+var resourceGraphTypes = []string{
+	resourceGraphVirtualNetworkType,
+	resourceGraphRouteTableType,
+	resourceGraphFirewallType,
+	resourceGraphPublicIPAddressType,
+	resourceGraphNetworkSecurityGroupType,
+	resourceGraphLoadBalancerType,
+	resourceGraphPrivateDNSZoneType,
+	resourceGraphStorageAccountType,
+	resourceGraphContainerRegistryType,
+	resourceGraphPostgresqlServerType,
+	resourceGraphImageType,
+	resourceGraphSSHPublicKeyType,
+}
+
+// resourceGraphQuery returns every supported resource of the subscription, resource groups live in the
+// resourcecontainers table
+func resourceGraphQuery() string {
+	types := make([]string, 0, len(resourceGraphTypes))
+	for _, t := range resourceGraphTypes {
+		types = append(types, fmt.Sprintf("'%s'", t))
+	}
+	return fmt.Sprintf(
+		"resources | where type in~ (%s) | union (resourcecontainers | where type =~ '%s') | project id, name, type, location, tags, properties",
+		strings.Join(types, ", "),
+		resourceGraphResourceGroupType,
+	)
+}
+
+type resourceGraphQueryOptions struct {
+	Top          int    `json:"$top,omitempty"`
+	SkipToken    string `json:"$skipToken,omitempty"`
+	ResultFormat string `json:"resultFormat"`
+}
+
+type resourceGraphQueryRequest struct {
+	Subscriptions []string                  `json:"subscriptions"`
+	Query         string                    `json:"query"`
+	Options       resourceGraphQueryOptions `json:"options"`
+}
+
+type resourceGraphQueryResponse struct {
+	Count     int               `json:"count"`
+	SkipToken string            `json:"$skipToken,omitempty"`
+	Data      []json.RawMessage `json:"data"`
+}
+
+type resourceGraphClient interface {
+	Resources(ctx context.Context, request resourceGraphQueryRequest) (resourceGraphQueryResponse, error)
+}
+
+// resourceGraphClientImpl calls the Resource Graph REST API as the SDK doesn't ship a Resource Graph client for the
+// azcore version we use
+type resourceGraphClientImpl struct {
+	host     string
+	pipeline runtime.Pipeline
+}
+
+func (c resourceGraphClientImpl) Resources(ctx context.Context, request resourceGraphQueryRequest) (resourceGraphQueryResponse, error) {
+	result := resourceGraphQueryResponse{}
+	req, err := runtime.NewRequest(ctx, http.MethodPost, runtime.JoinPaths(c.host, "/providers/Microsoft.ResourceGraph/resources"))
+	if err != nil {
+		return result, err
+	}
+	query := req.Raw().URL.Query()
+	query.Set("api-version", resourceGraphAPIVersion)
+	req.Raw().URL.RawQuery = query.Encode()
+	req.Raw().Header.Set("Accept", "application/json")
+	if err := runtime.MarshalAsJSON(req, request); err != nil {
+		return result, err
+	}
+
+	resp, err := c.pipeline.Do(req)
+	if err != nil {
+		return result, err
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return result, runtime.NewResponseError(errors.Errorf("unexpected status code %d", resp.StatusCode), resp)
+	}
+	if err := runtime.UnmarshalAsJSON(resp, &result); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// ResourceGraphRepository lists every supported resource of the subscription in a few paged Resource Graph queries
+// instead of one paged ARM call per type
+type ResourceGraphRepository interface {
+	ListAllResources() (map[string][]json.RawMessage, error)
+}
+
+type resourceGraphRepository struct {
+	client         resourceGraphClient
+	subscriptionID string
+	cache          cache.Cache
+}
+
+func NewResourceGraphRepository(cred azcore.TokenCredential, options *arm.ClientOptions, config common.AzureProviderConfig, cache cache.Cache) *resourceGraphRepository {
+	cp := arm.ClientOptions{}
+	if options != nil {
+		cp = *options
+	}
+	host := string(cp.Host)
+	if host == "" {
+		host = string(arm.AzurePublicCloud)
+	}
+	return &resourceGraphRepository{
+		&resourceGraphClientImpl{
+			host:     host,
+			pipeline: armruntime.NewPipeline("armresourcegraph", "v0.2.0", cred, &cp),
+		},
+		config.SubscriptionID,
+		cache,
+	}
+}
+
+// ListAllResources returns the rows of the Resource Graph query grouped by lowercase resource type
+func (r *resourceGraphRepository) ListAllResources() (map[string][]json.RawMessage, error) {
+	cacheKey := "resourceGraphListAllResources"
+	v := r.cache.GetAndLock(cacheKey)
+	defer r.cache.Unlock(cacheKey)
+	if v != nil {
+		// A failed query is cached too so Resource Graph is only queried once per scan
+		if err, ok := v.(error); ok {
+			return nil, err
+		}
+		return v.(map[string][]json.RawMessage), nil
+	}
+
+	results, err := r.query()
+	if err != nil {
+		logrus.WithField("error", err).Warn("Unable to query Azure Resource Graph, resources will be listed type by type")
+		r.cache.Put(cacheKey, err)
+		return nil, err
+	}
+
+	r.cache.Put(cacheKey, results)
+
+	return results, nil
+}
+
+func (r *resourceGraphRepository) query() (map[string][]json.RawMessage, error) {
+	results := make(map[string][]json.RawMessage)
+	request := resourceGraphQueryRequest{
+		Subscriptions: []string{r.subscriptionID},
+		Query:         resourceGraphQuery(),
+		Options: resourceGraphQueryOptions{
+			Top:          resourceGraphPageSize,
+			ResultFormat: "objectArray",
+		},
+	}
+	for {
+		resp, err := r.client.Resources(context.Background(), request)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range resp.Data {
+			var header struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(row, &header); err != nil {
+				return nil, errors.Wrap(err, "unable to read Resource Graph result")
+			}
+			resourceType := strings.ToLower(header.Type)
+			results[resourceType] = append(results[resourceType], row)
+		}
+		if resp.SkipToken == "" {
+			break
+		}
+		request.Options.SkipToken = resp.SkipToken
+	}
+	return results, nil
+}
+
+// resourceGraphLister decodes Resource Graph rows into ARM SDK models, it returns false when Resource Graph can't be
+// queried so callers fall back to the ARM clients
+type resourceGraphLister struct {
+	repository ResourceGraphRepository
+}
+
+func (l resourceGraphLister) list(resourceType string, results interface{}) bool {
+	resources, err := l.repository.ListAllResources()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"type":  resourceType,
+			"error": err,
+		}).Debug("Unable to list resources with Resource Graph, falling back to ARM clients")
+		return false
+	}
+
+	rows := resources[resourceType]
+	if rows == nil {
+		rows = []json.RawMessage{}
+	}
+	content, err := json.Marshal(rows)
+	if err == nil {
+		err = json.Unmarshal(content, results)
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"type":  resourceType,
+			"error": err,
+		}).Debug("Unable to decode Resource Graph result, falling back to ARM clients")
+		return false
+	}
+	return true
+}
+
+type resourceGraphNetworkRepository struct {
+	NetworkRepository
+	graph resourceGraphLister
+}
+
+func NewResourceGraphNetworkRepository(graph ResourceGraphRepository, fallback NetworkRepository) *resourceGraphNetworkRepository {
+	return &resourceGraphNetworkRepository{fallback, resourceGraphLister{graph}}
+}
+
+func (r *resourceGraphNetworkRepository) ListAllVirtualNetworks() ([]*armnetwork.VirtualNetwork, error) {
+	results := make([]*armnetwork.VirtualNetwork, 0)
+	if r.graph.list(resourceGraphVirtualNetworkType, &results) {
+		return results, nil
+	}
+	return r.NetworkRepository.ListAllVirtualNetworks()
+}
+
+func (r *resourceGraphNetworkRepository) ListAllRouteTables() ([]*armnetwork.RouteTable, error) {
+	results := make([]*armnetwork.RouteTable, 0)
+	if r.graph.list(resourceGraphRouteTableType, &results) {
+		return results, nil
+	}
+	return r.NetworkRepository.ListAllRouteTables()
+}
+
+func (r *resourceGraphNetworkRepository) ListAllFirewalls() ([]*armnetwork.AzureFirewall, error) {
+	results := make([]*armnetwork.AzureFirewall, 0)
+	if r.graph.list(resourceGraphFirewallType, &results) {
+		return results, nil
+	}
+	return r.NetworkRepository.ListAllFirewalls()
+}
+
+func (r *resourceGraphNetworkRepository) ListAllPublicIPAddresses() ([]*armnetwork.PublicIPAddress, error) {
+	results := make([]*armnetwork.PublicIPAddress, 0)
+	if r.graph.list(resourceGraphPublicIPAddressType, &results) {
+		return results, nil
+	}
+	return r.NetworkRepository.ListAllPublicIPAddresses()
+}
+
+func (r *resourceGraphNetworkRepository) ListAllSecurityGroups() ([]*armnetwork.NetworkSecurityGroup, error) {
+	results := make([]*armnetwork.NetworkSecurityGroup, 0)
+	if r.graph.list(resourceGraphNetworkSecurityGroupType, &results) {
+		return results, nil
+	}
+	return r.NetworkRepository.ListAllSecurityGroups()
+}
+
+func (r *resourceGraphNetworkRepository) ListAllLoadBalancers() ([]*armnetwork.LoadBalancer, error) {
+	results := make([]*armnetwork.LoadBalancer, 0)
+	if r.graph.list(resourceGraphLoadBalancerType, &results) {
+		return results, nil
+	}
+	return r.NetworkRepository.ListAllLoadBalancers()
+}
+
+type resourceGraphStorageRepository struct {
+	StorageRespository
+	graph resourceGraphLister
+}
+
+func NewResourceGraphStorageRepository(graph ResourceGraphRepository, fallback StorageRespository) *resourceGraphStorageRepository {
+	return &resourceGraphStorageRepository{fallback, resourceGraphLister{graph}}
+}
+
+func (r *resourceGraphStorageRepository) ListAllStorageAccount() ([]*armstorage.StorageAccount, error) {
+	results := make([]*armstorage.StorageAccount, 0)
+	if r.graph.list(resourceGraphStorageAccountType, &results) {
+		return results, nil
+	}
+	return r.StorageRespository.ListAllStorageAccount()
+}
+
+type resourceGraphContainerRegistryRepository struct {
+	ContainerRegistryRepository
+	graph resourceGraphLister
+}
+
+func NewResourceGraphContainerRegistryRepository(graph ResourceGraphRepository, fallback ContainerRegistryRepository) *resourceGraphContainerRegistryRepository {
+	return &resourceGraphContainerRegistryRepository{fallback, resourceGraphLister{graph}}
+}
+
+func (r *resourceGraphContainerRegistryRepository) ListAllContainerRegistries() ([]*armcontainerregistry.Registry, error) {
+	results := make([]*armcontainerregistry.Registry, 0)
+	if r.graph.list(resourceGraphContainerRegistryType, &results) {
+		return results, nil
+	}
+	return r.ContainerRegistryRepository.ListAllContainerRegistries()
+}
+
+type resourceGraphPostgresqlRepository struct {
+	PostgresqlRespository
+	graph resourceGraphLister
+}
+
+func NewResourceGraphPostgresqlRepository(graph ResourceGraphRepository, fallback PostgresqlRespository) *resourceGraphPostgresqlRepository {
+	return &resourceGraphPostgresqlRepository{fallback, resourceGraphLister{graph}}
+}
+
+func (r *resourceGraphPostgresqlRepository) ListAllServers() ([]*armpostgresql.Server, error) {
+	results := make([]*armpostgresql.Server, 0)
+	if r.graph.list(resourceGraphPostgresqlServerType, &results) {
+		return results, nil
+	}
+	return r.PostgresqlRespository.ListAllServers()
+}
+
+type resourceGraphPrivateDNSRepository struct {
+	PrivateDNSRepository
+	graph resourceGraphLister
+}
+
+func NewResourceGraphPrivateDNSRepository(graph ResourceGraphRepository, fallback PrivateDNSRepository) *resourceGraphPrivateDNSRepository {
+	return &resourceGraphPrivateDNSRepository{fallback, resourceGraphLister{graph}}
+}
+
+func (r *resourceGraphPrivateDNSRepository) ListAllPrivateZones() ([]*armprivatedns.PrivateZone, error) {
+	results := make([]*armprivatedns.PrivateZone, 0)
+	if r.graph.list(resourceGraphPrivateDNSZoneType, &results) {
+		return results, nil
+	}
+	return r.PrivateDNSRepository.ListAllPrivateZones()
+}
+
+type resourceGraphComputeRepository struct {
+	ComputeRepository
+	graph resourceGraphLister
+}
+
+func NewResourceGraphComputeRepository(graph ResourceGraphRepository, fallback ComputeRepository) *resourceGraphComputeRepository {
+	return &resourceGraphComputeRepository{fallback, resourceGraphLister{graph}}
+}
+
+func (r *resourceGraphComputeRepository) ListAllImages() ([]*armcompute.Image, error) {
+	results := make([]*armcompute.Image, 0)
+	if r.graph.list(resourceGraphImageType, &results) {
+		return results, nil
+	}
+	return r.ComputeRepository.ListAllImages()
+}
+
+func (r *resourceGraphComputeRepository) ListAllSSHPublicKeys() ([]*armcompute.SSHPublicKeyResource, error) {
+	results := make([]*armcompute.SSHPublicKeyResource, 0)
+	if r.graph.list(resourceGraphSSHPublicKeyType, &results) {
+		return results, nil
+	}
+	return r.ComputeRepository.ListAllSSHPublicKeys()
+}
+
+type resourceGraphResourcesRepository struct {
+	ResourcesRepository
+	graph resourceGraphLister
+}
+
+func NewResourceGraphResourcesRepository(graph ResourceGraphRepository, fallback ResourcesRepository) *resourceGraphResourcesRepository {
+	return &resourceGraphResourcesRepository{fallback, resourceGraphLister{graph}}
+}
+
+func (r *resourceGraphResourcesRepository) ListAllResourceGroups() ([]*armresources.ResourceGroup, error) {
+	results := make([]*armresources.ResourceGroup, 0)
+	if r.graph.list(resourceGraphResourceGroupType, &results) {
+		return results, nil
+	}
+	return r.ResourcesRepository.ListAllResourceGroups()
+}
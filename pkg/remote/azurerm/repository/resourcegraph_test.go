@@ -0,0 +1,244 @@
+package repository
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/network/armnetwork"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/pkg/remote/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func Test_ResourceGraph_ListAllResources(t *testing.T) {
+	vnet := json.RawMessage(`{"id":"/subscriptions/008b5f48-1b66-4d92-a6b6-d215b4c9b473/resourceGroups/driftctl/providers/Microsoft.Network/virtualNetworks/network1","name":"network1","type":"microsoft.network/virtualnetworks"}`)
+	group := json.RawMessage(`{"id":"/subscriptions/008b5f48-1b66-4d92-a6b6-d215b4c9b473/resourceGroups/driftctl","name":"driftctl","type":"microsoft.resources/subscriptions/resourcegroups"}`)
+	zone := json.RawMessage(`{"id":"/subscriptions/008b5f48-1b66-4d92-a6b6-d215b4c9b473/resourceGroups/driftctl/providers/Microsoft.Network/privateDnsZones/driftctl.com","name":"driftctl.com","type":"Microsoft.Network/privateDnsZones"}`)
+
+	expectedResults := map[string][]json.RawMessage{
+		"microsoft.network/virtualnetworks":                {vnet},
+		"microsoft.resources/subscriptions/resourcegroups": {group},
+		"microsoft.network/privatednszones":                {zone},
+	}
+
+	firstPage := resourceGraphQueryRequest{
+		Subscriptions: []string{"008b5f48-1b66-4d92-a6b6-d215b4c9b473"},
+		Query:         resourceGraphQuery(),
+		Options:       resourceGraphQueryOptions{Top: 1000, ResultFormat: "objectArray"},
+	}
+	secondPage := firstPage
+	secondPage.Options.SkipToken = "token"
+
+	testcases := []struct {
+		name     string
+		mocks    func(*mockResourceGraphClient, *cache.MockCache)
+		expected map[string][]json.RawMessage
+		wantErr  string
+	}{
+		{
+			name: "should return resources grouped by type",
+			mocks: func(client *mockResourceGraphClient, mockCache *cache.MockCache) {
+				client.On("Resources", mock.Anything, firstPage).Return(resourceGraphQueryResponse{
+					Count:     2,
+					SkipToken: "token",
+					Data:      []json.RawMessage{vnet, group},
+				}, nil).Once()
+				client.On("Resources", mock.Anything, secondPage).Return(resourceGraphQueryResponse{
+					Count: 1,
+					Data:  []json.RawMessage{zone},
+				}, nil).Once()
+
+				mockCache.On("GetAndLock", "resourceGraphListAllResources").Return(nil).Times(1)
+				mockCache.On("Put", "resourceGraphListAllResources", expectedResults).Return(false).Times(1)
+				mockCache.On("Unlock", "resourceGraphListAllResources").Times(1)
+			},
+			expected: expectedResults,
+		},
+		{
+			name: "should hit cache and return resources",
+			mocks: func(client *mockResourceGraphClient, mockCache *cache.MockCache) {
+				mockCache.On("GetAndLock", "resourceGraphListAllResources").Return(expectedResults).Times(1)
+				mockCache.On("Unlock", "resourceGraphListAllResources").Times(1)
+			},
+			expected: expectedResults,
+		},
+		{
+			name: "should return and cache remote error",
+			mocks: func(client *mockResourceGraphClient, mockCache *cache.MockCache) {
+				remoteErr := errors.New("remote error")
+				client.On("Resources", mock.Anything, firstPage).Return(resourceGraphQueryResponse{}, remoteErr).Once()
+
+				mockCache.On("GetAndLock", "resourceGraphListAllResources").Return(nil).Times(1)
+				mockCache.On("Put", "resourceGraphListAllResources", remoteErr).Return(false).Times(1)
+				mockCache.On("Unlock", "resourceGraphListAllResources").Times(1)
+			},
+			wantErr: "remote error",
+		},
+		{
+			name: "should return cached remote error",
+			mocks: func(client *mockResourceGraphClient, mockCache *cache.MockCache) {
+				mockCache.On("GetAndLock", "resourceGraphListAllResources").Return(errors.New("remote error")).Times(1)
+				mockCache.On("Unlock", "resourceGraphListAllResources").Times(1)
+			},
+			wantErr: "remote error",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &mockResourceGraphClient{}
+			mockCache := &cache.MockCache{}
+
+			tt.mocks(client, mockCache)
+
+			r := &resourceGraphRepository{
+				client:         client,
+				subscriptionID: "008b5f48-1b66-4d92-a6b6-d215b4c9b473",
+				cache:          mockCache,
+			}
+			got, err := r.ListAllResources()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.Nil(t, err)
+			}
+
+			client.AssertExpectations(t)
+			mockCache.AssertExpectations(t)
+
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func Test_ResourceGraph_ListAllVirtualNetworks(t *testing.T) {
+	testcases := []struct {
+		name     string
+		mocks    func(*MockResourceGraphRepository, *MockNetworkRepository)
+		expected []*armnetwork.VirtualNetwork
+		wantErr  string
+	}{
+		{
+			name: "should decode virtual networks from Resource Graph",
+			mocks: func(graph *MockResourceGraphRepository, fallback *MockNetworkRepository) {
+				graph.On("ListAllResources").Return(map[string][]json.RawMessage{
+					"microsoft.network/virtualnetworks": {
+						json.RawMessage(`{"id":"/subscriptions/008b5f48-1b66-4d92-a6b6-d215b4c9b473/resourceGroups/driftctl/providers/Microsoft.Network/virtualNetworks/network1","name":"network1","type":"microsoft.network/virtualnetworks","location":"westeurope","tags":null,"properties":{"addressSpace":{"addressPrefixes":["10.0.0.0/16"]}}}`),
+					},
+				}, nil).Once()
+			},
+			expected: []*armnetwork.VirtualNetwork{
+				{
+					Resource: armnetwork.Resource{
+						ID:       to.StringPtr("/subscriptions/008b5f48-1b66-4d92-a6b6-d215b4c9b473/resourceGroups/driftctl/providers/Microsoft.Network/virtualNetworks/network1"),
+						Name:     to.StringPtr("network1"),
+						Type:     to.StringPtr("microsoft.network/virtualnetworks"),
+						Location: to.StringPtr("westeurope"),
+					},
+					Properties: &armnetwork.VirtualNetworkPropertiesFormat{
+						AddressSpace: &armnetwork.AddressSpace{
+							AddressPrefixes: []*string{to.StringPtr("10.0.0.0/16")},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "should return an empty list when Resource Graph has no virtual network",
+			mocks: func(graph *MockResourceGraphRepository, fallback *MockNetworkRepository) {
+				graph.On("ListAllResources").Return(map[string][]json.RawMessage{}, nil).Once()
+			},
+			expected: []*armnetwork.VirtualNetwork{},
+		},
+		{
+			name: "should fall back to ARM client when Resource Graph fails",
+			mocks: func(graph *MockResourceGraphRepository, fallback *MockNetworkRepository) {
+				graph.On("ListAllResources").Return(nil, errors.New("resource graph error")).Once()
+				fallback.On("ListAllVirtualNetworks").Return([]*armnetwork.VirtualNetwork{
+					{Resource: armnetwork.Resource{ID: to.StringPtr("network1")}},
+				}, nil).Once()
+			},
+			expected: []*armnetwork.VirtualNetwork{
+				{Resource: armnetwork.Resource{ID: to.StringPtr("network1")}},
+			},
+		},
+		{
+			name: "should return ARM client error",
+			mocks: func(graph *MockResourceGraphRepository, fallback *MockNetworkRepository) {
+				graph.On("ListAllResources").Return(nil, errors.New("resource graph error")).Once()
+				fallback.On("ListAllVirtualNetworks").Return(nil, errors.New("remote error")).Once()
+			},
+			wantErr: "remote error",
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			graph := &MockResourceGraphRepository{}
+			fallback := &MockNetworkRepository{}
+
+			tt.mocks(graph, fallback)
+
+			r := NewResourceGraphNetworkRepository(graph, fallback)
+			got, err := r.ListAllVirtualNetworks()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.Nil(t, err)
+			}
+
+			graph.AssertExpectations(t)
+			fallback.AssertExpectations(t)
+
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func Test_ResourceGraph_FallbackForChildResources(t *testing.T) {
+	graph := &MockResourceGraphRepository{}
+	fallback := &MockNetworkRepository{}
+
+	network := &armnetwork.VirtualNetwork{Resource: armnetwork.Resource{ID: to.StringPtr("network1")}}
+	subnets := []*armnetwork.Subnet{{SubResource: armnetwork.SubResource{ID: to.StringPtr("subnet1")}}}
+	fallback.On("ListAllSubnets", network).Return(subnets, nil).Once()
+
+	r := NewResourceGraphNetworkRepository(graph, fallback)
+	got, err := r.ListAllSubnets(network)
+	assert.Nil(t, err)
+	assert.Equal(t, subnets, got)
+
+	graph.AssertExpectations(t)
+	fallback.AssertExpectations(t)
+}
+
+func Test_ResourceGraph_ListAllResourceGroups(t *testing.T) {
+	graph := &MockResourceGraphRepository{}
+	fallback := &MockResourcesRepository{}
+
+	graph.On("ListAllResources").Return(map[string][]json.RawMessage{
+		"microsoft.resources/subscriptions/resourcegroups": {
+			json.RawMessage(`{"id":"/subscriptions/008b5f48-1b66-4d92-a6b6-d215b4c9b473/resourceGroups/driftctl","name":"driftctl","type":"microsoft.resources/subscriptions/resourcegroups","location":"westeurope","tags":{"env":"dev"},"properties":{"provisioningState":"Succeeded"}}`),
+		},
+	}, nil).Once()
+
+	r := NewResourceGraphResourcesRepository(graph, fallback)
+	got, err := r.ListAllResourceGroups()
+	assert.Nil(t, err)
+	assert.Equal(t, []*armresources.ResourceGroup{
+		{
+			ID:         to.StringPtr("/subscriptions/008b5f48-1b66-4d92-a6b6-d215b4c9b473/resourceGroups/driftctl"),
+			Name:       to.StringPtr("driftctl"),
+			Type:       to.StringPtr("microsoft.resources/subscriptions/resourcegroups"),
+			Location:   to.StringPtr("westeurope"),
+			Tags:       map[string]*string{"env": to.StringPtr("dev")},
+			Properties: &armresources.ResourceGroupProperties{ProvisioningState: to.StringPtr("Succeeded")},
+		},
+	}, got)
+
+	graph.AssertExpectations(t)
+	fallback.AssertExpectations(t)
+}
@@ -1,7 +1,9 @@
 package common
 
 import (
+	"github.com/sirupsen/logrus"
 	"github.com/snyk/driftctl/pkg/resource"
+	"github.com/snyk/driftctl/pkg/terraform"
 )
 
 type Enumerator interface {
@@ -9,15 +11,32 @@ type Enumerator interface {
 	Enumerate() ([]*resource.Resource, error)
 }
 
+// Identity describes what a remote scans, as resolved by its provider: the account, project or subscription,
+// the region and the identity of the credentials used
+type Identity struct {
+	Account string
+	Region  string
+	Caller  string
+}
+
+// RemoteProvider is the terraform provider of a remote, it also resolves what the remote scans
+type RemoteProvider interface {
+	terraform.TerraformProvider
+	// Identity returns what could be resolved of the scanned identity, along with the error that prevented
+	// resolving the rest of it
+	Identity() (Identity, error)
+}
+
 type RemoteLibrary struct {
 	enumerators     []Enumerator
 	detailsFetchers map[resource.ResourceType]DetailsFetcher
+	identity        Identity
 }
 
 func NewRemoteLibrary() *RemoteLibrary {
 	return &RemoteLibrary{
-		make([]Enumerator, 0),
-		make(map[resource.ResourceType]DetailsFetcher),
+		enumerators:     make([]Enumerator, 0),
+		detailsFetchers: make(map[resource.ResourceType]DetailsFetcher),
 	}
 }
 
@@ -36,3 +55,24 @@ func (r *RemoteLibrary) AddDetailsFetcher(ty resource.ResourceType, detailsFetch
 func (r *RemoteLibrary) GetDetailsFetcher(ty resource.ResourceType) DetailsFetcher {
 	return r.detailsFetchers[ty]
 }
+
+func (r *RemoteLibrary) SetIdentity(identity Identity) {
+	r.identity = identity
+}
+
+// ResolveIdentity records the identity scanned by the provider, failing to resolve it is not an error
+// as it is only used to describe the scan
+func (r *RemoteLibrary) ResolveIdentity(provider RemoteProvider) {
+	identity, err := provider.Identity()
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"provider": provider.Name(),
+			"error":    err,
+		}).Warn("Unable to resolve the scanned identity")
+	}
+	r.SetIdentity(identity)
+}
+
+func (r *RemoteLibrary) Identity() Identity {
+	return r.identity
+}
@@ -0,0 +1,20 @@
+package common
+
+import (
+	"github.com/snyk/driftctl/pkg/remote/paging"
+	"github.com/snyk/driftctl/pkg/remote/recording"
+	"github.com/snyk/driftctl/pkg/remote/retry"
+	"github.com/snyk/driftctl/pkg/terraform"
+)
+
+// Options tells how remotes are scanned, they are shared by every remote
+type Options struct {
+	// ProviderInstallOptions tells where to find the terraform provider and how to verify it
+	ProviderInstallOptions terraform.ProviderInstallOptions
+	// RetryPolicy retries calls failing with transient errors
+	RetryPolicy *retry.Policy
+	// Recorder records or replays responses of cloud provider APIs, nil when scanning live
+	Recorder *recording.Recorder
+	// PageLimiter only reads the first page of listing calls, nil to read every page
+	PageLimiter *paging.Limiter
+}
@@ -21,10 +21,30 @@ var remoteParameterMapping = map[RemoteParameter]string{
 	RemoteAzureTerraform:  tf.AZURE,
 }
 
-func (p RemoteParameter) GetProviderAddress() *lock.ProviderAddress {
+var remoteDefaultProviderVersion = map[RemoteParameter]string{
+	RemoteAWSTerraform:    "3.19.0",
+	RemoteGithubTerraform: "4.4.0",
+	RemoteGoogleTerraform: "3.78.0",
+	RemoteAzureTerraform:  "2.71.0",
+}
+
+// GetProviderKey returns the name of the terraform provider used to scan the remote, e.g. aws
+func (p RemoteParameter) GetProviderKey() string {
+	return remoteParameterMapping[p]
+}
+
+// GetDefaultProviderVersion returns the terraform provider version used when no version is set by the user
+func (p RemoteParameter) GetDefaultProviderVersion() string {
+	return remoteDefaultProviderVersion[p]
+}
+
+// GetProviderAddress returns the address of the terraform provider in the given registry, as found in lock files.
+// An empty registry stands for registry.terraform.io/hashicorp.
+func (p RemoteParameter) GetProviderAddress(registry string) *lock.ProviderAddress {
+	hostname, namespace := tf.ParseRegistry(registry)
 	return &lock.ProviderAddress{
-		Hostname:  "registry.terraform.io",
-		Namespace: "hashicorp",
+		Hostname:  hostname,
+		Namespace: namespace,
 		Type:      remoteParameterMapping[p],
 	}
 }
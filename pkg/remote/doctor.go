@@ -0,0 +1,141 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/alerter"
+	"github.com/snyk/driftctl/pkg/filter"
+	"github.com/snyk/driftctl/pkg/parallel"
+	"github.com/snyk/driftctl/pkg/remote/common"
+)
+
+type CheckStatus string
+
+const (
+	CheckPass CheckStatus = "pass"
+	CheckWarn CheckStatus = "warn"
+	CheckFail CheckStatus = "fail"
+)
+
+type CheckResult struct {
+	Name    string
+	Status  CheckStatus
+	Message string
+}
+
+// Doctor runs each enumerator of the remote library once to detect missing permissions before running a real scan.
+// Remotes are expected to be activated with a first page limiter and resource details are never fetched, so probing
+// stays cheap even for accounts where a scan takes a long time.
+type Doctor struct {
+	runner        *parallel.ParallelRunner
+	remoteLibrary *common.RemoteLibrary
+	alerter       *alerter.Alerter
+	filter        filter.Filter
+}
+
+func NewDoctor(remoteLibrary *common.RemoteLibrary, alerter *alerter.Alerter, filter filter.Filter) *Doctor {
+	return &Doctor{
+		runner:        parallel.NewParallelRunner(context.TODO(), 10),
+		remoteLibrary: remoteLibrary,
+		alerter:       alerter,
+		filter:        filter,
+	}
+}
+
+type probeResult struct {
+	resourceType string
+	err          error
+}
+
+// Probe returns a check result per enumerated resource type, sorted by type.
+// Access denied errors are reported as failures, other alerts raised during enumeration as warnings.
+func (d *Doctor) Probe() ([]CheckResult, error) {
+	for _, enumerator := range d.remoteLibrary.Enumerators() {
+		if d.filter.IsTypeIgnored(enumerator.SupportedType()) {
+			logrus.WithFields(logrus.Fields{
+				"type": enumerator.SupportedType(),
+			}).Debug("Ignored probe of resources since it is ignored in filter")
+			continue
+		}
+		enumerator := enumerator
+		d.runner.Run(func() (interface{}, error) {
+			_, err := enumerator.Enumerate()
+			if err != nil {
+				err = HandleResourceEnumerationError(err, d.alerter)
+			}
+			return probeResult{string(enumerator.SupportedType()), err}, nil
+		})
+	}
+
+	probes := make([]probeResult, 0)
+loop:
+	for {
+		select {
+		case res, ok := <-d.runner.Read():
+			if !ok || res == nil {
+				break loop
+			}
+			probes = append(probes, res.(probeResult))
+		case <-d.runner.DoneChan():
+			break loop
+		}
+	}
+	if err := d.runner.Err(); err != nil {
+		return nil, err
+	}
+
+	alerts := d.alerter.Retrieve()
+
+	results := make([]CheckResult, 0, len(probes))
+	for _, probe := range probes {
+		results = append(results, checkProbe(probe, alerts))
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Name < results[j].Name
+	})
+
+	return results, nil
+}
+
+func checkProbe(probe probeResult, alerts alerter.Alerts) CheckResult {
+	result := CheckResult{
+		Name:   probe.resourceType,
+		Status: CheckPass,
+	}
+
+	if probe.err != nil {
+		result.Status = CheckFail
+		result.Message = probe.err.Error()
+		return result
+	}
+
+	var messages []string
+	for key, typeAlerts := range alerts {
+		if key != probe.resourceType && !strings.HasPrefix(key, fmt.Sprintf("%s.", probe.resourceType)) {
+			continue
+		}
+		for _, alert := range typeAlerts {
+			// Alerts that ignore resources means that the scan would skip them, e.g. on access denied
+			if alert.ShouldIgnoreResource() {
+				result.Status = CheckFail
+			} else if result.Status != CheckFail {
+				result.Status = CheckWarn
+			}
+			messages = append(messages, alert.Message())
+		}
+	}
+	sort.Strings(messages)
+	result.Message = strings.Join(messages, "\n")
+
+	return result
+}
+
+func (d *Doctor) Stop() {
+	logrus.Debug("Stopping doctor")
+	d.runner.Stop(errors.New("interrupted"))
+}
@@ -0,0 +1,81 @@
+package remote
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/snyk/driftctl/pkg/alerter"
+	"github.com/snyk/driftctl/pkg/filter"
+	"github.com/snyk/driftctl/pkg/remote/common"
+	remoteerr "github.com/snyk/driftctl/pkg/remote/error"
+	"github.com/snyk/driftctl/pkg/resource"
+	resourceaws "github.com/snyk/driftctl/pkg/resource/aws"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDoctor_Probe(t *testing.T) {
+	alr := alerter.NewAlerter()
+
+	okEnumerator := &common.MockEnumerator{}
+	okEnumerator.On("SupportedType").Return(resource.ResourceType(resourceaws.AwsVpcResourceType))
+	okEnumerator.On("Enumerate").Return([]*resource.Resource{{Id: "vpc-1", Type: resourceaws.AwsVpcResourceType}}, nil).Once()
+
+	deniedEnumerator := &common.MockEnumerator{}
+	deniedEnumerator.On("SupportedType").Return(resource.ResourceType(resourceaws.AwsIamRoleResourceType))
+	deniedEnumerator.On("Enumerate").Return(nil, remoteerr.NewResourceListingError(
+		awserr.NewRequestFailure(awserr.New("AccessDeniedException", "", errors.New("")), 403, ""),
+		resourceaws.AwsIamRoleResourceType,
+	)).Once()
+
+	warnEnumerator := &common.MockEnumerator{}
+	warnEnumerator.On("SupportedType").Return(resource.ResourceType(resourceaws.AwsS3BucketResourceType))
+	warnEnumerator.On("Enumerate").Run(func(args mock.Arguments) {
+		alr.SendAlert("aws_s3_bucket.foobar", &alerter.FakeAlert{Msg: "Unable to read bucket region"})
+	}).Return([]*resource.Resource{}, nil).Once()
+
+	errorEnumerator := &common.MockEnumerator{}
+	errorEnumerator.On("SupportedType").Return(resource.ResourceType(resourceaws.AwsSqsQueueResourceType))
+	errorEnumerator.On("Enumerate").Return(nil, remoteerr.NewResourceListingError(errors.New("connection reset"), resourceaws.AwsSqsQueueResourceType)).Once()
+
+	ignoredEnumerator := &common.MockEnumerator{}
+	ignoredEnumerator.On("SupportedType").Return(resource.ResourceType(resourceaws.AwsSnsTopicResourceType))
+
+	remoteLibrary := common.NewRemoteLibrary()
+	remoteLibrary.AddEnumerator(okEnumerator)
+	remoteLibrary.AddEnumerator(deniedEnumerator)
+	remoteLibrary.AddEnumerator(warnEnumerator)
+	remoteLibrary.AddEnumerator(errorEnumerator)
+	remoteLibrary.AddEnumerator(ignoredEnumerator)
+
+	testFilter := &filter.MockFilter{}
+	testFilter.On("IsTypeIgnored", resource.ResourceType(resourceaws.AwsSnsTopicResourceType)).Return(true)
+	testFilter.On("IsTypeIgnored", mock.Anything).Return(false)
+
+	results, err := NewDoctor(remoteLibrary, alr, testFilter).Probe()
+	assert.Nil(t, err)
+	assert.Len(t, results, 4)
+
+	assert.Equal(t, "aws_iam_role", results[0].Name)
+	assert.Equal(t, CheckFail, results[0].Status)
+	assert.Contains(t, results[0].Message, "Listing aws_iam_role is forbidden")
+
+	assert.Equal(t, CheckWarn, results[1].Status)
+	assert.Equal(t, "aws_s3_bucket", results[1].Name)
+	assert.Equal(t, "Unable to read bucket region", results[1].Message)
+
+	assert.Equal(t, "aws_sqs_queue", results[2].Name)
+	assert.Equal(t, CheckFail, results[2].Status)
+	assert.Equal(t, "error scanning resource type aws_sqs_queue: connection reset", results[2].Message)
+
+	assert.Equal(t, "aws_vpc", results[3].Name)
+	assert.Equal(t, CheckPass, results[3].Status)
+	assert.Equal(t, "", results[3].Message)
+
+	okEnumerator.AssertExpectations(t)
+	deniedEnumerator.AssertExpectations(t)
+	warnEnumerator.AssertExpectations(t)
+	errorEnumerator.AssertExpectations(t)
+	ignoredEnumerator.AssertNotCalled(t, "Enumerate")
+}
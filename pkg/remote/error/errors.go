@@ -63,3 +63,37 @@ func (b *ResourceScanningError) Resource() string {
 func (b *ResourceScanningError) String() string {
 	return fmt.Sprintf("%s.%s (%s)", b.resourceType, b.resourceId, b.listedTypeError)
 }
+
+// CredentialsError is returned when a remote is not able to find any way to authenticate
+type CredentialsError struct {
+	err error
+}
+
+func NewCredentialsError(err error) *CredentialsError {
+	return &CredentialsError{err}
+}
+
+func (c *CredentialsError) Error() string {
+	return c.err.Error()
+}
+
+func (c *CredentialsError) RootCause() error {
+	return c.err
+}
+
+// ProviderInitError is returned when the terraform provider of a remote cannot be installed or configured
+type ProviderInitError struct {
+	err error
+}
+
+func NewProviderInitError(err error) *ProviderInitError {
+	return &ProviderInitError{err}
+}
+
+func (p *ProviderInitError) Error() string {
+	return p.err.Error()
+}
+
+func (p *ProviderInitError) RootCause() error {
+	return p.err
+}
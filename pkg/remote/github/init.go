@@ -5,6 +5,7 @@ import (
 	"github.com/snyk/driftctl/pkg/output"
 	"github.com/snyk/driftctl/pkg/remote/cache"
 	"github.com/snyk/driftctl/pkg/remote/common"
+	remoteerror "github.com/snyk/driftctl/pkg/remote/error"
 	"github.com/snyk/driftctl/pkg/resource"
 	"github.com/snyk/driftctl/pkg/resource/github"
 	"github.com/snyk/driftctl/pkg/terraform"
@@ -21,20 +22,21 @@ func Init(version string, alerter *alerter.Alerter,
 	progress output.Progress,
 	resourceSchemaRepository *resource.SchemaRepository,
 	factory resource.ResourceFactory,
-	configDir string) error {
+	opts common.Options) error {
 
-	provider, err := NewGithubTerraformProvider(version, progress, configDir)
+	provider, err := NewGithubTerraformProvider(version, progress, opts.ProviderInstallOptions, opts.Recorder)
 	if err != nil {
 		return err
 	}
 	err = provider.Init()
 	if err != nil {
-		return err
+		return remoteerror.NewProviderInitError(err)
 	}
+	remoteLibrary.ResolveIdentity(provider)
 
 	repositoryCache := cache.New(100)
 
-	repository := NewGithubRepository(provider.GetConfig(), repositoryCache)
+	repository := NewGithubRepository(provider.GetConfig(), repositoryCache, opts.RetryPolicy, opts.Recorder, opts.PageLimiter)
 	deserializer := resource.NewDeserializer(factory)
 	providerLibrary.AddProvider(terraform.GITHUB, provider)
 
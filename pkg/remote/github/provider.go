@@ -4,14 +4,18 @@ import (
 	"os"
 
 	"github.com/snyk/driftctl/pkg/output"
+	"github.com/snyk/driftctl/pkg/remote/common"
+	"github.com/snyk/driftctl/pkg/remote/recording"
 	"github.com/snyk/driftctl/pkg/remote/terraform"
 	tf "github.com/snyk/driftctl/pkg/terraform"
 )
 
 type GithubTerraformProvider struct {
 	*terraform.TerraformProvider
-	name    string
-	version string
+	name         string
+	version      string
+	owner        string
+	organization string
 }
 
 type githubConfig struct {
@@ -20,18 +24,32 @@ type githubConfig struct {
 	Organization string
 }
 
-func NewGithubTerraformProvider(version string, progress output.Progress, configDir string) (*GithubTerraformProvider, error) {
+func NewGithubTerraformProvider(version string, progress output.Progress, installOptions tf.ProviderInstallOptions, recorder *recording.Recorder) (*GithubTerraformProvider, error) {
 	if version == "" {
-		version = "4.4.0"
+		version = common.RemoteParameter(common.RemoteGithubTerraform).GetDefaultProviderVersion()
+	}
+	version, err := recorder.Value("github_provider_version", version)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := recorder.Value("github_owner", os.Getenv("GITHUB_OWNER"))
+	if err != nil {
+		return nil, err
+	}
+	organization, err := recorder.Value("github_organization", os.Getenv("GITHUB_ORGANIZATION"))
+	if err != nil {
+		return nil, err
 	}
 	p := &GithubTerraformProvider{
-		version: version,
-		name:    "github",
+		version:      version,
+		name:         "github",
+		owner:        owner,
+		organization: organization,
 	}
 	installer, err := tf.NewProviderInstaller(tf.ProviderConfig{
-		Key:       p.name,
-		Version:   version,
-		ConfigDir: configDir,
+		Key:                    p.name,
+		Version:                version,
+		ProviderInstallOptions: installOptions,
 	})
 	if err != nil {
 		return nil, err
@@ -44,6 +62,7 @@ func NewGithubTerraformProvider(version string, progress output.Progress, config
 				Owner: p.GetConfig().getDefaultOwner(),
 			}
 		},
+		Recorder: recorder,
 	}, progress)
 	if err != nil {
 		return nil, err
@@ -62,8 +81,8 @@ func (c githubConfig) getDefaultOwner() string {
 func (p GithubTerraformProvider) GetConfig() githubConfig {
 	return githubConfig{
 		Token:        os.Getenv("GITHUB_TOKEN"),
-		Owner:        os.Getenv("GITHUB_OWNER"),
-		Organization: os.Getenv("GITHUB_ORGANIZATION"),
+		Owner:        p.owner,
+		Organization: p.organization,
 	}
 }
 
@@ -74,3 +93,8 @@ func (p *GithubTerraformProvider) Name() string {
 func (p *GithubTerraformProvider) Version() string {
 	return p.version
 }
+
+// Identity returns the scanned organization or owner
+func (p *GithubTerraformProvider) Identity() (common.Identity, error) {
+	return common.Identity{Account: p.GetConfig().getDefaultOwner()}, nil
+}
@@ -3,9 +3,13 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	"github.com/shurcooL/githubv4"
 	"github.com/snyk/driftctl/pkg/remote/cache"
+	"github.com/snyk/driftctl/pkg/remote/paging"
+	"github.com/snyk/driftctl/pkg/remote/recording"
+	"github.com/snyk/driftctl/pkg/remote/retry"
 	"golang.org/x/oauth2"
 )
 
@@ -28,8 +32,16 @@ type githubRepository struct {
 	cache  cache.Cache
 }
 
-func NewGithubRepository(config githubConfig, c cache.Cache) *githubRepository {
-	ctx := context.Background()
+func NewGithubRepository(config githubConfig, c cache.Cache, retryPolicy *retry.Policy, recorder *recording.Recorder, pageLimiter *paging.Limiter) *githubRepository {
+	transport := recorder.Transport(http.DefaultTransport)
+	if retryPolicy != nil {
+		transport = retryPolicy.Transport(transport)
+	}
+	transport = pageLimiter.Transport(transport)
+	// The oauth2 client sends requests with the client found in its context
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, &http.Client{
+		Transport: transport,
+	})
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: config.Token},
 	)
@@ -100,7 +100,7 @@ func TestScanGithubBranchProtection(t *testing.T) {
 					t.Fatal(err)
 				}
 				provider.ShouldUpdate()
-				repo = github.NewGithubRepository(realProvider.GetConfig(), cache.New(0))
+				repo = github.NewGithubRepository(realProvider.GetConfig(), cache.New(0), nil, nil, nil)
 			}
 
 			remoteLibrary.AddEnumerator(github.NewGithubBranchProtectionEnumerator(repo, factory))
@@ -96,7 +96,7 @@ func TestScanGithubMembership(t *testing.T) {
 					t.Fatal(err)
 				}
 				provider.ShouldUpdate()
-				repo = github.NewGithubRepository(realProvider.GetConfig(), cache.New(0))
+				repo = github.NewGithubRepository(realProvider.GetConfig(), cache.New(0), nil, nil, nil)
 			}
 
 			remoteLibrary.AddEnumerator(github.NewGithubMembershipEnumerator(repo, factory))
@@ -96,7 +96,7 @@ func TestScanGithubRepository(t *testing.T) {
 					t.Fatal(err)
 				}
 				provider.ShouldUpdate()
-				repo = github.NewGithubRepository(realProvider.GetConfig(), cache.New(0))
+				repo = github.NewGithubRepository(realProvider.GetConfig(), cache.New(0), nil, nil, nil)
 			}
 
 			remoteLibrary.AddEnumerator(github.NewGithubRepositoryEnumerator(repo, factory))
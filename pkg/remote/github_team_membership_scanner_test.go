@@ -96,7 +96,7 @@ func TestScanGithubTeamMembership(t *testing.T) {
 					t.Fatal(err)
 				}
 				provider.ShouldUpdate()
-				repo = github.NewGithubRepository(realProvider.GetConfig(), cache.New(0))
+				repo = github.NewGithubRepository(realProvider.GetConfig(), cache.New(0), nil, nil, nil)
 			}
 
 			remoteLibrary.AddEnumerator(github.NewGithubTeamMembershipEnumerator(repo, factory))
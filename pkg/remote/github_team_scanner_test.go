@@ -97,7 +97,7 @@ func TestScanGithubTeam(t *testing.T) {
 					t.Fatal(err)
 				}
 				provider.ShouldUpdate()
-				repo = github.NewGithubRepository(realProvider.GetConfig(), cache.New(0))
+				repo = github.NewGithubRepository(realProvider.GetConfig(), cache.New(0), nil, nil, nil)
 			}
 
 			remoteLibrary.AddEnumerator(github.NewGithubTeamEnumerator(repo, factory))
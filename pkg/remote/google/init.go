@@ -2,6 +2,7 @@ package google
 
 import (
 	"context"
+	"net/http"
 
 	asset "cloud.google.com/go/asset/apiv1"
 	"cloud.google.com/go/storage"
@@ -9,11 +10,15 @@ import (
 	"github.com/snyk/driftctl/pkg/output"
 	"github.com/snyk/driftctl/pkg/remote/cache"
 	"github.com/snyk/driftctl/pkg/remote/common"
+	remoteerror "github.com/snyk/driftctl/pkg/remote/error"
 	"github.com/snyk/driftctl/pkg/remote/google/repository"
 	"github.com/snyk/driftctl/pkg/resource"
 	"github.com/snyk/driftctl/pkg/resource/google"
 	"github.com/snyk/driftctl/pkg/terraform"
 	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+	"google.golang.org/grpc"
 )
 
 func Init(version string, alerter *alerter.Alerter,
@@ -22,37 +27,66 @@ func Init(version string, alerter *alerter.Alerter,
 	progress output.Progress,
 	resourceSchemaRepository *resource.SchemaRepository,
 	factory resource.ResourceFactory,
-	configDir string) error {
+	opts common.Options) error {
 
-	provider, err := NewGCPTerraformProvider(version, progress, configDir)
+	provider, err := NewGCPTerraformProvider(version, progress, opts.ProviderInstallOptions, opts.Recorder)
 	if err != nil {
 		return err
 	}
 
 	err = provider.CheckCredentialsExist()
 	if err != nil {
-		return err
+		return remoteerror.NewCredentialsError(err)
 	}
 
 	err = provider.Init()
 	if err != nil {
-		return err
+		return remoteerror.NewProviderInitError(err)
 	}
 
+	remoteLibrary.ResolveIdentity(provider)
+
 	repositoryCache := cache.New(100)
 
 	ctx := context.Background()
-	assetClient, err := asset.NewClient(ctx)
+	// The page limiter comes first so it only clears the final reply of retried calls
+	assetOptions := []option.ClientOption{option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(
+		opts.PageLimiter.UnaryClientInterceptor(),
+		opts.RetryPolicy.UnaryClientInterceptor(),
+	))}
+	if opts.Recorder != nil {
+		// The recorder comes last so it only sees the final reply of retried calls
+		assetOptions = []option.ClientOption{option.WithGRPCDialOption(grpc.WithChainUnaryInterceptor(
+			opts.PageLimiter.UnaryClientInterceptor(),
+			opts.RetryPolicy.UnaryClientInterceptor(),
+			opts.Recorder.UnaryClientInterceptor(),
+		))}
+	}
+	if opts.Recorder.IsReplaying() {
+		assetOptions = append(assetOptions, option.WithoutAuthentication())
+	}
+	assetClient, err := asset.NewClient(ctx, assetOptions...)
 	if err != nil {
 		return err
 	}
+	// Disable client retries as they are already handled by the interceptor
+	assetClient.CallOptions.ListAssets = nil
+	assetClient.CallOptions.SearchAllResources = nil
 
-	storageClient, err := storage.NewClient(ctx)
+	storageHTTPClient, err := newRetryingHTTPClient(ctx, opts, storage.ScopeFullControl)
+	if err != nil {
+		return err
+	}
+	storageClient, err := storage.NewClient(ctx, option.WithHTTPClient(storageHTTPClient))
 	if err != nil {
 		return err
 	}
 
-	crmService, err := cloudresourcemanager.NewService(ctx)
+	crmHTTPClient, err := newRetryingHTTPClient(ctx, opts, cloudresourcemanager.CloudPlatformScope)
+	if err != nil {
+		return err
+	}
+	crmService, err := cloudresourcemanager.NewService(ctx, option.WithHTTPClient(crmHTTPClient))
 	if err != nil {
 		return err
 	}
@@ -117,3 +151,17 @@ func Init(version string, alerter *alerter.Alerter,
 
 	return nil
 }
+
+// newRetryingHTTPClient returns an authenticated HTTP client following the scan retry policy.
+// Requests are not authenticated when replaying a recorded scan.
+func newRetryingHTTPClient(ctx context.Context, opts common.Options, scopes ...string) (*http.Client, error) {
+	base := opts.PageLimiter.Transport(opts.RetryPolicy.Transport(opts.Recorder.Transport(http.DefaultTransport)))
+	if opts.Recorder.IsReplaying() {
+		return &http.Client{Transport: base}, nil
+	}
+	transport, err := htransport.NewTransport(ctx, base, option.WithScopes(scopes...))
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: transport}, nil
+}
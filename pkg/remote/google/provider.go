@@ -2,34 +2,50 @@ package google
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"os"
 
 	asset "cloud.google.com/go/asset/apiv1"
 	"github.com/snyk/driftctl/pkg/output"
+	"github.com/snyk/driftctl/pkg/remote/common"
 	"github.com/snyk/driftctl/pkg/remote/google/config"
+	"github.com/snyk/driftctl/pkg/remote/recording"
 	"github.com/snyk/driftctl/pkg/remote/terraform"
 	tf "github.com/snyk/driftctl/pkg/terraform"
+	"golang.org/x/oauth2/google"
 )
 
 type GCPTerraformProvider struct {
 	*terraform.TerraformProvider
-	name    string
-	version string
+	name     string
+	version  string
+	project  string
+	recorder *recording.Recorder
 }
 
-func NewGCPTerraformProvider(version string, progress output.Progress, configDir string) (*GCPTerraformProvider, error) {
+func NewGCPTerraformProvider(version string, progress output.Progress, installOptions tf.ProviderInstallOptions, recorder *recording.Recorder) (*GCPTerraformProvider, error) {
 	if version == "" {
-		version = "3.78.0"
+		version = common.RemoteParameter(common.RemoteGoogleTerraform).GetDefaultProviderVersion()
+	}
+	version, err := recorder.Value("google_provider_version", version)
+	if err != nil {
+		return nil, err
+	}
+	project, err := recorder.Value("google_project", os.Getenv("CLOUDSDK_CORE_PROJECT"))
+	if err != nil {
+		return nil, err
 	}
 	p := &GCPTerraformProvider{
-		version: version,
-		name:    tf.GOOGLE,
+		version:  version,
+		name:     tf.GOOGLE,
+		project:  project,
+		recorder: recorder,
 	}
 	installer, err := tf.NewProviderInstaller(tf.ProviderConfig{
-		Key:       p.name,
-		Version:   version,
-		ConfigDir: configDir,
+		Key:                    p.name,
+		Version:                version,
+		ProviderInstallOptions: installOptions,
 	})
 	if err != nil {
 		return nil, err
@@ -39,6 +55,7 @@ func NewGCPTerraformProvider(version string, progress output.Progress, configDir
 		GetProviderConfig: func(alias string) interface{} {
 			return p.GetConfig()
 		},
+		Recorder: recorder,
 	}, progress)
 
 	if err != nil {
@@ -60,13 +77,16 @@ func (p *GCPTerraformProvider) Version() string {
 
 func (p *GCPTerraformProvider) GetConfig() config.GCPTerraformConfig {
 	return config.GCPTerraformConfig{
-		Project: os.Getenv("CLOUDSDK_CORE_PROJECT"),
+		Project: p.project,
 		Region:  os.Getenv("CLOUDSDK_COMPUTE_REGION"),
 		Zone:    os.Getenv("CLOUDSDK_COMPUTE_ZONE"),
 	}
 }
 
 func (p *GCPTerraformProvider) CheckCredentialsExist() error {
+	if p.recorder.IsReplaying() {
+		return nil
+	}
 	client, err := asset.NewClient(context.Background())
 	if err != nil {
 		return errors.New("Please use a Service Account to authenticate on GCP.\n" +
@@ -75,3 +95,27 @@ func (p *GCPTerraformProvider) CheckCredentialsExist() error {
 	_ = client.Close()
 	return nil
 }
+
+// Identity returns the scanned project and region, and the service account of the default credentials
+func (p *GCPTerraformProvider) Identity() (common.Identity, error) {
+	config := p.GetConfig()
+	identity := common.Identity{Account: config.Project, Region: config.Region}
+	if p.recorder.IsReplaying() {
+		return identity, nil
+	}
+	credentials, err := google.FindDefaultCredentials(context.Background())
+	if err != nil {
+		return identity, err
+	}
+	// Credentials coming from the metadata server have no JSON content
+	if len(credentials.JSON) > 0 {
+		var account struct {
+			ClientEmail string `json:"client_email"`
+		}
+		if err := json.Unmarshal(credentials.JSON, &account); err != nil {
+			return identity, err
+		}
+		identity.Caller = account.ClientEmail
+	}
+	return identity, nil
+}
@@ -0,0 +1,154 @@
+package paging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awsutil"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// Fields of JSON responses pointing to the next page, as returned by Azure, Google and GitHub APIs
+var nextPageFields = []string{"nextLink", "@odata.nextLink", "nextPageToken", "$skipToken", "skipToken"}
+
+// Limiter stops the pagination of cloud provider APIs after the first page, so listing resources only checks
+// permissions instead of reading whole accounts.
+// A nil limiter is valid and does nothing, so it can be passed around when pages are not limited.
+type Limiter struct{}
+
+// NewFirstPageLimiter returns a limiter answering every paginated call with its first page only
+func NewFirstPageLimiter() *Limiter {
+	return &Limiter{}
+}
+
+// AWSHandlers makes paginated AWS calls look like they returned their last page
+func (l *Limiter) AWSHandlers(handlers *request.Handlers) {
+	if l == nil {
+		return
+	}
+	handlers.Unmarshal.PushBack(func(r *request.Request) {
+		if r.Error != nil || r.Operation == nil || r.Operation.Paginator == nil {
+			return
+		}
+		paginator := r.Operation.Paginator
+		if paginator.TruncationToken != "" {
+			awsutil.SetValueAtPath(r.Data, paginator.TruncationToken, aws.Bool(false))
+		}
+		for _, token := range paginator.OutputTokens {
+			// Tokens can be expressions falling back on the last listed item, e.g. NextMarker || Contents[-1].Key,
+			// only their fields are cleared so listed items are kept
+			for _, field := range strings.Split(token, "||") {
+				field = strings.TrimSpace(field)
+				if strings.Contains(field, "[") {
+					continue
+				}
+				awsutil.SetValueAtPath(r.Data, field, nil)
+			}
+		}
+		// Without paginator, the SDK doesn't compute next page tokens from listed items either
+		operation := *r.Operation
+		operation.Paginator = nil
+		r.Operation = &operation
+	})
+}
+
+// UnaryClientInterceptor clears the next page token of gRPC replies
+func (l *Limiter) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if l == nil || err != nil {
+			return err
+		}
+		if msg, ok := reply.(proto.Message); ok {
+			m := msg.ProtoReflect()
+			if field := m.Descriptor().Fields().ByName("next_page_token"); field != nil {
+				m.Clear(field)
+			}
+		}
+		return nil
+	}
+}
+
+// Transport wraps an HTTP round tripper to remove next page links from JSON responses
+func (l *Limiter) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if l == nil {
+		return base
+	}
+	return &transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		return resp, err
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	body = removeNextPage(body)
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	resp.Header.Set("Content-Length", strconv.Itoa(len(body)))
+	return resp, nil
+}
+
+// removeNextPage removes next page fields of a JSON object, and marks GraphQL connections as complete
+func removeNextPage(body []byte) []byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return body
+	}
+
+	var content map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+	if err := decoder.Decode(&content); err != nil {
+		return body
+	}
+	for _, field := range nextPageFields {
+		delete(content, field)
+	}
+	completeGraphQLConnections(content)
+
+	limited, err := json.Marshal(content)
+	if err != nil {
+		return body
+	}
+	return limited
+}
+
+func completeGraphQLConnections(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if pageInfo, ok := v["pageInfo"].(map[string]interface{}); ok {
+			if _, exist := pageInfo["hasNextPage"]; exist {
+				pageInfo["hasNextPage"] = false
+			}
+		}
+		for _, field := range v {
+			completeGraphQLConnections(field)
+		}
+	case []interface{}:
+		for _, elem := range v {
+			completeGraphQLConnections(elem)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package paging
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	assetpb "google.golang.org/genproto/googleapis/cloud/asset/v1"
+	"google.golang.org/grpc"
+)
+
+func TestLimiter_AWSHandlers(t *testing.T) {
+	cases := []struct {
+		name      string
+		paginator *request.Paginator
+		data      interface{}
+		expected  interface{}
+	}{
+		{
+			name:      "clear next token",
+			paginator: &request.Paginator{InputTokens: []string{"NextToken"}, OutputTokens: []string{"NextToken"}},
+			data:      &ec2.DescribeVpcsOutput{NextToken: aws.String("next")},
+			expected:  &ec2.DescribeVpcsOutput{},
+		},
+		{
+			name: "mark truncated output as complete",
+			paginator: &request.Paginator{
+				InputTokens:     []string{"Marker"},
+				OutputTokens:    []string{"Marker"},
+				TruncationToken: "IsTruncated",
+			},
+			data:     &iam.ListRolesOutput{IsTruncated: aws.Bool(true), Marker: aws.String("next")},
+			expected: &iam.ListRolesOutput{IsTruncated: aws.Bool(false)},
+		},
+		{
+			name: "clear next token of expressions and keep listed items",
+			paginator: &request.Paginator{
+				InputTokens:     []string{"Marker"},
+				OutputTokens:    []string{"NextMarker || Contents[-1].Key"},
+				TruncationToken: "IsTruncated",
+			},
+			data: &s3.ListObjectsOutput{
+				IsTruncated: aws.Bool(true),
+				NextMarker:  aws.String("next"),
+				Contents:    []*s3.Object{{Key: aws.String("key")}},
+			},
+			expected: &s3.ListObjectsOutput{
+				IsTruncated: aws.Bool(false),
+				Contents:    []*s3.Object{{Key: aws.String("key")}},
+			},
+		},
+		{
+			name:      "stop expressions reading listed items",
+			paginator: &request.Paginator{InputTokens: []string{"Marker"}, OutputTokens: []string{"NextMarker || Contents[-1].Key"}},
+			data:      &s3.ListObjectsOutput{Contents: []*s3.Object{{Key: aws.String("key")}}},
+			expected:  &s3.ListObjectsOutput{Contents: []*s3.Object{{Key: aws.String("key")}}},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			handlers := request.Handlers{}
+			NewFirstPageLimiter().AWSHandlers(&handlers)
+
+			r := &request.Request{
+				Operation: &request.Operation{Name: "List", Paginator: c.paginator},
+				Data:      c.data,
+			}
+			handlers.Unmarshal.Run(r)
+			assert.Equal(t, c.expected, r.Data)
+			assert.False(t, r.HasNextPage())
+			assert.Equal(t, "List", r.Operation.Name)
+		})
+	}
+
+	var limiter *Limiter
+	handlers := request.Handlers{}
+	limiter.AWSHandlers(&handlers)
+	assert.Equal(t, 0, handlers.Unmarshal.Len())
+}
+
+func TestLimiter_AWSHandlers_ListObjectsPages(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		_, _ = w.Write([]byte(`<ListBucketResult><IsTruncated>true</IsTruncated><Contents><Key>key</Key></Contents></ListBucketResult>`))
+	}))
+	defer server.Close()
+
+	sess := session.Must(session.NewSession(&aws.Config{
+		Endpoint:         aws.String(server.URL),
+		Region:           aws.String("us-east-1"),
+		Credentials:      credentials.NewStaticCredentials("id", "secret", ""),
+		S3ForcePathStyle: aws.Bool(true),
+	}))
+	NewFirstPageLimiter().AWSHandlers(&sess.Handlers)
+
+	keys := 0
+	err := s3.New(sess).ListObjectsPages(&s3.ListObjectsInput{Bucket: aws.String("bucket")}, func(output *s3.ListObjectsOutput, lastPage bool) bool {
+		keys += len(output.Contents)
+		return calls < 3
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 1, keys)
+}
+
+func TestLimiter_UnaryClientInterceptor(t *testing.T) {
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		reply.(*assetpb.SearchAllResourcesResponse).NextPageToken = "next"
+		return nil
+	}
+
+	reply := &assetpb.SearchAllResourcesResponse{}
+	err := NewFirstPageLimiter().UnaryClientInterceptor()(context.Background(), "Search", nil, reply, nil, invoker)
+	assert.NoError(t, err)
+	assert.Empty(t, reply.NextPageToken)
+
+	var limiter *Limiter
+	reply = &assetpb.SearchAllResourcesResponse{}
+	err = limiter.UnaryClientInterceptor()(context.Background(), "Search", nil, reply, nil, invoker)
+	assert.NoError(t, err)
+	assert.Equal(t, "next", reply.NextPageToken)
+}
+
+func TestLimiter_Transport(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		body     string
+		expected string
+	}{
+		{
+			name:     "remove next page fields",
+			status:   http.StatusOK,
+			body:     `{"value":[{"id":"foo"}],"nextLink":"https://management.azure.com/next","$skipToken":"skip"}`,
+			expected: `{"value":[{"id":"foo"}]}`,
+		},
+		{
+			name:     "complete GraphQL connections",
+			status:   http.StatusOK,
+			body:     `{"data":{"viewer":{"repositories":{"nodes":[],"pageInfo":{"endCursor":"abc","hasNextPage":true}}}}}`,
+			expected: `{"data":{"viewer":{"repositories":{"nodes":[],"pageInfo":{"endCursor":"abc","hasNextPage":false}}}}}`,
+		},
+		{
+			name:     "keep responses that are not JSON objects",
+			status:   http.StatusOK,
+			body:     `[{"nextLink":"https://management.azure.com/next"}]`,
+			expected: `[{"nextLink":"https://management.azure.com/next"}]`,
+		},
+		{
+			name:     "keep errors",
+			status:   http.StatusForbidden,
+			body:     `{"error":"forbidden","nextLink":"https://management.azure.com/next"}`,
+			expected: `{"error":"forbidden","nextLink":"https://management.azure.com/next"}`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(c.status)
+				_, _ = w.Write([]byte(c.body))
+			}))
+			defer server.Close()
+
+			client := &http.Client{Transport: NewFirstPageLimiter().Transport(nil)}
+			resp, err := client.Get(server.URL)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer resp.Body.Close()
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.NoError(t, err)
+			assert.JSONEq(t, c.expected, string(body))
+			assert.Equal(t, int64(len(body)), resp.ContentLength)
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package recording
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+type grpcInteraction struct {
+	Method  string     `json:"method"`
+	Code    codes.Code `json:"code"`
+	Message string     `json:"message,omitempty"`
+	Reply   string     `json:"reply,omitempty"`
+}
+
+// UnaryClientInterceptor records gRPC replies, or answers calls with recorded replies.
+// Calls are identified by their method and request message.
+func (r *Recorder) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		reqMsg, reqOk := req.(proto.Message)
+		replyMsg, replyOk := reply.(proto.Message)
+		if !reqOk || !replyOk {
+			return errors.Errorf("unable to record gRPC call %s, messages are not protobuf messages", method)
+		}
+
+		content, err := proto.MarshalOptions{Deterministic: true}.Marshal(reqMsg)
+		if err != nil {
+			return err
+		}
+		name := filepath.Join("grpc", hash([]byte(method), content)+".json")
+
+		if r.IsReplaying() {
+			return r.replayGRPC(method, name, replyMsg)
+		}
+
+		callErr := invoker(ctx, method, req, reply, cc, opts...)
+
+		interaction := grpcInteraction{Method: method}
+		if callErr != nil {
+			s := status.Convert(callErr)
+			interaction.Code = s.Code()
+			interaction.Message = s.Message()
+		} else {
+			recorded, err := protojson.Marshal(replyMsg)
+			if err != nil {
+				return err
+			}
+			interaction.Reply = string(redactBody(recorded))
+		}
+		if err := r.writeJSON(name, interaction); err != nil {
+			logrus.WithFields(logrus.Fields{
+				"method": method,
+				"error":  err,
+			}).Warn("Unable to record gRPC reply")
+		}
+
+		return callErr
+	}
+}
+
+func (r *Recorder) replayGRPC(method, name string, reply proto.Message) error {
+	var interaction grpcInteraction
+	if err := r.readJSON(name, &interaction); err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return status.Errorf(codes.NotFound, "no reply recorded for %s", method)
+		}
+		return err
+	}
+	if interaction.Code != codes.OK {
+		return status.Error(interaction.Code, interaction.Message)
+	}
+	return protojson.Unmarshal([]byte(interaction.Reply), reply)
+}
@@ -0,0 +1,159 @@
+package recording
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type httpInteraction struct {
+	Method      string `json:"method"`
+	URL         string `json:"url"`
+	StatusCode  int    `json:"status_code"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        string `json:"body,omitempty"`
+	// BodyBase64 is used instead of Body for responses that are not valid UTF-8
+	BodyBase64 string `json:"body_base64,omitempty"`
+}
+
+// Transport wraps an HTTP round tripper to record responses, or to answer requests with recorded responses.
+// Requests are identified by their method, URL and body. Headers are never recorded.
+func (r *Recorder) Transport(base http.RoundTripper) http.RoundTripper {
+	if r == nil {
+		return base
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{recorder: r, base: base}
+}
+
+type transport struct {
+	recorder *Recorder
+	base     http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	url := redactURL(req.URL)
+	name := filepath.Join("http", hash([]byte(req.Method), []byte(url), body)+".json")
+
+	if t.recorder.IsReplaying() {
+		return t.replay(req, url, name)
+	}
+
+	current := req.Clone(req.Context())
+	if body != nil {
+		current.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	resp, err := t.base.RoundTrip(current)
+	if err != nil {
+		return resp, err
+	}
+
+	respBody, err := readBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	interaction := httpInteraction{
+		Method:      req.Method,
+		URL:         url,
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+	}
+	if recorded := redactBody(respBody); utf8.Valid(recorded) {
+		interaction.Body = string(recorded)
+	} else {
+		interaction.BodyBase64 = base64.StdEncoding.EncodeToString(recorded)
+	}
+	if err := t.recorder.writeJSON(name, interaction); err != nil {
+		logrus.WithFields(logrus.Fields{
+			"url":   url,
+			"error": err,
+		}).Warn("Unable to record HTTP response")
+	}
+
+	return resp, nil
+}
+
+func (t *transport) replay(req *http.Request, url, name string) (*http.Response, error) {
+	var interaction httpInteraction
+	if err := t.recorder.readJSON(name, &interaction); err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return nil, errors.Errorf("no response recorded for %s %s", req.Method, url)
+		}
+		return nil, err
+	}
+
+	body := []byte(interaction.Body)
+	if interaction.BodyBase64 != "" {
+		var err error
+		body, err = base64.StdEncoding.DecodeString(interaction.BodyBase64)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	header := make(http.Header)
+	if interaction.ContentType != "" {
+		header.Set("Content-Type", interaction.ContentType)
+	}
+	return &http.Response{
+		Status:        http.StatusText(interaction.StatusCode),
+		StatusCode:    interaction.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// readBody reads a response body, decompressing it when the client asked for a compressed response itself,
+// so we can look for secrets in it
+func readBody(resp *http.Response) ([]byte, error) {
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return body, nil
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	body, err = ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = int64(len(body))
+	return body, nil
+}
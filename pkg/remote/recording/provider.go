@@ -0,0 +1,110 @@
+package recording
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/hashicorp/terraform/providers"
+	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/pkg/terraform"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+type readResourceResult struct {
+	Type       string            `json:"type"`
+	Id         string            `json:"id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+	Typ        json.RawMessage   `json:"value_type,omitempty"`
+	Val        json.RawMessage   `json:"value,omitempty"`
+	Err        *string           `json:"error,omitempty"`
+}
+
+func schemaFilename(provider string) string {
+	return filepath.Join("providers", provider, "schema.json")
+}
+
+func resourceFilename(provider string, args terraform.ReadResourceArgs) string {
+	parts := [][]byte{[]byte(args.Ty), []byte(args.ID)}
+	keys := make([]string, 0, len(args.Attributes))
+	for k := range args.Attributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		parts = append(parts, []byte(fmt.Sprintf("%s=%s", k, args.Attributes[k])))
+	}
+	return filepath.Join("providers", provider, "resources", fmt.Sprintf("%s-%s.json", args.Ty, hash(parts...)))
+}
+
+// WriteSchema saves the resource schemas of a terraform provider
+func (r *Recorder) WriteSchema(provider string, schema map[string]providers.Schema) error {
+	return r.writeJSON(schemaFilename(provider), schema)
+}
+
+// ReadSchema returns the resource schemas saved for a terraform provider
+func (r *Recorder) ReadSchema(provider string) (map[string]providers.Schema, error) {
+	var schema map[string]providers.Schema
+	if err := r.readJSON(schemaFilename(provider), &schema); err != nil {
+		return nil, errors.Wrapf(err, "unable to read recorded schema of provider %s", provider)
+	}
+	return schema, nil
+}
+
+// WriteResource saves the result of a ReadResource call, attributes marked as sensitive in the schema are redacted
+func (r *Recorder) WriteResource(provider string, schema providers.Schema, args terraform.ReadResourceArgs, value *cty.Value, readErr error) error {
+	result := readResourceResult{
+		Type:       string(args.Ty),
+		Id:         args.ID,
+		Attributes: args.Attributes,
+	}
+	if value != nil {
+		redacted := redactValue(schema.Block, *value)
+		typ, err := ctyjson.MarshalType(redacted.Type())
+		if err != nil {
+			return err
+		}
+		val, err := ctyjson.Marshal(redacted, redacted.Type())
+		if err != nil {
+			return err
+		}
+		result.Typ = typ
+		result.Val = val
+	}
+	if readErr != nil {
+		e := readErr.Error()
+		result.Err = &e
+	}
+	return r.writeJSON(resourceFilename(provider, args), result)
+}
+
+// ReadResource returns the result of a ReadResource call saved for the given arguments
+func (r *Recorder) ReadResource(provider string, args terraform.ReadResourceArgs) (*cty.Value, error) {
+	var result readResourceResult
+	if err := r.readJSON(resourceFilename(provider, args), &result); err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return nil, errors.Errorf("no result recorded for resource %s.%s", args.Ty, args.ID)
+		}
+		return nil, err
+	}
+
+	if result.Err != nil {
+		return nil, errors.New(*result.Err)
+	}
+	if result.Typ == nil {
+		return nil, nil
+	}
+
+	typ, err := ctyjson.UnmarshalType(result.Typ)
+	if err != nil {
+		return nil, err
+	}
+	val, err := ctyjson.Unmarshal(result.Val, typ)
+	if err != nil {
+		return nil, err
+	}
+	return &val, nil
+}
@@ -0,0 +1,123 @@
+package recording
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+const metadataFilename = "metadata.json"
+
+type Mode uint8
+
+const (
+	// ModeRecord sends requests to cloud providers and saves every response
+	ModeRecord Mode = iota + 1
+	// ModeReplay never reaches cloud providers and answers requests with saved responses
+	ModeReplay
+)
+
+// Recorder saves cloud provider responses of a scan to a directory, or replays them from it.
+// A nil recorder is valid and does nothing, so it can be passed around when recording is disabled.
+type Recorder struct {
+	mode     Mode
+	dir      string
+	lock     sync.Mutex
+	metadata map[string]string
+}
+
+// NewRecorder returns a recorder saving responses to the given directory
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.Wrapf(err, "unable to create record directory %s", dir)
+	}
+	r := &Recorder{
+		mode:     ModeRecord,
+		dir:      dir,
+		metadata: make(map[string]string),
+	}
+	if err := r.writeJSON(metadataFilename, r.metadata); err != nil {
+		return nil, errors.Wrapf(err, "unable to write to record directory %s", dir)
+	}
+	return r, nil
+}
+
+// NewReplayer returns a recorder replaying responses previously saved to the given directory
+func NewReplayer(dir string) (*Recorder, error) {
+	r := &Recorder{
+		mode:     ModeReplay,
+		dir:      dir,
+		metadata: make(map[string]string),
+	}
+	if err := r.readJSON(metadataFilename, &r.metadata); err != nil {
+		return nil, errors.Wrapf(err, "unable to read recording from %s", dir)
+	}
+	return r, nil
+}
+
+func (r *Recorder) IsRecording() bool {
+	return r != nil && r.mode == ModeRecord
+}
+
+func (r *Recorder) IsReplaying() bool {
+	return r != nil && r.mode == ModeReplay
+}
+
+// Value saves a value needed to replay the scan, like the region or the project that was scanned.
+// When replaying, the saved value is returned instead of the given one.
+// Values must never contain secrets as they are written as is.
+func (r *Recorder) Value(key, value string) (string, error) {
+	if r == nil {
+		return value, nil
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.mode == ModeReplay {
+		saved, exist := r.metadata[key]
+		if !exist {
+			return "", errors.Errorf("no value recorded for %s in %s", key, r.dir)
+		}
+		return saved, nil
+	}
+
+	r.metadata[key] = value
+	return value, r.writeJSON(metadataFilename, r.metadata)
+}
+
+func (r *Recorder) writeJSON(name string, v interface{}) error {
+	content, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(r.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, content, 0600)
+}
+
+func (r *Recorder) readJSON(name string, v interface{}) error {
+	content, err := ioutil.ReadFile(filepath.Join(r.dir, name))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
+
+// hash returns a stable file name for the given request parts
+func hash(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
@@ -0,0 +1,225 @@
+package recording
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform/configs/configschema"
+	"github.com/hashicorp/terraform/providers"
+	"github.com/pkg/errors"
+	"github.com/snyk/driftctl/pkg/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestRecorder_Value(t *testing.T) {
+	dir := t.TempDir()
+
+	var nilRecorder *Recorder
+	value, err := nilRecorder.Value("region", "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", value)
+
+	recorder, err := NewRecorder(dir)
+	require.NoError(t, err)
+	value, err = recorder.Value("region", "eu-west-3")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-3", value)
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+	value, err = replayer.Value("region", "us-east-1")
+	require.NoError(t, err)
+	assert.Equal(t, "eu-west-3", value)
+
+	_, err = replayer.Value("project", "")
+	assert.EqualError(t, err, "no value recorded for project in "+dir)
+
+	_, err = NewReplayer(filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
+
+func TestRecorder_Transport(t *testing.T) {
+	dir := t.TempDir()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Set-Cookie", "session=secret")
+		if string(body) == "page=2" {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"message":"access denied"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"NextToken":"page-2","Credentials":{"SecretAccessKey":"foo","SessionToken":"bar"},"items":[{"password":"baz","name":"qux"}]}`))
+	}))
+
+	recorder, err := NewRecorder(dir)
+	require.NoError(t, err)
+	client := &http.Client{Transport: recorder.Transport(nil)}
+
+	resp, err := client.Post(server.URL+"/list?X-Amz-Signature=abc&page=1", "text/plain", strings.NewReader("page=1"))
+	require.NoError(t, err)
+	body, _ := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	// Callers always get the real response
+	assert.Contains(t, string(body), `"SecretAccessKey":"foo"`)
+
+	resp, err = client.Post(server.URL+"/list", "text/plain", strings.NewReader("page=2"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	server.Close()
+
+	files, err := filepath.Glob(filepath.Join(dir, "http", "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		require.NoError(t, err)
+		assert.NotContains(t, string(content), "foo")
+		assert.NotContains(t, string(content), "baz")
+		assert.NotContains(t, string(content), "Signature")
+		assert.NotContains(t, string(content), "session=secret")
+	}
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+	client = &http.Client{Transport: replayer.Transport(nil)}
+
+	resp, err = client.Post(server.URL+"/list?page=1&X-Amz-Signature=def", "text/plain", strings.NewReader("page=1"))
+	require.NoError(t, err)
+	body, _ = ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.JSONEq(t, `{"NextToken":"page-2","Credentials":{"SecretAccessKey":"REDACTED","SessionToken":"REDACTED"},"items":[{"password":"REDACTED","name":"qux"}]}`, string(body))
+
+	resp, err = client.Post(server.URL+"/list", "text/plain", strings.NewReader("page=2"))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+
+	_, err = client.Get(server.URL + "/unknown")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no response recorded for GET "+server.URL+"/unknown")
+}
+
+func TestRedactBody_XML(t *testing.T) {
+	body := redactBody([]byte(`<AssumeRoleResponse><Credentials><AccessKeyId>ASIA</AccessKeyId><SecretAccessKey>foo</SecretAccessKey><SessionToken>bar</SessionToken></Credentials><NextToken>next</NextToken></AssumeRoleResponse>`))
+	assert.Equal(t, `<AssumeRoleResponse><Credentials><AccessKeyId>ASIA</AccessKeyId><SecretAccessKey>REDACTED</SecretAccessKey><SessionToken>REDACTED</SessionToken></Credentials><NextToken>next</NextToken></AssumeRoleResponse>`, string(body))
+}
+
+func TestRecorder_UnaryClientInterceptor(t *testing.T) {
+	dir := t.TempDir()
+
+	recorder, err := NewRecorder(dir)
+	require.NoError(t, err)
+	interceptor := recorder.UnaryClientInterceptor()
+
+	invoker := func(_ context.Context, _ string, req, reply interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		if req.(*wrapperspb.StringValue).GetValue() == "denied" {
+			return status.Error(codes.PermissionDenied, "denied")
+		}
+		reply.(*wrapperspb.StringValue).Value = "hello " + req.(*wrapperspb.StringValue).GetValue()
+		return nil
+	}
+
+	reply := &wrapperspb.StringValue{}
+	require.NoError(t, interceptor(context.Background(), "/test/Hello", wrapperspb.String("world"), reply, nil, invoker))
+	assert.Equal(t, "hello world", reply.GetValue())
+	err = interceptor(context.Background(), "/test/Hello", wrapperspb.String("denied"), &wrapperspb.StringValue{}, nil, invoker)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+	interceptor = replayer.UnaryClientInterceptor()
+	failingInvoker := func(_ context.Context, _ string, _, _ interface{}, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		return errors.New("should not be called")
+	}
+
+	reply = &wrapperspb.StringValue{}
+	require.NoError(t, interceptor(context.Background(), "/test/Hello", wrapperspb.String("world"), reply, nil, failingInvoker))
+	assert.Equal(t, "hello world", reply.GetValue())
+	err = interceptor(context.Background(), "/test/Hello", wrapperspb.String("denied"), &wrapperspb.StringValue{}, nil, failingInvoker)
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	err = interceptor(context.Background(), "/test/Hello", wrapperspb.String("unknown"), &wrapperspb.StringValue{}, nil, failingInvoker)
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestRecorder_Resource(t *testing.T) {
+	dir := t.TempDir()
+	schema := providers.Schema{
+		Block: &configschema.Block{
+			Attributes: map[string]*configschema.Attribute{
+				"id":       {Type: cty.String, Computed: true},
+				"password": {Type: cty.String, Optional: true, Sensitive: true},
+			},
+			BlockTypes: map[string]*configschema.NestedBlock{
+				"user": {
+					Nesting: configschema.NestingList,
+					Block: configschema.Block{
+						Attributes: map[string]*configschema.Attribute{
+							"name":  {Type: cty.String, Optional: true},
+							"token": {Type: cty.Number, Optional: true, Sensitive: true},
+						},
+					},
+				},
+			},
+		},
+	}
+	args := terraform.ReadResourceArgs{Ty: "aws_db_instance", ID: "db", Attributes: map[string]string{"alias": "eu-west-3"}}
+	value := cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.StringVal("db"),
+		"password": cty.StringVal("secret"),
+		"user": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"name":  cty.StringVal("admin"),
+				"token": cty.NumberIntVal(42),
+			}),
+		}),
+	})
+
+	recorder, err := NewRecorder(dir)
+	require.NoError(t, err)
+	require.NoError(t, recorder.WriteSchema("aws", map[string]providers.Schema{"aws_db_instance": schema}))
+	require.NoError(t, recorder.WriteResource("aws", schema, args, &value, nil))
+	missing := terraform.ReadResourceArgs{Ty: "aws_db_instance", ID: "deleted"}
+	require.NoError(t, recorder.WriteResource("aws", schema, missing, nil, errors.New("not found")))
+
+	replayer, err := NewReplayer(dir)
+	require.NoError(t, err)
+
+	schemas, err := replayer.ReadSchema("aws")
+	require.NoError(t, err)
+	assert.True(t, schemas["aws_db_instance"].Block.Attributes["password"].Sensitive)
+
+	got, err := replayer.ReadResource("aws", args)
+	require.NoError(t, err)
+	assert.True(t, cty.ObjectVal(map[string]cty.Value{
+		"id":       cty.StringVal("db"),
+		"password": cty.StringVal(Redacted),
+		"user": cty.ListVal([]cty.Value{
+			cty.ObjectVal(map[string]cty.Value{
+				"name":  cty.StringVal("admin"),
+				"token": cty.NullVal(cty.Number),
+			}),
+		}),
+	}).RawEquals(*got))
+
+	_, err = replayer.ReadResource("aws", missing)
+	assert.EqualError(t, err, "not found")
+
+	_, err = replayer.ReadResource("aws", terraform.ReadResourceArgs{Ty: "aws_db_instance", ID: "db"})
+	assert.EqualError(t, err, "no result recorded for resource aws_db_instance.db")
+}
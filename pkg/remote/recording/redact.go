@@ -0,0 +1,194 @@
+package recording
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/terraform/configs/configschema"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Redacted replaces every secret found in recorded responses
+const Redacted = "REDACTED"
+
+// Query parameters used to authenticate requests, they are removed from recorded URLs
+var redactedQueryParams = map[string]struct{}{
+	"x-amz-signature":      {},
+	"x-amz-credential":     {},
+	"x-amz-security-token": {},
+	"access_token":         {},
+	"client_secret":        {},
+	"code":                 {},
+	"key":                  {},
+	"password":             {},
+	"sig":                  {},
+}
+
+// Fields holding secrets in JSON responses, matched against lower cased keys without separators
+var redactedFields = []string{
+	"secret",
+	"password",
+	"privatekey",
+	"sessiontoken",
+	"accesstoken",
+	"refreshtoken",
+	"idtoken",
+	"connectionstring",
+}
+
+var redactedXMLElements = regexp.MustCompile(`(?i)(<[a-z]*(?:secret|password|privatekey|sessiontoken|accesstoken)[a-z]*>)[^<]+`)
+
+// redactURL removes credentials from a request URL, and sorts its query so it can be used as a key
+func redactURL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	query := redacted.Query()
+	for param := range query {
+		if _, ok := redactedQueryParams[strings.ToLower(param)]; ok {
+			query.Del(param)
+		}
+	}
+	redacted.RawQuery = query.Encode()
+	return redacted.String()
+}
+
+func isRedactedField(name string) bool {
+	name = strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(name))
+	if name == "primarykey" || name == "secondarykey" {
+		return true
+	}
+	for _, field := range redactedFields {
+		if strings.Contains(name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// redactBody replaces secrets found in a JSON or XML response body
+func redactBody(body []byte) []byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return body
+	}
+
+	if trimmed[0] == '{' || trimmed[0] == '[' {
+		var content interface{}
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.UseNumber()
+		if err := decoder.Decode(&content); err == nil {
+			if redacted, err := json.Marshal(redactJSON(content)); err == nil {
+				return redacted
+			}
+		}
+		return body
+	}
+
+	if trimmed[0] == '<' {
+		return redactedXMLElements.ReplaceAll(body, []byte("${1}"+Redacted))
+	}
+
+	return body
+}
+
+func redactJSON(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, isString := field.(string); isString && isRedactedField(key) {
+				v[key] = Redacted
+				continue
+			}
+			v[key] = redactJSON(field)
+		}
+	case []interface{}:
+		for i, elem := range v {
+			v[i] = redactJSON(elem)
+		}
+	}
+	return value
+}
+
+// redactValue replaces attributes marked as sensitive in the provider schema
+func redactValue(block *configschema.Block, val cty.Value) cty.Value {
+	if block == nil || val.IsNull() || !val.IsKnown() || !val.Type().IsObjectType() {
+		return val
+	}
+
+	attrs := val.AsValueMap()
+	for name, attr := range block.Attributes {
+		v, exist := attrs[name]
+		if !exist || !attr.Sensitive || v.IsNull() || !v.IsKnown() {
+			continue
+		}
+		if v.Type() == cty.String {
+			attrs[name] = cty.StringVal(Redacted)
+			continue
+		}
+		attrs[name] = cty.NullVal(v.Type())
+	}
+
+	for name, nested := range block.BlockTypes {
+		v, exist := attrs[name]
+		if !exist || v.IsNull() || !v.IsKnown() {
+			continue
+		}
+		switch nested.Nesting {
+		case configschema.NestingSingle, configschema.NestingGroup:
+			attrs[name] = redactValue(&nested.Block, v)
+		case configschema.NestingList, configschema.NestingSet, configschema.NestingMap:
+			attrs[name] = redactCollection(&nested.Block, v)
+		}
+	}
+
+	if len(attrs) == 0 {
+		return val
+	}
+	return cty.ObjectVal(attrs)
+}
+
+func redactCollection(block *configschema.Block, val cty.Value) cty.Value {
+	ty := val.Type()
+	if ty.IsObjectType() {
+		elems := val.AsValueMap()
+		for key, elem := range elems {
+			elems[key] = redactValue(block, elem)
+		}
+		if len(elems) == 0 {
+			return val
+		}
+		return cty.ObjectVal(elems)
+	}
+
+	if val.LengthInt() == 0 {
+		return val
+	}
+
+	switch {
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		elems := make([]cty.Value, 0, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			elems = append(elems, redactValue(block, elem))
+		}
+		switch {
+		case ty.IsListType():
+			return cty.ListVal(elems)
+		case ty.IsSetType():
+			return cty.SetVal(elems)
+		default:
+			return cty.TupleVal(elems)
+		}
+	case ty.IsMapType():
+		elems := make(map[string]cty.Value, val.LengthInt())
+		for it := val.ElementIterator(); it.Next(); {
+			key, elem := it.Element()
+			elems[key.AsString()] = redactValue(block, elem)
+		}
+		return cty.MapVal(elems)
+	}
+	return val
+}
@@ -0,0 +1,250 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	DefaultMaxAttempts    = 5
+	DefaultMaxElapsedTime = 2 * time.Minute
+	defaultBaseDelay      = 500 * time.Millisecond
+	defaultMaxDelay       = 20 * time.Second
+)
+
+type Options struct {
+	// MaxAttempts is the maximum number of calls made for a single request, retries are disabled below 2
+	MaxAttempts int
+	// MaxElapsedTime stops retrying when the next attempt would start after this duration, 0 means no limit
+	MaxElapsedTime time.Duration
+	// BaseDelay and MaxDelay bound the exponential backoff, defaults are used when not set
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// Policy retries transient and throttling errors with an exponential backoff and jitter.
+// The same policy is shared by every client of a scan, so retries can be counted for the whole scan.
+type Policy struct {
+	opts    Options
+	retries uint64
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxAttempts:    DefaultMaxAttempts,
+		MaxElapsedTime: DefaultMaxElapsedTime,
+	}
+}
+
+func NewPolicy(opts Options) *Policy {
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = defaultBaseDelay
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = defaultMaxDelay
+	}
+	return &Policy{opts: opts}
+}
+
+// MaxAttempts returns the maximum number of calls made for a single request
+func (p *Policy) MaxAttempts() int {
+	if p.opts.MaxAttempts < 1 {
+		return 1
+	}
+	return p.opts.MaxAttempts
+}
+
+// Retries returns the number of retries made since the policy was created
+func (p *Policy) Retries() uint64 {
+	return atomic.LoadUint64(&p.retries)
+}
+
+// Backoff returns the delay to wait before the given retry, starting at zero.
+// The delay doubles for each retry up to MaxDelay, and half of it is randomized.
+func (p *Policy) Backoff(retry int) time.Duration {
+	delay := p.opts.MaxDelay
+	if retry < 32 && p.opts.BaseDelay<<uint(retry) < p.opts.MaxDelay {
+		delay = p.opts.BaseDelay << uint(retry)
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// ShouldRetry tells whether a request started at the given time and already attempted the given
+// number of times can be retried after the given delay
+func (p *Policy) ShouldRetry(attempts int, start time.Time, delay time.Duration) bool {
+	if attempts >= p.MaxAttempts() {
+		return false
+	}
+	if p.opts.MaxElapsedTime > 0 && time.Since(start)+delay > p.opts.MaxElapsedTime {
+		return false
+	}
+	return true
+}
+
+// CountRetry records a retry, it must be called by clients handling retries themselves
+func (p *Policy) CountRetry() {
+	atomic.AddUint64(&p.retries, 1)
+}
+
+// wait sleeps before the next attempt, it returns false if the request should not be retried
+func (p *Policy) wait(ctx context.Context, attempts int, start time.Time, minDelay time.Duration) bool {
+	delay := p.Backoff(attempts - 1)
+	if minDelay > delay {
+		delay = minDelay
+	}
+	if !p.ShouldRetry(attempts, start, delay) {
+		return false
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+	}
+	p.CountRetry()
+	return true
+}
+
+// Do calls fn until it succeeds, returns an error that is not retryable, or the policy gives up
+func (p *Policy) Do(ctx context.Context, isRetryable func(error) bool, fn func() error) error {
+	start := time.Now()
+	for attempts := 1; ; attempts++ {
+		err := fn()
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+		logrus.WithFields(logrus.Fields{
+			"attempt": attempts,
+			"error":   err,
+		}).Debug("Got a retryable error")
+		if !p.wait(ctx, attempts, start, 0) {
+			return err
+		}
+	}
+}
+
+// Transport wraps an HTTP round tripper to retry requests on network errors, throttling and 5xx responses
+func (p *Policy) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{policy: p, base: base}
+}
+
+// UnaryClientInterceptor retries gRPC calls failing with transient codes
+func (p *Policy) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return p.Do(ctx, IsRetryableGRPCError, func() error {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		})
+	}
+}
+
+type transport struct {
+	policy *Policy
+	base   http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	current := req
+	for attempts := 1; ; attempts++ {
+		resp, err := t.base.RoundTrip(current)
+		if !isRetryableResponse(resp, err) {
+			return resp, err
+		}
+		// Requests with a body can only be retried if the body can be read again
+		if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+			return resp, err
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"attempt": attempts,
+			"url":     req.URL.Redacted(),
+		}).Debug("Got a retryable HTTP response")
+
+		if !t.policy.wait(req.Context(), attempts, start, retryAfter(resp)) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(ioutil.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+
+		current = req.Clone(req.Context())
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			current.Body = body
+		}
+	}
+}
+
+func isRetryableResponse(resp *http.Response, err error) bool {
+	if err != nil {
+		return IsRetryableNetworkError(err)
+	}
+	switch resp.StatusCode {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter returns the delay asked by the server with the Retry-After header, if any
+func retryAfter(resp *http.Response) time.Duration {
+	if resp == nil {
+		return 0
+	}
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// IsRetryableNetworkError returns true for timeouts and connections closed by the remote end
+func IsRetryableNetworkError(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// IsRetryableGRPCError returns true for transient and throttling gRPC errors
+func IsRetryableGRPCError(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
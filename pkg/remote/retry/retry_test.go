@@ -0,0 +1,133 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestPolicy_Backoff(t *testing.T) {
+	p := NewPolicy(Options{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second})
+
+	for i := 0; i < 20; i++ {
+		delay := p.Backoff(0)
+		assert.GreaterOrEqual(t, int64(delay), int64(50*time.Millisecond))
+		assert.LessOrEqual(t, int64(delay), int64(100*time.Millisecond))
+
+		delay = p.Backoff(2)
+		assert.GreaterOrEqual(t, int64(delay), int64(200*time.Millisecond))
+		assert.LessOrEqual(t, int64(delay), int64(400*time.Millisecond))
+
+		delay = p.Backoff(50)
+		assert.GreaterOrEqual(t, int64(delay), int64(500*time.Millisecond))
+		assert.LessOrEqual(t, int64(delay), int64(time.Second))
+	}
+}
+
+func TestPolicy_ShouldRetry(t *testing.T) {
+	p := NewPolicy(Options{MaxAttempts: 3, MaxElapsedTime: time.Minute})
+
+	assert.True(t, p.ShouldRetry(1, time.Now(), time.Second))
+	assert.True(t, p.ShouldRetry(2, time.Now(), time.Second))
+	assert.False(t, p.ShouldRetry(3, time.Now(), time.Second))
+	assert.False(t, p.ShouldRetry(1, time.Now().Add(-59*time.Second), 2*time.Second))
+
+	assert.False(t, NewPolicy(Options{}).ShouldRetry(1, time.Now(), 0))
+}
+
+func TestPolicy_Do(t *testing.T) {
+	p := NewPolicy(Options{MaxAttempts: 3, BaseDelay: time.Millisecond})
+
+	calls := 0
+	err := p.Do(context.Background(), IsRetryableGRPCError, func() error {
+		calls++
+		if calls < 3 {
+			return status.Error(codes.Unavailable, "unavailable")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, uint64(2), p.Retries())
+
+	calls = 0
+	err = p.Do(context.Background(), IsRetryableGRPCError, func() error {
+		calls++
+		return status.Error(codes.PermissionDenied, "denied")
+	})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	err = p.Do(context.Background(), IsRetryableGRPCError, func() error {
+		calls++
+		return status.Error(codes.ResourceExhausted, "quota")
+	})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, uint64(4), p.Retries())
+}
+
+func TestPolicy_Transport(t *testing.T) {
+	var bodies []string
+	responses := []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.WriteHeader(responses[len(bodies)-1])
+	}))
+	defer server.Close()
+
+	p := NewPolicy(Options{MaxAttempts: 5, BaseDelay: time.Millisecond})
+	client := &http.Client{Transport: p.Transport(nil)}
+
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"query":"foo"}`))
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{`{"query":"foo"}`, `{"query":"foo"}`, `{"query":"foo"}`}, bodies)
+	assert.Equal(t, uint64(2), p.Retries())
+}
+
+func TestPolicy_Transport_GiveUp(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	p := NewPolicy(Options{MaxAttempts: 2, BaseDelay: time.Millisecond})
+	client := &http.Client{Transport: p.Transport(nil)}
+
+	resp, err := client.Get(server.URL)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, uint64(1), p.Retries())
+}
+
+func TestIsRetryableNetworkError(t *testing.T) {
+	assert.True(t, IsRetryableNetworkError(&timeoutError{}))
+	assert.False(t, IsRetryableNetworkError(context.Canceled))
+	assert.False(t, IsRetryableNetworkError(errors.New("x509: certificate signed by unknown authority")))
+}
+
+type timeoutError struct{}
+
+func (e *timeoutError) Error() string   { return "i/o timeout" }
+func (e *timeoutError) Timeout() bool   { return true }
+func (e *timeoutError) Temporary() bool { return true }
@@ -12,6 +12,9 @@ import (
 	"github.com/snyk/driftctl/pkg/resource"
 )
 
+// maxPendingDetailsFetching is the maximum number of resources waiting for their details to be fetched in deep mode
+const maxPendingDetailsFetching = 100
+
 type ScannerOptions struct {
 	Deep bool
 }
@@ -42,7 +45,12 @@ loop:
 	for {
 		select {
 		case resources, ok := <-runner.Read():
-			if !ok || resources == nil {
+			if !ok {
+				break loop
+			}
+			// A routine failed and the runner is stopping
+			if resources == nil {
+				runner.Drain()
 				break loop
 			}
 
@@ -52,6 +60,7 @@ loop:
 				}
 			}
 		case <-runner.DoneChan():
+			runner.Drain()
 			break loop
 		}
 	}
@@ -89,35 +98,80 @@ func (s *Scanner) scan() ([]*resource.Resource, error) {
 		})
 	}
 
-	enumerationResult, err := s.retrieveRunnerResults(s.enumeratorRunner)
-	if err != nil {
-		return nil, err
-	}
-
 	if !s.options.Deep {
-		return enumerationResult, nil
+		return s.retrieveRunnerResults(s.enumeratorRunner)
 	}
 
-	for _, res := range enumerationResult {
-		res := res
-		s.detailsFetcherRunner.Run(func() (interface{}, error) {
-			fetcher := s.remoteLibrary.GetDetailsFetcher(resource.ResourceType(res.ResourceType()))
-			if fetcher == nil {
-				return []*resource.Resource{res}, nil
+	// In deep mode enumeration results are streamed to the details fetchers as soon as an enumerator is done.
+	// This way we never hold the whole list of enumerated resources in memory next to the detailed ones.
+	// Detailed resources are still all returned at once, so memory remains proportional to the number of
+	// scanned resources, middlewares and the analyzer need every remote resource anyway.
+	// Scheduling does not take a details fetching slot, it only waits for enumerators.
+	s.detailsFetcherRunner.RunUnbounded(func() (interface{}, error) {
+		return []*resource.Resource{}, s.fetchDetails()
+	})
+
+	return s.retrieveRunnerResults(s.detailsFetcherRunner)
+}
+
+// fetchDetails reads enumerated resources and schedules a details fetching for each of them.
+// The number of pending fetches is bounded to avoid spawning a routine per resource on very large accounts.
+func (s *Scanner) fetchDetails() error {
+	pending := make(chan struct{}, maxPendingDetailsFetching)
+loop:
+	for {
+		select {
+		case resources, ok := <-s.enumeratorRunner.Read():
+			if !ok {
+				break loop
+			}
+			// An enumeration failed and the runner is stopping
+			if resources == nil {
+				s.enumeratorRunner.Drain()
+				break loop
 			}
 
-			resourceWithDetails, err := fetcher.ReadDetails(res)
-			if err != nil {
-				if err := HandleResourceDetailsFetchingError(err, s.alerter); err != nil {
-					return nil, err
+			for _, res := range resources.([]*resource.Resource) {
+				if res == nil {
+					continue
+				}
+				res := res
+				select {
+				case pending <- struct{}{}:
+				case <-s.detailsFetcherRunner.DoneChan():
+					// A details fetching failed, enumerators still running would otherwise stay blocked
+					// on sending their results
+					s.enumeratorRunner.Stop(errors.New("details fetching failed"))
+					s.enumeratorRunner.Drain()
+					return nil
 				}
-				return []*resource.Resource{}, nil
+				s.detailsFetcherRunner.Run(func() (interface{}, error) {
+					defer func() { <-pending }()
+					return s.fetchResourceDetails(res)
+				})
 			}
-			return []*resource.Resource{resourceWithDetails}, nil
-		})
+		case <-s.enumeratorRunner.DoneChan():
+			s.enumeratorRunner.Drain()
+			break loop
+		}
 	}
+	return s.enumeratorRunner.Err()
+}
 
-	return s.retrieveRunnerResults(s.detailsFetcherRunner)
+func (s *Scanner) fetchResourceDetails(res *resource.Resource) ([]*resource.Resource, error) {
+	fetcher := s.remoteLibrary.GetDetailsFetcher(resource.ResourceType(res.ResourceType()))
+	if fetcher == nil {
+		return []*resource.Resource{res}, nil
+	}
+
+	resourceWithDetails, err := fetcher.ReadDetails(res)
+	if err != nil {
+		if err := HandleResourceDetailsFetchingError(err, s.alerter); err != nil {
+			return nil, err
+		}
+		return []*resource.Resource{}, nil
+	}
+	return []*resource.Resource{resourceWithDetails}, nil
 }
 
 func (s *Scanner) Resources() ([]*resource.Resource, error) {
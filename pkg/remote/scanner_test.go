@@ -1,13 +1,20 @@
 package remote
 
 import (
+	"fmt"
+	"runtime"
 	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
 	"github.com/snyk/driftctl/pkg/alerter"
 	"github.com/snyk/driftctl/pkg/filter"
 	"github.com/snyk/driftctl/pkg/remote/common"
 	"github.com/snyk/driftctl/pkg/resource"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestScannerShouldIgnoreType(t *testing.T) {
@@ -29,3 +36,199 @@ func TestScannerShouldIgnoreType(t *testing.T) {
 	assert.Nil(t, err)
 	fakeEnumerator.AssertExpectations(t)
 }
+
+type fakeDetailsFetcher struct{}
+
+func (f *fakeDetailsFetcher) ReadDetails(res *resource.Resource) (*resource.Resource, error) {
+	return &resource.Resource{
+		Id:   res.ResourceId(),
+		Type: res.ResourceType(),
+		Attrs: &resource.Attributes{
+			"id":   res.ResourceId(),
+			"tags": map[string]interface{}{"Name": res.ResourceId()},
+		},
+	}, nil
+}
+
+type failingDetailsFetcher struct{}
+
+func (f *failingDetailsFetcher) ReadDetails(res *resource.Resource) (*resource.Resource, error) {
+	return nil, errors.New("unable to read details")
+}
+
+func TestScannerDeep_DetailsFetchingErrorStopsEnumerators(t *testing.T) {
+	remoteLibrary := common.NewRemoteLibrary()
+	for i := 0; i < 50; i++ {
+		ty := resource.ResourceType(fmt.Sprintf("fake_type_%d", i))
+		enumerator := &common.MockEnumerator{}
+		enumerator.On("SupportedType").Return(ty)
+		enumerator.On("Enumerate").Return([]*resource.Resource{{Id: "id", Type: string(ty)}}, nil).Maybe()
+		remoteLibrary.AddEnumerator(enumerator)
+		remoteLibrary.AddDetailsFetcher(ty, &failingDetailsFetcher{})
+	}
+
+	testFilter := &filter.MockFilter{}
+	testFilter.On("IsTypeIgnored", mock.Anything).Return(false)
+
+	alerter := alerter.NewAlerter()
+	goroutines := runtime.NumGoroutine()
+
+	s := NewScanner(remoteLibrary, alerter, ScannerOptions{Deep: true}, testFilter)
+	_, err := s.Resources()
+	assert.EqualError(t, err, "unable to read details")
+
+	// Enumerators and details fetchers still running when the error occurred must not stay blocked
+	deadline := time.Now().Add(5 * time.Second)
+	for runtime.NumGoroutine() > goroutines && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+}
+
+// fakeEnumerator allocates new resources on each enumeration, like a real enumerator would,
+// so that enumerated resources can be released once their details are fetched
+type fakeEnumerator struct {
+	ty    resource.ResourceType
+	count int
+}
+
+func (e *fakeEnumerator) SupportedType() resource.ResourceType {
+	return e.ty
+}
+
+func (e *fakeEnumerator) Enumerate() ([]*resource.Resource, error) {
+	resources := make([]*resource.Resource, 0, e.count)
+	for i := 0; i < e.count; i++ {
+		id := fmt.Sprintf("%s-%d", e.ty, i)
+		resources = append(resources, &resource.Resource{
+			Id:   id,
+			Type: string(e.ty),
+			Attrs: &resource.Attributes{
+				"id":   id,
+				"arn":  fmt.Sprintf("arn:aws:fake:us-east-1:123456789012:%s", id),
+				"tags": map[string]interface{}{"Name": id},
+			},
+		})
+	}
+	return resources, nil
+}
+
+// scanEnumerateThenFetchDetails is the deep scan as it was before enumeration results were streamed to
+// details fetchers: every resource is enumerated first, then details are fetched for all of them.
+func (s *Scanner) scanEnumerateThenFetchDetails() ([]*resource.Resource, error) {
+	for _, enumerator := range s.remoteLibrary.Enumerators() {
+		enumerator := enumerator
+		s.enumeratorRunner.Run(func() (interface{}, error) {
+			return enumerator.Enumerate()
+		})
+	}
+
+	enumerationResult, err := s.retrieveRunnerResults(s.enumeratorRunner)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range enumerationResult {
+		res := res
+		s.detailsFetcherRunner.Run(func() (interface{}, error) {
+			return s.fetchResourceDetails(res)
+		})
+	}
+
+	return s.retrieveRunnerResults(s.detailsFetcherRunner)
+}
+
+// peakHeapInuse samples the heap in use while scan runs and returns the highest value seen
+func peakHeapInuse(scan func()) uint64 {
+	runtime.GC()
+
+	var peak uint64
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+	go func() {
+		defer close(sampled)
+		ticker := time.NewTicker(5 * time.Millisecond)
+		defer ticker.Stop()
+		stats := runtime.MemStats{}
+		for {
+			runtime.ReadMemStats(&stats)
+			if stats.HeapInuse > peak {
+				peak = stats.HeapInuse
+			}
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	scan()
+	close(done)
+	<-sampled
+	return peak
+}
+
+// BenchmarkScanner_Deep scans a synthetic account of 200k resources split into 200 types,
+// with enumeration results streamed to details fetchers and with every resource enumerated before fetching details
+func BenchmarkScanner_Deep(b *testing.B) {
+	const (
+		typeCount        = 200
+		resourcesPerType = 1000
+	)
+
+	remoteLibrary := common.NewRemoteLibrary()
+	for i := 0; i < typeCount; i++ {
+		ty := resource.ResourceType(fmt.Sprintf("fake_type_%d", i))
+		remoteLibrary.AddEnumerator(&fakeEnumerator{ty: ty, count: resourcesPerType})
+		remoteLibrary.AddDetailsFetcher(ty, &fakeDetailsFetcher{})
+	}
+
+	testFilter := &filter.MockFilter{}
+	testFilter.On("IsTypeIgnored", mock.Anything).Return(false)
+
+	logrus.SetLevel(logrus.WarnLevel)
+
+	cases := []struct {
+		name string
+		scan func(s *Scanner) ([]*resource.Resource, error)
+	}{
+		{
+			name: "enumerate then fetch details",
+			scan: func(s *Scanner) ([]*resource.Resource, error) {
+				return s.scanEnumerateThenFetchDetails()
+			},
+		},
+		{
+			name: "streaming",
+			scan: func(s *Scanner) ([]*resource.Resource, error) {
+				return s.Resources()
+			},
+		},
+	}
+
+	for _, c := range cases {
+		b.Run(c.name, func(b *testing.B) {
+			b.ReportAllocs()
+			var peak uint64
+			for n := 0; n < b.N; n++ {
+				s := NewScanner(remoteLibrary, alerter.NewAlerter(), ScannerOptions{Deep: true}, testFilter)
+				var resources []*resource.Resource
+				var err error
+				scanPeak := peakHeapInuse(func() {
+					resources, err = c.scan(s)
+				})
+				if err != nil {
+					b.Fatal(err)
+				}
+				if len(resources) != typeCount*resourcesPerType {
+					b.Fatalf("expected %d resources, got %d", typeCount*resourcesPerType, len(resources))
+				}
+				if scanPeak > peak {
+					peak = scanPeak
+				}
+			}
+			b.ReportMetric(float64(peak)/1024/1024, "peak-heap-MB")
+		})
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/snyk/driftctl/pkg/cmd/scan"
 	"github.com/snyk/driftctl/pkg/output"
+	"github.com/snyk/driftctl/pkg/remote/recording"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/gocty"
 
@@ -32,6 +33,10 @@ type TerraformProviderConfig struct {
 	Name              string
 	DefaultAlias      string
 	GetProviderConfig func(alias string) interface{}
+	// Recorder saves the provider schema and every ReadResource result, or replays them without starting the provider
+	Recorder *recording.Recorder
+	// SchemaOnly starts the provider to read its schema without configuring it, resources can't be read then
+	SchemaOnly bool
 }
 
 type TerraformProvider struct {
@@ -56,6 +61,15 @@ func NewTerraformProvider(installer *tf.ProviderInstaller, config TerraformProvi
 }
 
 func (p *TerraformProvider) Init() error {
+	if p.Config.Recorder.IsReplaying() {
+		schemas, err := p.Config.Recorder.ReadSchema(p.Config.Name)
+		if err != nil {
+			return err
+		}
+		p.schemas = schemas
+		return nil
+	}
+
 	stopCh := make(chan bool)
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
@@ -76,6 +90,9 @@ func (p *TerraformProvider) Init() error {
 	if err != nil {
 		return err
 	}
+	if p.Config.Recorder.IsRecording() {
+		return p.Config.Recorder.WriteSchema(p.Config.Name, p.schemas)
+	}
 	return nil
 }
 
@@ -111,6 +128,9 @@ func (p *TerraformProvider) configure(alias string) error {
 	if p.schemas == nil {
 		p.schemas = schema.ResourceTypes
 	}
+	if p.Config.SchemaOnly {
+		return nil
+	}
 
 	// This value is optional. It'll be overridden by the provider config.
 	config := cty.NullVal(cty.DynamicPseudoType)
@@ -143,7 +163,40 @@ func (p *TerraformProvider) configure(alias string) error {
 }
 
 func (p *TerraformProvider) ReadResource(args tf.ReadResourceArgs) (*cty.Value, error) {
+	recorder := p.Config.Recorder
+	if recorder.IsReplaying() {
+		value, err := recorder.ReadResource(p.Config.Name, args)
+		if err != nil {
+			return nil, err
+		}
+		p.progress.Inc()
+		return value, nil
+	}
+	if !recorder.IsRecording() {
+		return p.readResource(args)
+	}
+
+	// Attributes are modified when reading the resource, so we keep the ones used to identify the recording
+	recordedArgs := tf.ReadResourceArgs{
+		Ty:         args.Ty,
+		ID:         args.ID,
+		Attributes: make(map[string]string, len(args.Attributes)),
+	}
+	for k, v := range args.Attributes {
+		recordedArgs.Attributes[k] = v
+	}
+	value, err := p.readResource(args)
+	if recordErr := recorder.WriteResource(p.Config.Name, p.schemas[string(args.Ty)], recordedArgs, value, err); recordErr != nil {
+		logrus.WithFields(logrus.Fields{
+			"id":    args.ID,
+			"type":  args.Ty,
+			"error": recordErr,
+		}).Warn("Unable to record cloud resource")
+	}
+	return value, err
+}
 
+func (p *TerraformProvider) readResource(args tf.ReadResourceArgs) (*cty.Value, error) {
 	logrus.WithFields(logrus.Fields{
 		"id":    args.ID,
 		"type":  args.Ty,
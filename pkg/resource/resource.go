@@ -16,20 +16,72 @@ type Source interface {
 	InternalName() string
 }
 
+// TerraformStateSourceKind is used to tell which kind of source a resource comes from once serialized
+const TerraformStateSourceKind = "terraform_state"
+
+// SerializableSource is the serialized form of a Source.
+// It is also used as a Source when deserializing a source of an unknown kind.
 type SerializableSource struct {
+	Kind string `json:"kind,omitempty"`
 	S    string `json:"source"`
 	Ns   string `json:"namespace"`
 	Name string `json:"internal_name"`
+	// Ws is the terraform workspace of the state the resource was found in
+	Ws string `json:"workspace,omitempty"`
+}
+
+func (s *SerializableSource) Source() string {
+	return s.S
+}
+
+func (s *SerializableSource) Namespace() string {
+	return s.Ns
+}
+
+func (s *SerializableSource) InternalName() string {
+	return s.Name
+}
+
+func NewSerializableSource(src Source) *SerializableSource {
+	serializable := &SerializableSource{
+		S:    src.Source(),
+		Ns:   src.Namespace(),
+		Name: src.InternalName(),
+	}
+	switch s := src.(type) {
+	case *TerraformStateSource:
+		serializable.Kind = TerraformStateSourceKind
+		serializable.Ws = s.Workspace
+	case *SerializableSource:
+		serializable.Kind = s.Kind
+		serializable.Ws = s.Ws
+	}
+	return serializable
+}
+
+// ToSource converts back a serialized source to its original type
+func (s *SerializableSource) ToSource() Source {
+	switch s.Kind {
+	// Sources serialized without kind were always coming from a terraform state
+	case TerraformStateSourceKind, "":
+		source := NewTerraformStateSource(s.S, s.Ns, s.Name)
+		source.Workspace = s.Ws
+		return source
+	default:
+		return &SerializableSource{Kind: s.Kind, S: s.S, Ns: s.Ns, Name: s.Name, Ws: s.Ws}
+	}
 }
 
 type TerraformStateSource struct {
 	State  string
 	Module string
 	Name   string
+	// Workspace is set when the state was found by enumerating terraform workspaces
+	Workspace string
 }
 
 func NewTerraformStateSource(state, module, name string) *TerraformStateSource {
-	return &TerraformStateSource{state, module, name}
+	return &TerraformStateSource{State: state, Module: module, Name: name}
 }
 
 func (s *TerraformStateSource) Source() string {
@@ -44,12 +96,20 @@ func (s *TerraformStateSource) InternalName() string {
 	return s.Name
 }
 
+// Origin tells where a resource was found when analyses from several scans are merged
+type Origin struct {
+	Provider string `json:"provider,omitempty"`
+	Account  string `json:"account,omitempty"`
+	File     string `json:"file,omitempty"`
+}
+
 type Resource struct {
 	Id     string
 	Type   string
 	Attrs  *Attributes
 	Sch    *Schema `json:"-" diff:"-"`
 	Source Source  `json:"-"`
+	Origin *Origin `json:"-" diff:"-"`
 }
 
 func (r *Resource) Schema() *Schema {
@@ -102,24 +162,107 @@ type SerializableResource struct {
 	Id                 string              `json:"id"`
 	Type               string              `json:"type"`
 	ReadableAttributes map[string]string   `json:"human_readable_attributes,omitempty"`
+	Attributes         *Attributes         `json:"attributes,omitempty"`
 	Source             *SerializableSource `json:"source,omitempty"`
+	Origin             *Origin             `json:"origin,omitempty"`
 }
 
 func NewSerializableResource(res *Resource) *SerializableResource {
 	var src *SerializableSource
 	if res.Src() != nil {
-		src = &SerializableSource{
-			S:    res.Src().Source(),
-			Ns:   res.Src().Namespace(),
-			Name: res.Src().InternalName(),
-		}
+		src = NewSerializableSource(res.Src())
+	}
+	var attrs *Attributes
+	if res.Attributes() != nil && len(*res.Attributes()) > 0 {
+		attrs = redactSensitiveAttributes(res.Schema(), res.Attributes())
 	}
 	return &SerializableResource{
 		Id:                 res.ResourceId(),
 		Type:               res.ResourceType(),
 		ReadableAttributes: formatReadableAttributes(res),
+		Attributes:         attrs,
 		Source:             src,
+		Origin:             res.Origin,
+	}
+}
+
+// ToResource rebuilds a resource from its serialized form.
+// Schemas are not serialized, so the resource only gets a schema returning
+// the serialized human readable attributes, if any, and telling its attributes are already redacted.
+func (r *SerializableResource) ToResource() *Resource {
+	res := &Resource{
+		Id:     r.Id,
+		Type:   r.Type,
+		Attrs:  r.Attributes,
+		Origin: r.Origin,
+	}
+	if r.Source != nil {
+		res.Source = r.Source.ToSource()
+	}
+	if r.Attributes != nil {
+		// Sensitive values were redacted when the resource was serialized
+		res.Sch = &Schema{redacted: true}
+	}
+	if len(r.ReadableAttributes) > 0 {
+		readableAttributes := r.ReadableAttributes
+		if res.Sch == nil {
+			res.Sch = &Schema{}
+		}
+		res.Sch.HumanReadableAttributesFunc = func(*Resource) map[string]string {
+			return readableAttributes
+		}
+	}
+	return res
+}
+
+// SensitiveValue replaces the value of attributes marked as sensitive in the schema when a resource is serialized
+const SensitiveValue = "(sensitive value)"
+
+// redactSensitiveAttributes returns a copy of the attributes where the values of sensitive attributes are replaced,
+// so secrets read from states or remotes never end up in outputs.
+// Without schema we can't tell which attributes are sensitive, so every value is replaced.
+func redactSensitiveAttributes(schema *Schema, attrs *Attributes) *Attributes {
+	if schema != nil && schema.redacted {
+		return attrs
+	}
+	if schema == nil || len(schema.Attributes) == 0 {
+		redacted := make(Attributes, len(*attrs))
+		for key := range *attrs {
+			redacted[key] = SensitiveValue
+		}
+		return &redacted
+	}
+	redacted := Attributes(redactSensitiveMap(schema, "", *attrs))
+	return &redacted
+}
+
+func redactSensitiveMap(schema *Schema, path string, values map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		redacted[key] = redactSensitiveValue(schema, concatenatePath(path, key), value)
+	}
+	return redacted
+}
+
+func redactSensitiveValue(schema *Schema, path string, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+	if metadata, exist := schema.Attributes[path]; exist && metadata.ConfigSchema.Sensitive {
+		return SensitiveValue
+	}
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return redactSensitiveMap(schema, path, v)
+	case []interface{}:
+		// Schema paths of nested blocks don't hold list indexes
+		redacted := make([]interface{}, 0, len(v))
+		for _, elem := range v {
+			redacted = append(redacted, redactSensitiveValue(schema, path, elem))
+		}
+		return redacted
 	}
+	return value
 }
 
 func formatReadableAttributes(res *Resource) map[string]string {
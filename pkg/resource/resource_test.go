@@ -1,8 +1,10 @@
 package resource
 
 import (
+	"encoding/json"
 	"testing"
 
+	"github.com/hashicorp/terraform/configs/configschema"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -73,3 +75,78 @@ func TestSanitizeDefaults(t *testing.T) {
 		})
 	}
 }
+
+func TestSerializableSource_Workspace(t *testing.T) {
+	source := NewTerraformStateSource("tfstate+s3://bucket/env:/prod/terraform.tfstate", "module", "name")
+	source.Workspace = "prod"
+
+	serialized, err := json.Marshal(NewSerializableSource(source))
+	assert.NoError(t, err)
+	assert.Contains(t, string(serialized), `"workspace":"prod"`)
+
+	var deserialized SerializableSource
+	assert.NoError(t, json.Unmarshal(serialized, &deserialized))
+	assert.Equal(t, source, deserialized.ToSource())
+}
+
+func TestNewSerializableResource_RedactSensitiveAttributes(t *testing.T) {
+	schema := &Schema{
+		Attributes: map[string]AttributeSchema{
+			"password":                  {ConfigSchema: configschema.Attribute{Sensitive: true}},
+			"username":                  {ConfigSchema: configschema.Attribute{}},
+			"environment.variables":     {ConfigSchema: configschema.Attribute{Sensitive: true}},
+			"credentials.secret_key":    {ConfigSchema: configschema.Attribute{Sensitive: true}},
+			"credentials.access_key_id": {ConfigSchema: configschema.Attribute{}},
+		},
+	}
+	attrs := Attributes{
+		"username": "admin",
+		"password": "hunter2",
+		"environment": []interface{}{
+			map[string]interface{}{
+				"variables": map[string]interface{}{"TOKEN": "secret"},
+			},
+		},
+		"credentials": []interface{}{
+			map[string]interface{}{"access_key_id": "AKIA", "secret_key": "secret"},
+			map[string]interface{}{"access_key_id": "AKIB", "secret_key": nil},
+		},
+	}
+	res := &Resource{Id: "id", Type: "type", Attrs: &attrs, Sch: schema}
+
+	serialized := NewSerializableResource(res)
+
+	assert.Equal(t, &Attributes{
+		"username": "admin",
+		"password": SensitiveValue,
+		"environment": []interface{}{
+			map[string]interface{}{"variables": SensitiveValue},
+		},
+		"credentials": []interface{}{
+			map[string]interface{}{"access_key_id": "AKIA", "secret_key": SensitiveValue},
+			map[string]interface{}{"access_key_id": "AKIB", "secret_key": nil},
+		},
+	}, serialized.Attributes)
+	// The resource itself is left untouched
+	assert.Equal(t, "hunter2", *res.Attributes().GetString("password"))
+}
+
+func TestNewSerializableResource_RedactAttributesWithoutSchema(t *testing.T) {
+	attrs := Attributes{
+		"username": "admin",
+		"password": "hunter2",
+		"tags":     map[string]interface{}{"Name": "foo"},
+	}
+
+	for _, schema := range []*Schema{nil, {}} {
+		res := &Resource{Id: "id", Type: "type", Attrs: &attrs, Sch: schema}
+
+		serialized := NewSerializableResource(res)
+
+		assert.Equal(t, &Attributes{
+			"username": SensitiveValue,
+			"password": SensitiveValue,
+			"tags":     SensitiveValue,
+		}, serialized.Attributes)
+	}
+}
@@ -1,6 +1,7 @@
 package resource
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/hashicorp/go-version"
@@ -37,6 +38,8 @@ type Schema struct {
 	HumanReadableAttributesFunc func(res *Resource) map[string]string
 	ResolveReadAttributesFunc   func(res *Resource) map[string]string
 	DiscriminantFunc            func(*Resource, *Resource) bool
+	// redacted tells the attributes of resources read from a serialized analysis hold no sensitive value anymore
+	redacted bool
 }
 
 func (s *Schema) IsComputedField(path []string) bool {
@@ -47,6 +50,23 @@ func (s *Schema) IsComputedField(path []string) bool {
 	return metadata.ConfigSchema.Computed
 }
 
+// IsSensitiveField tells whether a changed field is marked as sensitive, list indexes of the path are skipped
+// as schema paths of nested blocks don't hold them
+func (s *Schema) IsSensitiveField(path []string) bool {
+	schemaPath := make([]string, 0, len(path))
+	for _, part := range path {
+		if _, err := strconv.Atoi(part); err == nil {
+			continue
+		}
+		schemaPath = append(schemaPath, part)
+	}
+	metadata, exist := s.Attributes[strings.Join(schemaPath, ".")]
+	if !exist {
+		return false
+	}
+	return metadata.ConfigSchema.Sensitive
+}
+
 func (s *Schema) IsJsonStringField(path []string) bool {
 	metadata, exist := s.Attributes[strings.Join(path, ".")]
 	if !exist {
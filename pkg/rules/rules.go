@@ -0,0 +1,265 @@
+package rules
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/google/cel-go/cel"
+	"github.com/google/cel-go/checker/decls"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/snyk/driftctl/pkg/resource"
+	exprpb "google.golang.org/genproto/googleapis/api/expr/v1alpha1"
+	"google.golang.org/protobuf/proto"
+)
+
+type Kind string
+
+const (
+	// KindIgnoreChange rules are evaluated for each change of a drifted resource,
+	// the change is not reported when the expression is true.
+	KindIgnoreChange Kind = "ignore_change"
+	// KindManaged rules are evaluated for each unmanaged resource against every resource found in IaC,
+	// the unmanaged resource is reported as managed when the expression is true.
+	KindManaged Kind = "managed"
+)
+
+// Definition is a rule as written by users in a rules file
+type Definition struct {
+	Name       string `json:"name"`
+	Kind       Kind   `json:"kind"`
+	Expression string `json:"expression"`
+}
+
+type file struct {
+	Rules []Definition `json:"rules"`
+}
+
+type rule struct {
+	name    string
+	program cel.Program
+	// partial is the same program evaluating expressions where only resource types are known
+	partial cel.Program
+}
+
+// RuleSet holds compiled CEL rules. Expressions can use the following variables:
+//   - state: the resource found in IaC, a map with id, type, source and attributes keys
+//   - remote: the resource found on the cloud provider, with the same keys as state
+//   - change: only for ignore_change rules, a map with type, path, from and to keys.
+//     The path is a string joined with dots (e.g. tags.LastModified)
+type RuleSet struct {
+	ignoreChange []rule
+	managed      []rule
+}
+
+// ReadFile compiles every rule found in the given YAML file
+func ReadFile(path string) (*RuleSet, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to read rules file %s", path)
+	}
+
+	var f file
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		return nil, errors.Wrapf(err, "unable to parse rules file %s", path)
+	}
+
+	return NewRuleSet(f.Rules...)
+}
+
+func NewRuleSet(definitions ...Definition) (*RuleSet, error) {
+	resourceType := decls.NewMapType(decls.String, decls.Dyn)
+	env, err := cel.NewEnv(cel.Declarations(
+		decls.NewVar("state", resourceType),
+		decls.NewVar("remote", resourceType),
+		decls.NewVar("change", decls.NewMapType(decls.String, decls.Dyn)),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	set := &RuleSet{}
+	for _, def := range definitions {
+		ast, issues := env.Compile(def.Expression)
+		if issues != nil && issues.Err() != nil {
+			return nil, errors.Errorf("invalid expression for rule '%s': %s", def.Name, issues.Err())
+		}
+		if !proto.Equal(ast.ResultType(), decls.Bool) && !proto.Equal(ast.ResultType(), decls.Dyn) {
+			return nil, errors.Errorf("expression for rule '%s' must return a boolean, got %s", def.Name, cel.FormatType(ast.ResultType()))
+		}
+		program, err := env.Program(ast)
+		if err != nil {
+			return nil, errors.Wrapf(err, "unable to build rule '%s'", def.Name)
+		}
+
+		r := rule{name: def.Name, program: program}
+		switch def.Kind {
+		case KindIgnoreChange:
+			set.ignoreChange = append(set.ignoreChange, r)
+		case KindManaged:
+			r.partial, err = env.Program(ast, cel.EvalOptions(cel.OptPartialEval))
+			if err != nil {
+				return nil, errors.Wrapf(err, "unable to build rule '%s'", def.Name)
+			}
+			set.managed = append(set.managed, r)
+		default:
+			return nil, errors.Errorf("unsupported kind '%s' for rule '%s', valid kinds are: %s, %s", def.Kind, def.Name, KindIgnoreChange, KindManaged)
+		}
+	}
+
+	return set, nil
+}
+
+// IsChangeIgnored returns true if at least one ignore_change rule matches the given change
+func (r *RuleSet) IsChangeIgnored(stateRes, remoteRes *resource.Resource, changeType string, path []string, from, to interface{}) bool {
+	if r == nil || len(r.ignoreChange) == 0 {
+		return false
+	}
+	vars := map[string]interface{}{
+		"state":  ResourceToMap(stateRes),
+		"remote": ResourceToMap(remoteRes),
+		"change": map[string]interface{}{
+			"type": changeType,
+			"path": strings.Join(path, "."),
+			"from": from,
+			"to":   to,
+		},
+	}
+	return evalAny(r.ignoreChange, vars)
+}
+
+func (r *RuleSet) HasManagedRules() bool {
+	return r != nil && len(r.managed) > 0
+}
+
+// IsManaged returns true if at least one managed rule matches the given pair of resources
+func (r *RuleSet) IsManaged(stateRes, remoteRes *resource.Resource) bool {
+	return r.IsManagedMap(ResourceToMap(stateRes), ResourceToMap(remoteRes))
+}
+
+// IsManagedMap is IsManaged for resources already converted with ResourceToMap, so a resource compared
+// to many others is only converted once
+func (r *RuleSet) IsManagedMap(stateRes, remoteRes map[string]interface{}) bool {
+	if !r.HasManagedRules() {
+		return false
+	}
+	vars := map[string]interface{}{
+		"state":  stateRes,
+		"remote": remoteRes,
+		"change": map[string]interface{}{},
+	}
+	return evalAny(r.managed, vars)
+}
+
+// MayBeManaged tells whether a managed rule can match a state resource of the given type with a remote resource
+// of the other type. Rules are evaluated with only resource types known, so pairs of types a rule can never
+// match are skipped without comparing every pair of resources.
+func (r *RuleSet) MayBeManaged(stateType, remoteType string) bool {
+	if !r.HasManagedRules() {
+		return false
+	}
+	vars, err := cel.PartialVars(
+		map[string]interface{}{
+			"state":  map[string]interface{}{"type": stateType},
+			"remote": map[string]interface{}{"type": remoteType},
+			"change": map[string]interface{}{},
+		},
+		cel.AttributePattern("state").QualString("id"),
+		cel.AttributePattern("state").QualString("source"),
+		cel.AttributePattern("state").QualString("attributes"),
+		cel.AttributePattern("remote").QualString("id"),
+		cel.AttributePattern("remote").QualString("source"),
+		cel.AttributePattern("remote").QualString("attributes"),
+	)
+	if err != nil {
+		return true
+	}
+	for _, rule := range r.managed {
+		out, _, err := rule.partial.Eval(vars)
+		// Only a rule known to be false whatever the resources is skipped, unknown values and errors need
+		// resources to be compared
+		if err == nil {
+			if matched, ok := out.Value().(bool); ok && !matched {
+				continue
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// Expression is a single compiled CEL expression, for features that evaluate expressions
+// outside of a rule set. Every declared variable is a map of string to dyn.
+type Expression struct {
+	rule
+}
+
+func CompileExpression(name, expression string, variables ...string) (*Expression, error) {
+	declarations := make([]*exprpb.Decl, 0, len(variables))
+	for _, v := range variables {
+		declarations = append(declarations, decls.NewVar(v, decls.NewMapType(decls.String, decls.Dyn)))
+	}
+	env, err := cel.NewEnv(cel.Declarations(declarations...))
+	if err != nil {
+		return nil, err
+	}
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, errors.Errorf("invalid expression for '%s': %s", name, issues.Err())
+	}
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to build expression for '%s'", name)
+	}
+	return &Expression{rule{name: name, program: program}}, nil
+}
+
+// Eval returns the raw value computed by the expression
+func (e *Expression) Eval(vars map[string]interface{}) (interface{}, error) {
+	out, _, err := e.program.Eval(vars)
+	if err != nil {
+		return nil, errors.Wrapf(err, "unable to evaluate expression for '%s'", e.name)
+	}
+	return out.Value(), nil
+}
+
+// Matches returns true if the expression evaluates to true, evaluation errors are treated as false
+func (e *Expression) Matches(vars map[string]interface{}) bool {
+	return evalAny([]rule{e.rule}, vars)
+}
+
+func evalAny(rules []rule, vars map[string]interface{}) bool {
+	for _, rule := range rules {
+		out, _, err := rule.program.Eval(vars)
+		if err != nil {
+			// Evaluation errors are expected when an expression reads an attribute missing from a resource
+			logrus.WithFields(logrus.Fields{
+				"rule":  rule.name,
+				"error": err,
+			}).Debug("Unable to evaluate rule")
+			continue
+		}
+		if matched, ok := out.Value().(bool); ok && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// ResourceToMap converts a resource to the value exposed to expressions
+func ResourceToMap(res *resource.Resource) map[string]interface{} {
+	m := map[string]interface{}{
+		"id":         res.ResourceId(),
+		"type":       res.ResourceType(),
+		"source":     "",
+		"attributes": map[string]interface{}{},
+	}
+	if res.Src() != nil {
+		m["source"] = res.Src().Source()
+	}
+	if res.Attributes() != nil {
+		m["attributes"] = map[string]interface{}(*res.Attributes())
+	}
+	return m
+}
@@ -0,0 +1,109 @@
+package rules
+
+import (
+	"testing"
+
+	"github.com/snyk/driftctl/pkg/resource"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadFile(t *testing.T) {
+	cases := []struct {
+		name string
+		path string
+		err  string
+	}{
+		{
+			name: "valid rules",
+			path: "testdata/rules.yml",
+		},
+		{
+			name: "invalid kind",
+			path: "testdata/invalid_kind.yml",
+			err:  "unsupported kind 'unknown' for rule 'foobar', valid kinds are: ignore_change, managed",
+		},
+		{
+			name: "missing file",
+			path: "testdata/not_found.yml",
+			err:  "unable to read rules file testdata/not_found.yml: open testdata/not_found.yml: no such file or directory",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			set, err := ReadFile(c.path)
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Len(t, set.ignoreChange, 1)
+			assert.Len(t, set.managed, 1)
+		})
+	}
+}
+
+func TestNewRuleSet_InvalidExpression(t *testing.T) {
+	_, err := NewRuleSet(Definition{Name: "foo", Kind: KindIgnoreChange, Expression: "change.path =="})
+	assert.Contains(t, err.Error(), "invalid expression for rule 'foo'")
+
+	_, err = NewRuleSet(Definition{Name: "bar", Kind: KindIgnoreChange, Expression: "change.to"})
+	assert.Nil(t, err)
+
+	_, err = NewRuleSet(Definition{Name: "baz", Kind: KindIgnoreChange, Expression: "'foo'"})
+	assert.EqualError(t, err, "expression for rule 'baz' must return a boolean, got string")
+}
+
+func TestRuleSet_IsChangeIgnored(t *testing.T) {
+	set, err := ReadFile("testdata/rules.yml")
+	assert.Nil(t, err)
+
+	res := &resource.Resource{Id: "foo", Type: "aws_s3_bucket", Attrs: &resource.Attributes{}}
+
+	assert.True(t, set.IsChangeIgnored(res, res, "update", []string{"tags", "LastModified"}, "a", "b"))
+	assert.False(t, set.IsChangeIgnored(res, res, "update", []string{"tags", "Name"}, "a", "b"))
+
+	var nilSet *RuleSet
+	assert.False(t, nilSet.IsChangeIgnored(res, res, "update", []string{"tags", "LastModified"}, "a", "b"))
+}
+
+func TestRuleSet_IsManaged(t *testing.T) {
+	set, err := ReadFile("testdata/rules.yml")
+	assert.Nil(t, err)
+	assert.True(t, set.HasManagedRules())
+
+	stateRes := &resource.Resource{Id: "role", Type: "aws_iam_role", Attrs: &resource.Attributes{"arn": "arn:aws:iam::123456789012:role/role"}}
+	remoteRes := &resource.Resource{Id: "AROAEXAMPLE", Type: "aws_iam_role", Attrs: &resource.Attributes{"arn": "arn:aws:iam::123456789012:role/role"}}
+	otherRes := &resource.Resource{Id: "other", Type: "aws_iam_role", Attrs: &resource.Attributes{"arn": "arn:aws:iam::123456789012:role/other"}}
+	noAttrsRes := &resource.Resource{Id: "noattrs", Type: "aws_iam_role"}
+
+	assert.True(t, set.IsManaged(stateRes, remoteRes))
+	assert.False(t, set.IsManaged(stateRes, otherRes))
+	// Missing attributes make the evaluation fail, the rule must not match
+	assert.False(t, set.IsManaged(stateRes, noAttrsRes))
+}
+
+func TestRuleSet_MayBeManaged(t *testing.T) {
+	set, err := NewRuleSet(
+		Definition{
+			Name:       "roles by arn",
+			Kind:       KindManaged,
+			Expression: `state.type == "aws_iam_role" && remote.type == state.type && state.attributes.arn == remote.attributes.arn`,
+		},
+		Definition{
+			Name:       "buckets by name",
+			Kind:       KindManaged,
+			Expression: `remote.type == "aws_s3_bucket" && remote.id.startsWith(state.attributes.bucket_prefix)`,
+		},
+	)
+	assert.Nil(t, err)
+
+	assert.True(t, set.MayBeManaged("aws_iam_role", "aws_iam_role"))
+	assert.False(t, set.MayBeManaged("aws_iam_role", "aws_iam_user"))
+	assert.True(t, set.MayBeManaged("aws_s3_bucket", "aws_s3_bucket"))
+	assert.True(t, set.MayBeManaged("aws_s3_bucket_prefix", "aws_s3_bucket"))
+	assert.False(t, set.MayBeManaged("aws_s3_bucket", "aws_iam_role"))
+
+	var empty *RuleSet
+	assert.False(t, empty.MayBeManaged("aws_iam_role", "aws_iam_role"))
+}
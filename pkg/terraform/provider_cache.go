@@ -0,0 +1,81 @@
+package terraform
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+
+	"github.com/hashicorp/go-version"
+	"github.com/pkg/errors"
+)
+
+var providerBinaryRegex = regexp.MustCompile(`^terraform-provider-([a-z0-9-]+)_v([0-9][^_]*)(_x[0-9]+)?(\.exe)?$`)
+
+// CachedProvider is a terraform provider binary installed in the driftctl plugins directory
+type CachedProvider struct {
+	Key     string
+	Version string
+	Path    string
+	Size    int64
+}
+
+// GetProvidersDirectory returns the directory where providers are installed for the current platform
+func GetProvidersDirectory(homeDir string) string {
+	return path.Join(homeDir, fmt.Sprintf(".driftctl/plugins/%s_%s/", runtime.GOOS, runtime.GOARCH))
+}
+
+// ListCachedProviders returns the providers installed in the plugins directory, sorted by name and version
+func ListCachedProviders(homeDir string) ([]CachedProvider, error) {
+	dir := GetProvidersDirectory(homeDir)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []CachedProvider{}, nil
+		}
+		return nil, errors.Wrapf(err, "unable to list providers in %s", dir)
+	}
+
+	providers := make([]CachedProvider, 0, len(entries))
+	for _, entry := range entries {
+		matches := providerBinaryRegex.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || matches == nil {
+			continue
+		}
+		providers = append(providers, CachedProvider{
+			Key:     matches[1],
+			Version: matches[2],
+			Path:    filepath.Join(dir, entry.Name()),
+			Size:    entry.Size(),
+		})
+	}
+
+	sort.SliceStable(providers, func(i, j int) bool {
+		if providers[i].Key != providers[j].Key {
+			return providers[i].Key < providers[j].Key
+		}
+		vi, erri := version.NewVersion(providers[i].Version)
+		vj, errj := version.NewVersion(providers[j].Version)
+		if erri != nil || errj != nil {
+			return providers[i].Version < providers[j].Version
+		}
+		return vi.LessThan(vj)
+	})
+
+	return providers, nil
+}
+
+// Remove deletes the provider binary from the plugins directory, along with the package hash recorded at install time
+func (p CachedProvider) Remove() error {
+	if err := os.Remove(p.Path); err != nil {
+		return err
+	}
+	if err := os.Remove(getInstalledPackagePath(p.Path)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package terraform
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCachedProviders(t *testing.T) {
+	homeDir := t.TempDir()
+
+	providers, err := ListCachedProviders(homeDir)
+	require.NoError(t, err)
+	assert.Empty(t, providers)
+
+	dir := GetProvidersDirectory(homeDir)
+	require.NoError(t, os.MkdirAll(path.Join(dir, "terraform-provider-google_v3.78.0"), 0755))
+	for name, content := range map[string]string{
+		"terraform-provider-aws_v3.47.0_x5":               "aws 3.47.0",
+		"terraform-provider-aws_v3.19.0_x4":               "aws",
+		"terraform-provider-aws_v3.9.0":                   "a",
+		"terraform-provider-github_v4.4.0":                "github",
+		"README.md":                                       "not a provider",
+		".terraform-provider-aws_v3.19.0_x4.package.json": "{}",
+	} {
+		require.NoError(t, os.WriteFile(path.Join(dir, name), []byte(content), 0755))
+	}
+
+	providers, err = ListCachedProviders(homeDir)
+	require.NoError(t, err)
+	assert.Equal(t, []CachedProvider{
+		{Key: "aws", Version: "3.9.0", Path: path.Join(dir, "terraform-provider-aws_v3.9.0"), Size: 1},
+		{Key: "aws", Version: "3.19.0", Path: path.Join(dir, "terraform-provider-aws_v3.19.0_x4"), Size: 3},
+		{Key: "aws", Version: "3.47.0", Path: path.Join(dir, "terraform-provider-aws_v3.47.0_x5"), Size: 10},
+		{Key: "github", Version: "4.4.0", Path: path.Join(dir, "terraform-provider-github_v4.4.0"), Size: 6},
+	}, providers)
+
+	require.NoError(t, providers[0].Remove())
+	_, err = os.Stat(providers[0].Path)
+	assert.True(t, os.IsNotExist(err))
+
+	require.NoError(t, providers[1].Remove())
+	assert.NoFileExists(t, getInstalledPackagePath(providers[1].Path))
+}
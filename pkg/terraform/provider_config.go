@@ -3,30 +3,90 @@ package terraform
 import (
 	"fmt"
 	"runtime"
+	"strings"
 )
 
-type ProviderConfig struct {
-	Key       string
-	Version   string
+const (
+	defaultProviderHostname  = "registry.terraform.io"
+	defaultProviderNamespace = "hashicorp"
+)
+
+// ProviderInstallOptions tells where to look for terraform providers before downloading them,
+// and how to verify them
+type ProviderInstallOptions struct {
 	ConfigDir string
+	// PluginCacheDir is a terraform plugin cache directory, TF_PLUGIN_CACHE_DIR is used when empty
+	PluginCacheDir string
+	// MirrorDir is a terraform filesystem mirror, using either the packed or the unpacked layout
+	MirrorDir string
+	// MirrorURL is a terraform network mirror, used instead of releases.hashicorp.com when set
+	MirrorURL string
+	// Registry is a provider registry host optionally followed by a namespace, e.g. registry.opentofu.org or
+	// registry.example.com/acme. Providers are downloaded from releases.hashicorp.com when empty.
+	Registry string
+	// Hashes are the provider hashes found in the terraform lock file, the provider must match one of them
+	Hashes []string
 }
 
-func (c *ProviderConfig) GetDownloadUrl() string {
-	arch := runtime.GOARCH
-	if runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
-		arch = "amd64"
+// ParseRegistry returns the hostname and namespace of a registry, as used in provider addresses.
+// The namespace defaults to hashicorp, and the hostname to registry.terraform.io.
+func ParseRegistry(registry string) (hostname, namespace string) {
+	hostname, namespace = defaultProviderHostname, defaultProviderNamespace
+	parts := strings.SplitN(strings.Trim(registry, "/"), "/", 2)
+	if parts[0] != "" {
+		hostname = strings.ToLower(parts[0])
+	}
+	if len(parts) == 2 && parts[1] != "" {
+		namespace = strings.ToLower(parts[1])
 	}
+	return hostname, namespace
+}
+
+type ProviderConfig struct {
+	Key     string
+	Version string
+	ProviderInstallOptions
+}
+
+func (c *ProviderConfig) GetDownloadUrl() string {
+	return fmt.Sprintf(
+		"https://releases.hashicorp.com/terraform-provider-%s/%s/%s",
+		c.Key,
+		c.Version,
+		c.GetArchiveName(),
+	)
+}
+
+// GetChecksumsUrl returns the URL of the SHA256SUMS file of the provider release, its signature URL is suffixed with .sig
+func (c *ProviderConfig) GetChecksumsUrl() string {
 	return fmt.Sprintf(
-		"https://releases.hashicorp.com/terraform-provider-%s/%s/terraform-provider-%s_%s_%s_%s.zip",
+		"https://releases.hashicorp.com/terraform-provider-%s/%s/terraform-provider-%s_%s_SHA256SUMS",
 		c.Key,
 		c.Version,
 		c.Key,
 		c.Version,
-		runtime.GOOS,
-		arch,
 	)
 }
 
+func (c *ProviderConfig) GetArchiveName() string {
+	return fmt.Sprintf("terraform-provider-%s_%s_%s.zip", c.Key, c.Version, c.GetPlatform())
+}
+
+// GetPlatform returns the os_arch string used in provider archives and mirrors
+func (c *ProviderConfig) GetPlatform() string {
+	arch := runtime.GOARCH
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
+		arch = "amd64"
+	}
+	return fmt.Sprintf("%s_%s", runtime.GOOS, arch)
+}
+
+// GetAddressPath returns the hostname/namespace/type path used to store the provider in mirrors and plugin caches
+func (c *ProviderConfig) GetAddressPath() string {
+	hostname, namespace := ParseRegistry(c.Registry)
+	return fmt.Sprintf("%s/%s/%s", hostname, namespace, c.Key)
+}
+
 func (c *ProviderConfig) GetBinaryName() string {
 	return fmt.Sprintf("terraform-provider-%s_v%s", c.Key, c.Version)
 }
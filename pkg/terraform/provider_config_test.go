@@ -86,3 +86,26 @@ func TestProviderConfig_GetDownloadUrl(t *testing.T) {
 		})
 	}
 }
+
+func TestProviderConfig_GetAddressPath(t *testing.T) {
+	tests := []struct {
+		registry string
+		want     string
+	}{
+		{registry: "", want: "registry.terraform.io/hashicorp/aws"},
+		{registry: "registry.opentofu.org", want: "registry.opentofu.org/hashicorp/aws"},
+		{registry: "Registry.Example.com/Acme/", want: "registry.example.com/acme/aws"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			c := &ProviderConfig{
+				Key:                    "aws",
+				Version:                "3.19.0",
+				ProviderInstallOptions: ProviderInstallOptions{Registry: tt.registry},
+			}
+			if got := c.GetAddressPath(); got != tt.want {
+				t.Errorf("GetAddressPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
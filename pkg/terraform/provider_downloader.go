@@ -13,7 +13,10 @@ import (
 )
 
 type ProviderDownloaderInterface interface {
-	Download(url, path string) error
+	// Download fetches the archive found at url, and decompresses it to path once verify accepted it
+	Download(url, path string, verify func(archivePath string) error) error
+	// Get returns the content of a small file, like a checksums list or a mirror index
+	Get(url string) ([]byte, error)
 }
 
 type ProviderDownloader struct {
@@ -30,27 +33,46 @@ func NewProviderDownloader() *ProviderDownloader {
 	}
 }
 
-func (p *ProviderDownloader) Download(url, path string) error {
-	logrus.WithFields(logrus.Fields{
-		"url":  url,
-		"path": path,
-	}).Debug("Downloading provider")
-
+func (p *ProviderDownloader) get(url string) (*http.Response, error) {
 	req, err := http.NewRequestWithContext(p.context, "GET", url, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	resp, err := p.httpclient.Do(req)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusForbidden {
-		return error2.ProviderNotFoundError{}
+		resp.Body.Close()
+		return nil, error2.ProviderNotFoundError{}
 	}
 	if resp.StatusCode != http.StatusOK {
-		return errors.Errorf("unsuccessful request to %s: %s", url, resp.Status)
+		resp.Body.Close()
+		return nil, errors.Errorf("unsuccessful request to %s: %s", url, resp.Status)
+	}
+	return resp, nil
+}
+
+func (p *ProviderDownloader) Get(url string) ([]byte, error) {
+	resp, err := p.get(url)
+	if err != nil {
+		return nil, err
 	}
+	defer resp.Body.Close()
+	return ioutil.ReadAll(resp.Body)
+}
+
+func (p *ProviderDownloader) Download(url, path string, verify func(archivePath string) error) error {
+	logrus.WithFields(logrus.Fields{
+		"url":  url,
+		"path": path,
+	}).Debug("Downloading provider")
+
+	resp, err := p.get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
 	f, err := ioutil.TempFile("", "terraform-provider")
 	if err != nil {
 		return errors.Errorf("failed to open temporary file to download from %s", url)
@@ -68,6 +90,11 @@ func (p *ProviderDownloader) Download(url, path string) error {
 	if err != nil {
 		return err
 	}
+	if verify != nil {
+		if err := verify(f.Name()); err != nil {
+			return err
+		}
+	}
 	logrus.WithFields(logrus.Fields{
 		"src": f.Name(),
 		"dst": path,
@@ -101,7 +101,7 @@ func TestProviderDownloader_Download(t *testing.T) {
 				}
 			}
 
-			err := downloader.Download(url, tmpDir)
+			err := downloader.Download(url, tmpDir, nil)
 
 			c.assert(assert, tmpDir, err)
 		})
@@ -1,14 +1,17 @@
 package terraform
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
+	"io/ioutil"
 	"os"
 	"path"
 	"path/filepath"
-	"runtime"
 	"strings"
 
+	"github.com/hashicorp/go-getter"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 	error2 "github.com/snyk/driftctl/pkg/terraform/error"
@@ -24,13 +27,18 @@ type ProviderInstaller struct {
 	downloader ProviderDownloaderInterface
 	config     ProviderConfig
 	homeDir    string
+	// signingKey is the armored PGP key used to verify releases downloaded from releases.hashicorp.com
+	signingKey string
+	// packageHash is the lock file hash the package being installed was verified against
+	packageHash string
 }
 
 func NewProviderInstaller(config ProviderConfig) (*ProviderInstaller, error) {
 	return &ProviderInstaller{
-		NewProviderDownloader(),
-		config,
-		config.ConfigDir,
+		downloader: NewProviderDownloader(),
+		config:     config,
+		homeDir:    config.ConfigDir,
+		signingKey: hashicorpPublicKey,
 	}, nil
 }
 
@@ -43,12 +51,8 @@ func (p *ProviderInstaller) Install() (string, error) {
 	if err != nil && os.IsNotExist(err) {
 		logrus.WithFields(logrus.Fields{
 			"path": providerPath,
-		}).Debug("provider not found, downloading ...")
-		output.Printf("Downloading terraform provider: %s\n", p.config.Key)
-		err := p.downloader.Download(
-			p.config.GetDownloadUrl(),
-			providerDir,
-		)
+		}).Debug("provider not found, installing ...")
+		err := p.install(providerDir)
 		if err != nil {
 			if notFoundErr, ok := err.(error2.ProviderNotFoundError); ok {
 				notFoundErr.Version = p.config.Version
@@ -56,7 +60,12 @@ func (p *ProviderInstaller) Install() (string, error) {
 			}
 			return "", err
 		}
-		logrus.Debug("Download successful")
+		if p.packageHash != "" {
+			if err := recordInstalledPackage(p.getBinaryPath(), p.packageHash); err != nil {
+				return "", err
+			}
+		}
+		logrus.Debug("Install successful")
 	}
 
 	if info != nil && info.IsDir() {
@@ -70,13 +79,158 @@ func (p *ProviderInstaller) Install() (string, error) {
 		logrus.WithFields(logrus.Fields{
 			"path": providerPath,
 		}).Debug("Found existing provider")
+		// Providers installed by a previous run, or copied by hand, are checked against the lock file as well
+		if err := verifyInstalledProvider(providerPath, p.config.Hashes); err != nil {
+			return "", errors.Wrap(err, "installed provider does not match the lock file, remove it to install it again")
+		}
 	}
 
 	return p.getBinaryPath(), nil
 }
 
+// install looks for the provider in the plugin cache and the filesystem mirror,
+// then downloads it from the network mirror, the provider registry or releases.hashicorp.com
+func (p *ProviderInstaller) install(providerDir string) error {
+	p.packageHash = ""
+	pluginCacheDir := p.config.PluginCacheDir
+	if pluginCacheDir == "" {
+		pluginCacheDir = os.Getenv("TF_PLUGIN_CACHE_DIR")
+	}
+	for _, dir := range []string{pluginCacheDir, p.config.MirrorDir} {
+		if dir == "" {
+			continue
+		}
+		found, err := p.installFromDirectory(dir, providerDir)
+		if err != nil || found {
+			return err
+		}
+	}
+
+	output.Printf("Downloading terraform provider: %s\n", p.config.Key)
+	if p.config.MirrorURL != "" {
+		return p.installFromNetworkMirror(providerDir)
+	}
+	if p.config.Registry != "" {
+		return p.installFromRegistry(providerDir)
+	}
+	return p.downloader.Download(p.config.GetDownloadUrl(), providerDir, p.verifyRelease)
+}
+
+// installFromDirectory installs the provider from a plugin cache or a filesystem mirror,
+// it returns false if the provider was not found in the given directory
+func (p *ProviderInstaller) installFromDirectory(dir, providerDir string) (bool, error) {
+	unpackedDir := filepath.Join(dir, p.config.GetAddressPath(), p.config.Version, p.config.GetPlatform())
+	if info, err := os.Stat(unpackedDir); err == nil && info.IsDir() {
+		logrus.WithFields(logrus.Fields{
+			"path": unpackedDir,
+		}).Debug("Installing provider from local directory")
+		hash, err := verifyDirectoryHashes(unpackedDir, p.config.Hashes)
+		if err != nil {
+			return false, err
+		}
+		p.packageHash = hash
+		return true, p.copyBinaries(unpackedDir, providerDir)
+	}
+
+	archive := filepath.Join(dir, p.config.GetAddressPath(), p.config.GetArchiveName())
+	if info, err := os.Stat(archive); err == nil && !info.IsDir() {
+		logrus.WithFields(logrus.Fields{
+			"path": archive,
+		}).Debug("Installing provider from local archive")
+		if err := p.verifyLockHashes(p.config.GetArchiveName(), archive); err != nil {
+			return false, err
+		}
+		unzip := getter.ZipDecompressor{}
+		return true, unzip.Decompress(providerDir, archive, true, 0)
+	}
+
+	return false, nil
+}
+
+func (p *ProviderInstaller) copyBinaries(srcDir, providerDir string) error {
+	entries, err := ioutil.ReadDir(srcDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(providerDir, 0755); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), p.config.GetBinaryName()) {
+			continue
+		}
+		if err := copyFile(filepath.Join(srcDir, entry.Name()), filepath.Join(providerDir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// networkMirrorIndex is the list of archives of a provider version, as described by the provider network mirror protocol
+type networkMirrorIndex struct {
+	Archives map[string]struct {
+		Url    string   `json:"url"`
+		Hashes []string `json:"hashes"`
+	} `json:"archives"`
+}
+
+func (p *ProviderInstaller) installFromNetworkMirror(providerDir string) error {
+	indexUrl := fmt.Sprintf("%s/%s/%s.json", strings.TrimSuffix(p.config.MirrorURL, "/"), p.config.GetAddressPath(), p.config.Version)
+	content, err := p.downloader.Get(indexUrl)
+	if err != nil {
+		return err
+	}
+	var index networkMirrorIndex
+	if err := json.Unmarshal(content, &index); err != nil {
+		return errors.Wrapf(err, "unable to parse network mirror response from %s", indexUrl)
+	}
+	archive, exist := index.Archives[p.config.GetPlatform()]
+	if !exist {
+		return error2.ProviderNotFoundError{}
+	}
+
+	archiveUrl, err := resolveUrl(indexUrl, archive.Url)
+	if err != nil {
+		return err
+	}
+
+	return p.downloader.Download(archiveUrl, providerDir, func(archivePath string) error {
+		if _, err := verifyArchiveHashes(p.config.GetArchiveName(), archivePath, archive.Hashes); err != nil {
+			return err
+		}
+		return p.verifyLockHashes(p.config.GetArchiveName(), archivePath)
+	})
+}
+
+// verifyRelease checks an archive downloaded from releases.hashicorp.com against the signed checksums of the release
+func (p *ProviderInstaller) verifyRelease(archivePath string) error {
+	sums, err := p.downloader.Get(p.config.GetChecksumsUrl())
+	if err != nil {
+		return err
+	}
+	signature, err := p.downloader.Get(fmt.Sprintf("%s.%s.sig", p.config.GetChecksumsUrl(), hashicorpKeyID))
+	if err != nil {
+		return err
+	}
+	if err := verifyChecksums(sums, signature, p.signingKey, p.config.GetArchiveName(), archivePath); err != nil {
+		return err
+	}
+	return p.verifyLockHashes(p.config.GetArchiveName(), archivePath)
+}
+
+// verifyLockHashes checks an archive against the hashes of the lock file and keeps the matching hash,
+// so that it can be recorded once the provider is installed
+func (p *ProviderInstaller) verifyLockHashes(archiveName, archivePath string) error {
+	hash, err := verifyArchiveHashes(archiveName, archivePath, p.config.Hashes)
+	if err != nil {
+		return err
+	}
+	p.packageHash = hash
+	return nil
+}
+
 func (p ProviderInstaller) getProviderDirectory() string {
-	return path.Join(p.homeDir, fmt.Sprintf(".driftctl/plugins/%s_%s/", runtime.GOOS, runtime.GOARCH))
+	return GetProvidersDirectory(p.homeDir)
 }
 
 // Handle postfixes in binary names
@@ -95,3 +249,20 @@ func (p *ProviderInstaller) getBinaryPath() string {
 
 	return path.Join(providerDir, binaryName)
 }
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
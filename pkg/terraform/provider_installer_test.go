@@ -1,15 +1,19 @@
 package terraform
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/snyk/driftctl/mocks"
 	terraformError "github.com/snyk/driftctl/pkg/terraform/error"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/mod/sumdb/dirhash"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -27,7 +31,7 @@ func TestProviderInstallerInstallDoesNotExist(t *testing.T) {
 	}
 
 	mockDownloader := mocks.ProviderDownloaderInterface{}
-	mockDownloader.On("Download", config.GetDownloadUrl(), path.Join(fakeTmpHome, expectedSubFolder)).Return(nil)
+	mockDownloader.On("Download", config.GetDownloadUrl(), path.Join(fakeTmpHome, expectedSubFolder), mock.Anything).Return(nil)
 
 	installer := ProviderInstaller{
 		downloader: &mockDownloader,
@@ -79,6 +83,52 @@ func TestProviderInstallerInstallAlreadyExist(t *testing.T) {
 
 }
 
+func TestProviderInstallerInstallAlreadyExistWithLockHashes(t *testing.T) {
+	fakeTmpHome := t.TempDir()
+
+	config := ProviderConfig{
+		Key:     "aws",
+		Version: "3.19.0",
+	}
+	// zh: hashes of archives can't be checked against an installed binary
+	config.Hashes = []string{"zh:0123456789abcdef"}
+
+	mockDownloader := mocks.ProviderDownloaderInterface{}
+	installer := ProviderInstaller{
+		downloader: &mockDownloader,
+		config:     config,
+		homeDir:    fakeTmpHome,
+	}
+
+	binaryPath := path.Join(installer.getProviderDirectory(), config.GetBinaryName()+"_x5")
+	if err := os.MkdirAll(installer.getProviderDirectory(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(binaryPath, []byte("provider binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	h1, err := dirhash.Hash1([]string{config.GetBinaryName() + "_x5"}, func(string) (io.ReadCloser, error) {
+		return os.Open(binaryPath)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	providerPath, err := installer.Install()
+	assert.Nil(t, err)
+	assert.Equal(t, binaryPath, providerPath)
+
+	installer.config.Hashes = []string{"h1:invalid", h1}
+	providerPath, err = installer.Install()
+	assert.Nil(t, err)
+	assert.Equal(t, binaryPath, providerPath)
+
+	installer.config.Hashes = []string{"h1:invalid", "zh:0123456789abcdef"}
+	_, err = installer.Install()
+	assert.EqualError(t, err, fmt.Sprintf("installed provider does not match the lock file, remove it to install it again: provider package %s does not match any of the expected hashes", binaryPath))
+	mockDownloader.AssertExpectations(t)
+}
+
 func TestProviderInstallerInstallAlreadyExistButIsDirectory(t *testing.T) {
 
 	assert := assert.New(t)
@@ -166,7 +216,7 @@ func TestProviderInstallerVersionDoesNotExist(t *testing.T) {
 	}
 
 	mockDownloader := mocks.ProviderDownloaderInterface{}
-	mockDownloader.On("Download", mock.Anything, mock.Anything).Return(terraformError.ProviderNotFoundError{})
+	mockDownloader.On("Download", mock.Anything, mock.Anything, mock.Anything).Return(terraformError.ProviderNotFoundError{})
 
 	installer := ProviderInstaller{
 		downloader: &mockDownloader,
@@ -186,13 +236,13 @@ func TestProviderInstallerWithConfigDirectory(t *testing.T) {
 	expectedSubFolder := fmt.Sprintf("/.driftctl/plugins/%s_%s", runtime.GOOS, runtime.GOARCH)
 
 	config := ProviderConfig{
-		Key:       "aws",
-		Version:   "3.19.0",
-		ConfigDir: fakeTmpHome,
+		Key:                    "aws",
+		Version:                "3.19.0",
+		ProviderInstallOptions: ProviderInstallOptions{ConfigDir: fakeTmpHome},
 	}
 
 	mockDownloader := mocks.ProviderDownloaderInterface{}
-	mockDownloader.On("Download", config.GetDownloadUrl(), path.Join(fakeTmpHome, expectedSubFolder)).Return(nil)
+	mockDownloader.On("Download", config.GetDownloadUrl(), path.Join(fakeTmpHome, expectedSubFolder), mock.Anything).Return(nil)
 
 	installer, _ := NewProviderInstaller(config)
 	installer.downloader = &mockDownloader
@@ -204,3 +254,307 @@ func TestProviderInstallerWithConfigDirectory(t *testing.T) {
 	assert.Equal(path.Join(fakeTmpHome, expectedSubFolder, config.GetBinaryName()), providerPath)
 
 }
+
+func TestProviderInstallerInstallFromPluginCache(t *testing.T) {
+	fakeTmpHome := t.TempDir()
+	cacheDir := t.TempDir()
+	t.Setenv("TF_PLUGIN_CACHE_DIR", cacheDir)
+
+	config := ProviderConfig{
+		Key:     "aws",
+		Version: "3.19.0",
+	}
+	unpackedDir := path.Join(cacheDir, config.GetAddressPath(), config.Version, config.GetPlatform())
+	if err := os.MkdirAll(unpackedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(unpackedDir, config.GetBinaryName()+"_x5"), []byte("provider binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	mockDownloader := mocks.ProviderDownloaderInterface{}
+	installer := ProviderInstaller{
+		downloader: &mockDownloader,
+		config:     config,
+		homeDir:    fakeTmpHome,
+	}
+
+	providerPath, err := installer.Install()
+	mockDownloader.AssertExpectations(t)
+
+	assert.Nil(t, err)
+	assert.Equal(t, path.Join(installer.getProviderDirectory(), config.GetBinaryName()+"_x5"), providerPath)
+
+	installer.homeDir = t.TempDir()
+	installer.config.Hashes = []string{"h1:invalid"}
+	_, err = installer.Install()
+	assert.EqualError(t, err, fmt.Sprintf("provider package %s does not match any of the expected hashes", unpackedDir))
+}
+
+func TestProviderInstallerInstallFromPluginCacheWithMultipleFiles(t *testing.T) {
+	fakeTmpHome := t.TempDir()
+	cacheDir := t.TempDir()
+	t.Setenv("TF_PLUGIN_CACHE_DIR", cacheDir)
+
+	config := ProviderConfig{
+		Key:     "aws",
+		Version: "3.19.0",
+	}
+	unpackedDir := path.Join(cacheDir, config.GetAddressPath(), config.Version, config.GetPlatform())
+	if err := os.MkdirAll(unpackedDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(unpackedDir, config.GetBinaryName()+"_x5"), []byte("provider binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path.Join(unpackedDir, "README.md"), []byte("provider readme"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	h1, err := dirhash.HashDir(unpackedDir, "", dirhash.Hash1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Hashes = []string{h1}
+
+	mockDownloader := mocks.ProviderDownloaderInterface{}
+	installer := ProviderInstaller{
+		downloader: &mockDownloader,
+		config:     config,
+		homeDir:    fakeTmpHome,
+	}
+
+	providerPath, err := installer.Install()
+	assert.Nil(t, err)
+	assert.Equal(t, path.Join(installer.getProviderDirectory(), config.GetBinaryName()+"_x5"), providerPath)
+
+	// The installed binary alone can't match the hash of the package, the hash recorded at install time is used
+	providerPath, err = installer.Install()
+	assert.Nil(t, err)
+	assert.Equal(t, path.Join(installer.getProviderDirectory(), config.GetBinaryName()+"_x5"), providerPath)
+
+	installer.config.Hashes = []string{"h1:invalid"}
+	_, err = installer.Install()
+	assert.EqualError(t, err, fmt.Sprintf("installed provider does not match the lock file, remove it to install it again: provider package %s does not match any of the expected hashes", providerPath))
+
+	installer.config.Hashes = []string{h1}
+	if err := os.WriteFile(providerPath, []byte("modified provider binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	_, err = installer.Install()
+	assert.EqualError(t, err, fmt.Sprintf("installed provider does not match the lock file, remove it to install it again: provider binary %s changed since it was installed", providerPath))
+	mockDownloader.AssertExpectations(t)
+}
+
+func TestProviderInstallerInstallFromMirrorDirectory(t *testing.T) {
+	t.Setenv("TF_PLUGIN_CACHE_DIR", "")
+	fakeTmpHome := t.TempDir()
+	mirrorDir := t.TempDir()
+
+	config := ProviderConfig{
+		Key:                    "aws",
+		Version:                "3.19.0",
+		ProviderInstallOptions: ProviderInstallOptions{MirrorDir: mirrorDir},
+	}
+	archivePath := path.Join(mirrorDir, config.GetAddressPath(), config.GetArchiveName())
+	writeProviderArchive(t, archivePath, config.GetBinaryName()+"_x5")
+	zh, err := sha256File(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config.Hashes = []string{"zh:" + zh}
+
+	mockDownloader := mocks.ProviderDownloaderInterface{}
+	installer := ProviderInstaller{
+		downloader: &mockDownloader,
+		config:     config,
+		homeDir:    fakeTmpHome,
+	}
+
+	providerPath, err := installer.Install()
+	mockDownloader.AssertExpectations(t)
+
+	assert.Nil(t, err)
+	assert.Equal(t, path.Join(installer.getProviderDirectory(), config.GetBinaryName()+"_x5"), providerPath)
+}
+
+func TestProviderInstallerInstallFromNetworkMirror(t *testing.T) {
+	t.Setenv("TF_PLUGIN_CACHE_DIR", "")
+	fakeTmpHome := t.TempDir()
+
+	config := ProviderConfig{
+		Key:     "aws",
+		Version: "3.19.0",
+		ProviderInstallOptions: ProviderInstallOptions{
+			MirrorURL: "https://mirror.example.com/providers/",
+			Hashes:    []string{"zh:locked"},
+		},
+	}
+	indexUrl := "https://mirror.example.com/providers/registry.terraform.io/hashicorp/aws/3.19.0.json"
+	index := fmt.Sprintf(`{"archives":{"%s":{"url":"%s","hashes":["zh:mirrored"]}}}`, config.GetPlatform(), config.GetArchiveName())
+
+	mockDownloader := mocks.ProviderDownloaderInterface{}
+	mockDownloader.On("Get", indexUrl).Return([]byte(index), nil)
+	mockDownloader.On(
+		"Download",
+		"https://mirror.example.com/providers/registry.terraform.io/hashicorp/aws/"+config.GetArchiveName(),
+		path.Join(fakeTmpHome, fmt.Sprintf("/.driftctl/plugins/%s_%s", runtime.GOOS, runtime.GOARCH)),
+		mock.Anything,
+	).Return(nil)
+
+	installer := ProviderInstaller{
+		downloader: &mockDownloader,
+		config:     config,
+		homeDir:    fakeTmpHome,
+	}
+
+	_, err := installer.Install()
+	mockDownloader.AssertExpectations(t)
+	assert.Nil(t, err)
+
+	mockDownloader = mocks.ProviderDownloaderInterface{}
+	mockDownloader.On("Get", indexUrl).Return([]byte(`{"archives":{}}`), nil)
+	installer.downloader = &mockDownloader
+
+	_, err = installer.Install()
+	mockDownloader.AssertExpectations(t)
+	assert.Equal(t, "Provider version 3.19.0 does not exist", err.Error())
+}
+
+func TestProviderInstallerInstallFromRegistry(t *testing.T) {
+	t.Setenv("TF_PLUGIN_CACHE_DIR", "")
+	fakeTmpHome := t.TempDir()
+
+	config := ProviderConfig{
+		Key:                    "aws",
+		Version:                "3.19.0",
+		ProviderInstallOptions: ProviderInstallOptions{Registry: "registry.example.com/acme"},
+	}
+	archivePath := path.Join(t.TempDir(), config.GetArchiveName())
+	writeProviderArchive(t, archivePath, config.GetBinaryName())
+	sum, err := sha256File(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sums := []byte(fmt.Sprintf("%s  %s\n", sum, config.GetArchiveName()))
+	key, signature := signChecksums(t, sums)
+	otherKey, _ := signChecksums(t, sums)
+
+	platform := strings.SplitN(config.GetPlatform(), "_", 2)
+	packageUrl := fmt.Sprintf("https://registry.example.com/v1/providers/acme/aws/3.19.0/download/%s/%s", platform[0], platform[1])
+	providerPackage, _ := json.Marshal(map[string]interface{}{
+		"filename":              config.GetArchiveName(),
+		"download_url":          "https://github.com/acme/terraform-provider-aws/releases/download/v3.19.0/" + config.GetArchiveName(),
+		"shasums_url":           "SHA256SUMS",
+		"shasums_signature_url": "SHA256SUMS.sig",
+		"shasum":                sum,
+		"signing_keys": map[string]interface{}{
+			"gpg_public_keys": []map[string]string{
+				{"key_id": "other", "ascii_armor": otherKey},
+				{"key_id": "acme", "ascii_armor": key},
+			},
+		},
+	})
+
+	var verifyErr error
+	mockDownloader := mocks.ProviderDownloaderInterface{}
+	mockDownloader.On("Get", "https://registry.example.com/.well-known/terraform.json").Return([]byte(`{"providers.v1":"/v1/providers/"}`), nil)
+	mockDownloader.On("Get", packageUrl).Return(providerPackage, nil)
+	mockDownloader.On("Get", fmt.Sprintf("https://registry.example.com/v1/providers/acme/aws/3.19.0/download/%s/SHA256SUMS", platform[0])).Return(sums, nil)
+	mockDownloader.On("Get", fmt.Sprintf("https://registry.example.com/v1/providers/acme/aws/3.19.0/download/%s/SHA256SUMS.sig", platform[0])).Return(signature, nil)
+	mockDownloader.On(
+		"Download",
+		"https://github.com/acme/terraform-provider-aws/releases/download/v3.19.0/"+config.GetArchiveName(),
+		path.Join(fakeTmpHome, fmt.Sprintf("/.driftctl/plugins/%s_%s", runtime.GOOS, runtime.GOARCH)),
+		mock.Anything,
+	).Run(func(args mock.Arguments) {
+		verifyErr = args.Get(2).(func(string) error)(archivePath)
+	}).Return(nil)
+
+	installer := ProviderInstaller{
+		downloader: &mockDownloader,
+		config:     config,
+		homeDir:    fakeTmpHome,
+	}
+
+	_, err = installer.Install()
+	mockDownloader.AssertExpectations(t)
+	assert.Nil(t, err)
+	assert.Nil(t, verifyErr)
+
+	installer.config.Hashes = []string{"zh:locked"}
+	_, err = installer.Install()
+	assert.Nil(t, err)
+	assert.EqualError(t, verifyErr, fmt.Sprintf("provider package %s does not match any of the expected hashes", config.GetArchiveName()))
+}
+
+func TestProviderInstallerInstallFromRegistryWithoutSigningKeys(t *testing.T) {
+	t.Setenv("TF_PLUGIN_CACHE_DIR", "")
+	fakeTmpHome := t.TempDir()
+
+	config := ProviderConfig{
+		Key:                    "aws",
+		Version:                "3.19.0",
+		ProviderInstallOptions: ProviderInstallOptions{Registry: "registry.example.com/acme"},
+	}
+	archivePath := path.Join(t.TempDir(), config.GetArchiveName())
+	writeProviderArchive(t, archivePath, config.GetBinaryName())
+	sum, err := sha256File(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	platform := strings.SplitN(config.GetPlatform(), "_", 2)
+	packageUrl := fmt.Sprintf("https://registry.example.com/v1/providers/acme/aws/3.19.0/download/%s/%s", platform[0], platform[1])
+	providerPackage, _ := json.Marshal(map[string]interface{}{
+		"filename":     config.GetArchiveName(),
+		"download_url": "https://github.com/acme/terraform-provider-aws/releases/download/v3.19.0/" + config.GetArchiveName(),
+		"shasum":       sum,
+	})
+
+	var verifyErr error
+	mockDownloader := mocks.ProviderDownloaderInterface{}
+	mockDownloader.On("Get", "https://registry.example.com/.well-known/terraform.json").Return([]byte(`{"providers.v1":"/v1/providers/"}`), nil)
+	mockDownloader.On("Get", packageUrl).Return(providerPackage, nil)
+	mockDownloader.On(
+		"Download",
+		"https://github.com/acme/terraform-provider-aws/releases/download/v3.19.0/"+config.GetArchiveName(),
+		path.Join(fakeTmpHome, fmt.Sprintf("/.driftctl/plugins/%s_%s", runtime.GOOS, runtime.GOARCH)),
+		mock.Anything,
+	).Run(func(args mock.Arguments) {
+		verifyErr = args.Get(2).(func(string) error)(archivePath)
+		if verifyErr == nil {
+			if err := os.MkdirAll(args.String(1), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path.Join(args.String(1), config.GetBinaryName()), []byte("provider binary"), 0755); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}).Return(nil)
+
+	installer := ProviderInstaller{
+		downloader: &mockDownloader,
+		config:     config,
+		homeDir:    fakeTmpHome,
+	}
+
+	_, err = installer.Install()
+	mockDownloader.AssertExpectations(t)
+	assert.Nil(t, err)
+	assert.EqualError(t, verifyErr, "provider registry did not return any signing key for registry.example.com/acme/aws@3.19.0, use a terraform lock file holding the provider hashes to verify it")
+
+	installer.config.Hashes = []string{"zh:" + sum}
+	providerPath, err := installer.Install()
+	assert.Nil(t, err)
+	assert.Nil(t, verifyErr)
+	// The verified package hash is recorded, so the installed provider is accepted on the next runs
+	_, err = installer.Install()
+	assert.Nil(t, err)
+	assert.Nil(t, os.RemoveAll(installer.getProviderDirectory()))
+	assert.NoFileExists(t, providerPath)
+
+	installer.config.Hashes = []string{"zh:locked"}
+	_, err = installer.Install()
+	assert.Nil(t, err)
+	assert.EqualError(t, verifyErr, fmt.Sprintf("provider package %s does not match any of the expected hashes", config.GetArchiveName()))
+}
@@ -0,0 +1,139 @@
+package terraform
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// registryDiscovery is the service discovery document of a registry host, served at /.well-known/terraform.json
+type registryDiscovery struct {
+	ProvidersV1 string `json:"providers.v1"`
+}
+
+// registryPackage is the download response of the provider registry protocol
+type registryPackage struct {
+	Filename            string `json:"filename"`
+	DownloadURL         string `json:"download_url"`
+	ShasumsURL          string `json:"shasums_url"`
+	ShasumsSignatureURL string `json:"shasums_signature_url"`
+	Shasum              string `json:"shasum"`
+	SigningKeys         struct {
+		GPGPublicKeys []struct {
+			KeyID      string `json:"key_id"`
+			ASCIIArmor string `json:"ascii_armor"`
+		} `json:"gpg_public_keys"`
+	} `json:"signing_keys"`
+}
+
+// installFromRegistry downloads the provider using the provider registry protocol,
+// the archive is verified against the signing keys returned by the registry
+func (p *ProviderInstaller) installFromRegistry(providerDir string) error {
+	hostname, namespace := ParseRegistry(p.config.Registry)
+
+	discoveryUrl := fmt.Sprintf("https://%s/.well-known/terraform.json", hostname)
+	content, err := p.downloader.Get(discoveryUrl)
+	if err != nil {
+		return errors.Wrapf(err, "unable to discover provider registry %s", hostname)
+	}
+	var discovery registryDiscovery
+	if err := json.Unmarshal(content, &discovery); err != nil || discovery.ProvidersV1 == "" {
+		return errors.Errorf("%s does not support the provider registry protocol", hostname)
+	}
+	providersUrl, err := resolveUrl(discoveryUrl, strings.TrimSuffix(discovery.ProvidersV1, "/")+"/")
+	if err != nil {
+		return err
+	}
+
+	platform := strings.SplitN(p.config.GetPlatform(), "_", 2)
+	packageUrl, err := resolveUrl(providersUrl, fmt.Sprintf("%s/%s/%s/download/%s/%s", namespace, p.config.Key, p.config.Version, platform[0], platform[1]))
+	if err != nil {
+		return err
+	}
+	content, err = p.downloader.Get(packageUrl)
+	if err != nil {
+		return err
+	}
+	var providerPackage registryPackage
+	if err := json.Unmarshal(content, &providerPackage); err != nil {
+		return errors.Wrapf(err, "unable to parse provider registry response from %s", packageUrl)
+	}
+
+	archiveUrl, err := resolveUrl(packageUrl, providerPackage.DownloadURL)
+	if err != nil {
+		return err
+	}
+	return p.downloader.Download(archiveUrl, providerDir, func(archivePath string) error {
+		if err := p.verifyRegistryPackage(packageUrl, providerPackage, archivePath); err != nil {
+			return err
+		}
+		return p.verifyLockHashes(providerPackage.Filename, archivePath)
+	})
+}
+
+func (p *ProviderInstaller) verifyRegistryPackage(packageUrl string, providerPackage registryPackage, archivePath string) error {
+	actual, err := sha256File(archivePath)
+	if err != nil {
+		return err
+	}
+	if actual != providerPackage.Shasum {
+		return errors.Errorf("checksum of %s does not match the checksum returned by the registry", providerPackage.Filename)
+	}
+
+	// Without signing keys, only the hashes of the lock file tell the archive can be trusted, they are checked
+	// once the registry package is verified
+	if len(providerPackage.SigningKeys.GPGPublicKeys) == 0 {
+		if len(p.config.Hashes) == 0 {
+			return errors.Errorf(
+				"provider registry did not return any signing key for %s@%s, use a terraform lock file holding the provider hashes to verify it",
+				p.config.GetAddressPath(), p.config.Version,
+			)
+		}
+		logrus.WithFields(logrus.Fields{
+			"provider": p.config.GetAddressPath(),
+			"version":  p.config.Version,
+		}).Debug("Provider registry did not return any signing key, verifying the provider with the lock file hashes")
+		return nil
+	}
+
+	sumsUrl, err := resolveUrl(packageUrl, providerPackage.ShasumsURL)
+	if err != nil {
+		return err
+	}
+	sums, err := p.downloader.Get(sumsUrl)
+	if err != nil {
+		return err
+	}
+	signatureUrl, err := resolveUrl(packageUrl, providerPackage.ShasumsSignatureURL)
+	if err != nil {
+		return err
+	}
+	signature, err := p.downloader.Get(signatureUrl)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range providerPackage.SigningKeys.GPGPublicKeys {
+		err = verifyChecksums(sums, signature, key.ASCIIArmor, providerPackage.Filename, archivePath)
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func resolveUrl(base, ref string) (string, error) {
+	baseUrl, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	refUrl, err := baseUrl.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	return refUrl.String(), nil
+}
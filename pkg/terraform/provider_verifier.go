@@ -0,0 +1,189 @@
+package terraform
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// hashicorpKeyID is the ID of hashicorpPublicKey, used to find the matching signature of a release
+const hashicorpKeyID = "72D7468F"
+
+// verifyChecksums checks the signature of a SHA256SUMS file, then checks that it contains the checksum of the archive
+func verifyChecksums(sums, signature []byte, armoredKey, archiveName, archivePath string) error {
+	keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armoredKey))
+	if err != nil {
+		return errors.Wrap(err, "unable to read signing key")
+	}
+	if _, err := openpgp.CheckDetachedSignature(keyring, bytes.NewReader(sums), bytes.NewReader(signature)); err != nil {
+		return errors.Wrapf(err, "invalid signature for the checksums of %s", archiveName)
+	}
+
+	expected := ""
+	scanner := bufio.NewScanner(bytes.NewReader(sums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == archiveName {
+			expected = fields[0]
+			break
+		}
+	}
+	if expected == "" {
+		return errors.Errorf("no checksum found for %s", archiveName)
+	}
+
+	actual, err := sha256File(archivePath)
+	if err != nil {
+		return err
+	}
+	if actual != expected {
+		return errors.Errorf("checksum of %s does not match the signed checksum", archiveName)
+	}
+	return nil
+}
+
+// verifyArchiveHashes checks that a provider archive matches one of the given zh: or h1: hashes, as found in
+// terraform lock files and network mirrors, and returns the matching hash. Any archive is accepted when there is no hash.
+func verifyArchiveHashes(archiveName, archivePath string, hashes []string) (string, error) {
+	if len(hashes) == 0 {
+		return "", nil
+	}
+	zh, err := sha256File(archivePath)
+	if err != nil {
+		return "", err
+	}
+	h1, err := dirhash.HashZip(archivePath, dirhash.Hash1)
+	if err != nil {
+		return "", err
+	}
+	return matchHashes(archiveName, hashes, "zh:"+zh, h1)
+}
+
+// verifyDirectoryHashes checks that an unpacked provider package matches one of the given h1: hashes
+// and returns the matching hash
+func verifyDirectoryHashes(dir string, hashes []string) (string, error) {
+	if len(hashes) == 0 {
+		return "", nil
+	}
+	h1, err := dirhash.HashDir(dir, "", dirhash.Hash1)
+	if err != nil {
+		return "", err
+	}
+	return matchHashes(dir, hashes, h1)
+}
+
+// installedPackage records the package hash a provider was verified against when it was installed,
+// along with the checksum of the installed binary
+type installedPackage struct {
+	PackageHash  string `json:"package_hash"`
+	BinarySHA256 string `json:"binary_sha256"`
+}
+
+// getInstalledPackagePath returns the file recording the verified package of an installed provider binary.
+// It starts with a dot, so it is never mistaken for a provider binary.
+func getInstalledPackagePath(binaryPath string) string {
+	return filepath.Join(filepath.Dir(binaryPath), "."+filepath.Base(binaryPath)+".package.json")
+}
+
+// recordInstalledPackage saves the package hash a freshly installed provider binary was verified against
+func recordInstalledPackage(binaryPath, packageHash string) error {
+	sum, err := sha256File(binaryPath)
+	if err != nil {
+		return err
+	}
+	content, err := json.Marshal(installedPackage{PackageHash: packageHash, BinarySHA256: sum})
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(getInstalledPackagePath(binaryPath), content, 0644)
+}
+
+// verifyInstalledProvider checks that an installed provider binary matches one of the given hashes.
+// Providers installed by driftctl are checked against the package hash recorded at install time, the binary
+// must not have changed since then. Other providers are checked with verifyBinaryHashes.
+func verifyInstalledProvider(binaryPath string, hashes []string) error {
+	if len(hashes) == 0 {
+		return nil
+	}
+	content, err := ioutil.ReadFile(getInstalledPackagePath(binaryPath))
+	if os.IsNotExist(err) {
+		return verifyBinaryHashes(binaryPath, hashes)
+	}
+	if err != nil {
+		return err
+	}
+	var installed installedPackage
+	if err := json.Unmarshal(content, &installed); err != nil {
+		return errors.Wrapf(err, "unable to read the installed package of %s", binaryPath)
+	}
+	if _, err := matchHashes(binaryPath, hashes, installed.PackageHash); err != nil {
+		return err
+	}
+	sum, err := sha256File(binaryPath)
+	if err != nil {
+		return err
+	}
+	if sum != installed.BinarySHA256 {
+		return errors.Errorf("provider binary %s changed since it was installed", binaryPath)
+	}
+	return nil
+}
+
+// verifyBinaryHashes checks that an installed provider binary matches one of the given h1: hashes.
+// The h1 hash covers every file of a provider package, so installed providers can only match the hash of packages
+// made of the binary alone, as the ones published by HashiCorp. Nothing is checked when there is no h1: hash.
+func verifyBinaryHashes(binaryPath string, hashes []string) error {
+	h1Hashes := make([]string, 0, len(hashes))
+	for _, hash := range hashes {
+		if strings.HasPrefix(hash, "h1:") {
+			h1Hashes = append(h1Hashes, hash)
+		}
+	}
+	if len(h1Hashes) == 0 {
+		return nil
+	}
+	h1, err := dirhash.Hash1([]string{filepath.Base(binaryPath)}, func(string) (io.ReadCloser, error) {
+		return os.Open(binaryPath)
+	})
+	if err != nil {
+		return err
+	}
+	_, err = matchHashes(binaryPath, h1Hashes, h1)
+	return err
+}
+
+// matchHashes returns the first expected hash equal to one of the actual ones
+func matchHashes(name string, hashes []string, actual ...string) (string, error) {
+	for _, hash := range hashes {
+		for _, a := range actual {
+			if hash == a {
+				return hash, nil
+			}
+		}
+	}
+	return "", errors.Errorf("provider package %s does not match any of the expected hashes", name)
+}
+
+func sha256File(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
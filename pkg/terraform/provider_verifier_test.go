@@ -0,0 +1,92 @@
+package terraform
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+func writeProviderArchive(t *testing.T, archivePath, binaryName string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(archivePath), 0755))
+	f, err := os.Create(archivePath)
+	require.NoError(t, err)
+	defer f.Close()
+	w := zip.NewWriter(f)
+	entry, err := w.Create(binaryName)
+	require.NoError(t, err)
+	_, err = entry.Write([]byte("provider binary"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+}
+
+func signChecksums(t *testing.T, sums []byte) (string, []byte) {
+	entity, err := openpgp.NewEntity("test", "", "test@example.com", nil)
+	require.NoError(t, err)
+
+	key := &bytes.Buffer{}
+	w, err := armor.Encode(key, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+
+	signature := &bytes.Buffer{}
+	require.NoError(t, openpgp.DetachSign(signature, entity, bytes.NewReader(sums), nil))
+	return key.String(), signature.Bytes()
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "provider.zip")
+	writeProviderArchive(t, archivePath, "terraform-provider-aws_v3.19.0_x5")
+	sum, err := sha256File(archivePath)
+	require.NoError(t, err)
+
+	sums := []byte(fmt.Sprintf("%s  terraform-provider-aws_3.19.0_linux_amd64.zip\n", sum))
+	key, signature := signChecksums(t, sums)
+
+	assert.NoError(t, verifyChecksums(sums, signature, key, "terraform-provider-aws_3.19.0_linux_amd64.zip", archivePath))
+
+	err = verifyChecksums(sums, signature, key, "terraform-provider-aws_3.19.0_darwin_amd64.zip", archivePath)
+	assert.EqualError(t, err, "no checksum found for terraform-provider-aws_3.19.0_darwin_amd64.zip")
+
+	tampered := []byte(fmt.Sprintf("%s  terraform-provider-aws_3.19.0_linux_amd64.zip\n", "00"+sum[2:]))
+	err = verifyChecksums(tampered, signature, key, "terraform-provider-aws_3.19.0_linux_amd64.zip", archivePath)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid signature for the checksums of terraform-provider-aws_3.19.0_linux_amd64.zip")
+
+	otherKey, _ := signChecksums(t, sums)
+	err = verifyChecksums(sums, signature, otherKey, "terraform-provider-aws_3.19.0_linux_amd64.zip", archivePath)
+	assert.Error(t, err)
+}
+
+func TestVerifyArchiveHashes(t *testing.T) {
+	archivePath := filepath.Join(t.TempDir(), "provider.zip")
+	writeProviderArchive(t, archivePath, "terraform-provider-aws_v3.19.0_x5")
+	zh, err := sha256File(archivePath)
+	require.NoError(t, err)
+	h1, err := dirhash.HashZip(archivePath, dirhash.Hash1)
+	require.NoError(t, err)
+
+	hash, err := verifyArchiveHashes("provider.zip", archivePath, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "", hash)
+
+	hash, err = verifyArchiveHashes("provider.zip", archivePath, []string{"zh:" + zh})
+	assert.NoError(t, err)
+	assert.Equal(t, "zh:"+zh, hash)
+
+	hash, err = verifyArchiveHashes("provider.zip", archivePath, []string{"h1:invalid", h1})
+	assert.NoError(t, err)
+	assert.Equal(t, h1, hash)
+
+	_, err = verifyArchiveHashes("provider.zip", archivePath, []string{"h1:invalid", "zh:invalid"})
+	assert.EqualError(t, err, "provider package provider.zip does not match any of the expected hashes")
+}
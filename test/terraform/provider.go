@@ -5,6 +5,7 @@ import (
 
 	"github.com/snyk/driftctl/pkg/output"
 	"github.com/snyk/driftctl/pkg/remote/aws"
+	"github.com/snyk/driftctl/pkg/remote/aws/client"
 	"github.com/snyk/driftctl/pkg/remote/azurerm"
 	"github.com/snyk/driftctl/pkg/remote/github"
 	"github.com/snyk/driftctl/pkg/remote/google"
@@ -14,7 +15,7 @@ import (
 func InitTestAwsProvider(providerLibrary *terraform.ProviderLibrary, version string) (*aws.AWSTerraformProvider, error) {
 	progress := &output.MockProgress{}
 	progress.On("Inc").Maybe().Return()
-	provider, err := aws.NewAWSTerraformProvider(version, progress, os.TempDir())
+	provider, err := aws.NewAWSTerraformProvider(version, progress, terraform.ProviderInstallOptions{ConfigDir: os.TempDir()}, client.EndpointOptions{}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -25,7 +26,7 @@ func InitTestAwsProvider(providerLibrary *terraform.ProviderLibrary, version str
 func InitTestGithubProvider(providerLibrary *terraform.ProviderLibrary, version string) (*github.GithubTerraformProvider, error) {
 	progress := &output.MockProgress{}
 	progress.On("Inc").Maybe().Return()
-	provider, err := github.NewGithubTerraformProvider(version, progress, os.TempDir())
+	provider, err := github.NewGithubTerraformProvider(version, progress, terraform.ProviderInstallOptions{ConfigDir: os.TempDir()}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -37,7 +38,7 @@ func InitTestGithubProvider(providerLibrary *terraform.ProviderLibrary, version
 func InitTestGoogleProvider(providerLibrary *terraform.ProviderLibrary, version string) (*google.GCPTerraformProvider, error) {
 	progress := &output.MockProgress{}
 	progress.On("Inc").Maybe().Return()
-	provider, err := google.NewGCPTerraformProvider(version, progress, os.TempDir())
+	provider, err := google.NewGCPTerraformProvider(version, progress, terraform.ProviderInstallOptions{ConfigDir: os.TempDir()}, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +50,7 @@ func InitTestGoogleProvider(providerLibrary *terraform.ProviderLibrary, version
 func InitTestAzureProvider(providerLibrary *terraform.ProviderLibrary, version string) (*azurerm.AzureTerraformProvider, error) {
 	progress := &output.MockProgress{}
 	progress.On("Inc").Maybe().Return()
-	provider, err := azurerm.NewAzureTerraformProvider(version, progress, os.TempDir())
+	provider, err := azurerm.NewAzureTerraformProvider(version, progress, terraform.ProviderInstallOptions{ConfigDir: os.TempDir()}, nil)
 	if err != nil {
 		return nil, err
 	}